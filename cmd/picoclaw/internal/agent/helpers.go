@@ -33,6 +33,10 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 		return fmt.Errorf("error loading config: %w", err)
 	}
 
+	if err = logger.ConfigureWithDebug(cfg.Logging.Level, cfg.Logging.Format, debug); err != nil {
+		return fmt.Errorf("error applying logging config: %w", err)
+	}
+
 	if model != "" {
 		cfg.Agents.Defaults.ModelName = model
 	}
@@ -47,7 +51,10 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 		cfg.Agents.Defaults.ModelName = modelID
 	}
 
-	msgBus := bus.NewMessageBus()
+	msgBus := bus.NewMessageBusWithOptions(bus.Options{
+		BufferSize: cfg.Gateway.Bus.BufferSize,
+		Policy:     bus.BackpressurePolicy(cfg.Gateway.Bus.Policy),
+	})
 	defer msgBus.Close()
 	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
 	defer agentLoop.Close()
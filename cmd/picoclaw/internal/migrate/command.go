@@ -17,7 +17,8 @@ func NewMigrateCommand() *cobra.Command {
   picoclaw migrate --from openclaw
   picoclaw migrate --dry-run
   picoclaw migrate --refresh
-  picoclaw migrate --force`,
+  picoclaw migrate --force
+  picoclaw migrate providers`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			m := migrate.NewMigrateInstance(opts)
 			result, err := m.Run(opts)
@@ -48,5 +49,7 @@ func NewMigrateCommand() *cobra.Command {
 	cmd.Flags().StringVar(&opts.TargetHome, "target-home", "",
 		"Override target home directory (default: ~/.picoclaw)")
 
+	cmd.AddCommand(newProvidersCommand())
+
 	return cmd
 }
@@ -0,0 +1,111 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newProvidersCommand() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "providers",
+		Short: "Convert the legacy providers config into model_list",
+		Long: `Convert the legacy "providers" section of the config into "model_list"
+entries and write them back to the config file.
+
+Entries whose model_name already exists in model_list are kept as they are.
+The providers section is left in place; remove it once the new entries work.`,
+		Args: cobra.NoArgs,
+		Example: `  picoclaw migrate providers
+  picoclaw migrate providers --dry-run`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return migrateProviders(internal.GetConfigPath(), dryRun, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"Show the model_list changes without writing the config")
+
+	return cmd
+}
+
+// migrateProviders merges the model_list entries converted from the legacy
+// providers config into model_list, deduplicated by model_name, prints the
+// added entries and saves the config unless dryRun is set.
+func migrateProviders(configPath string, dryRun bool, out io.Writer) error {
+	cfg, err := loadConfigFile(configPath)
+	if err != nil {
+		return err
+	}
+	if !cfg.HasProvidersConfig() {
+		fmt.Fprintln(out, "No legacy providers config found; nothing to migrate.")
+		return nil
+	}
+
+	merged := mergeModelList(cfg.ModelList, config.ConvertProvidersToModelList(cfg))
+	added := merged[len(cfg.ModelList):]
+	if len(added) == 0 {
+		fmt.Fprintln(out, "model_list already covers the providers config; nothing to migrate.")
+		return nil
+	}
+
+	fmt.Fprintln(out, "model_list changes:")
+	for _, m := range added {
+		fmt.Fprintf(out, "+ %s (%s)\n", m.ModelName, m.Model)
+	}
+	if dryRun {
+		fmt.Fprintln(out, "Dry run: config not written.")
+		return nil
+	}
+
+	cfg.ModelList = merged
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Fprintf(out, "✓ Added %d model(s) to model_list in %s\n", len(added), configPath)
+	return nil
+}
+
+// loadConfigFile reads the config file over the defaults without what
+// config.LoadConfig layers on top (environment variables, PICOCLAW_MODEL_LIST,
+// resolved file:// keys, the built-in model_list template), so saving it
+// writes back only what the user configured.
+func loadConfigFile(configPath string) (*config.Config, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.ModelList = nil
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return cfg, nil
+}
+
+// mergeModelList appends the converted entries whose model_name is not
+// already in existing.
+func mergeModelList(existing, converted []config.ModelConfig) []config.ModelConfig {
+	seen := make(map[string]bool, len(existing))
+	merged := make([]config.ModelConfig, 0, len(existing)+len(converted))
+	for _, m := range existing {
+		seen[m.ModelName] = true
+		merged = append(merged, m)
+	}
+	for _, m := range converted {
+		if seen[m.ModelName] {
+			continue
+		}
+		seen[m.ModelName] = true
+		merged = append(merged, m)
+	}
+	return merged
+}
@@ -0,0 +1,108 @@
+package migrate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func writeLegacyConfig(t *testing.T, modelList string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	data := `{
+  "agents": {"defaults": {"workspace": "` + filepath.ToSlash(dir) + `", "provider": "anthropic", "model": "claude-sonnet-4.6"}},
+  "providers": {
+    "anthropic": {"api_key": "sk-ant-test"},
+    "openai": {"api_key": "sk-openai-test"}
+  }` + modelList + `
+}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func savedModelList(t *testing.T, path string) map[string]config.ModelConfig {
+	t.Helper()
+	cfg, err := loadConfigFile(path)
+	require.NoError(t, err)
+	models := make(map[string]config.ModelConfig, len(cfg.ModelList))
+	for _, m := range cfg.ModelList {
+		models[m.ModelName] = m
+	}
+	return models
+}
+
+func TestMigrateProviders_ConvertsLegacyConfig(t *testing.T) {
+	path := writeLegacyConfig(t, "")
+
+	var out bytes.Buffer
+	require.NoError(t, migrateProviders(path, false, &out))
+
+	models := savedModelList(t, path)
+	require.Len(t, models, 2)
+	assert.Equal(t, "anthropic/claude-sonnet-4.6", models["anthropic"].Model)
+	assert.Equal(t, "sk-ant-test", models["anthropic"].APIKey)
+	assert.Equal(t, "sk-openai-test", models["openai"].APIKey)
+
+	assert.Contains(t, out.String(), "+ anthropic (anthropic/claude-sonnet-4.6)")
+	assert.Contains(t, out.String(), "+ openai (")
+
+	// A second run finds nothing left to do.
+	out.Reset()
+	require.NoError(t, migrateProviders(path, false, &out))
+	assert.Contains(t, out.String(), "nothing to migrate")
+}
+
+func TestMigrateProviders_KeepsExistingModelNames(t *testing.T) {
+	path := writeLegacyConfig(t, `,
+  "model_list": [
+    {"model_name": "openai", "model": "openai/gpt-4o", "api_key": "sk-custom"}
+  ]`)
+
+	var out bytes.Buffer
+	require.NoError(t, migrateProviders(path, false, &out))
+
+	models := savedModelList(t, path)
+	require.Len(t, models, 2)
+	assert.Equal(t, "openai/gpt-4o", models["openai"].Model)
+	assert.Equal(t, "sk-custom", models["openai"].APIKey)
+	assert.Equal(t, "anthropic/claude-sonnet-4.6", models["anthropic"].Model)
+	assert.NotContains(t, out.String(), "+ openai")
+}
+
+func TestMigrateProviders_DryRunDoesNotWrite(t *testing.T) {
+	path := writeLegacyConfig(t, "")
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, migrateProviders(path, true, &out))
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+	assert.Contains(t, out.String(), "+ anthropic")
+}
+
+func TestMigrateProviders_DoesNotPersistEnvironment(t *testing.T) {
+	path := writeLegacyConfig(t, "")
+	t.Setenv("PICOCLAW_MODEL_LIST", `[{"model_name":"from-env","model":"openai/gpt-4o","api_key":"sk-env"}]`)
+
+	var out bytes.Buffer
+	require.NoError(t, migrateProviders(path, false, &out))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "from-env")
+	assert.NotContains(t, string(data), "sk-env")
+
+	models := savedModelList(t, path)
+	require.Len(t, models, 2)
+	assert.Equal(t, "sk-openai-test", models["openai"].APIKey)
+}
@@ -7,12 +7,15 @@ import (
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
 // LocalModel is a special model name that indicates that the model is local and with or without api_key.
 const LocalModel = "local-model"
 
 func NewModelCommand() *cobra.Command {
+	var explain bool
+
 	cmd := &cobra.Command{
 		Use:   "model [model_name]",
 		Short: "Show or change the default model",
@@ -26,6 +29,7 @@ Examples:
   picoclaw model gpt-5.2           # Set gpt-5.2 as default
   picoclaw model claude-sonnet-4.6 # Set claude-sonnet-4.6 as default
   picoclaw model local-model       # Set local VLLM server as default
+  picoclaw model --explain         # Show how the default model picks a provider
 
 Note: 'local-model' is a special value for using a local VLLM server
 (running at localhost:8000 by default) which does not require an API key.`,
@@ -39,6 +43,14 @@ Note: 'local-model' is a special value for using a local VLLM server
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
+			if explain {
+				modelName := cfg.Agents.Defaults.GetModelName()
+				if len(args) > 0 {
+					modelName = args[0]
+				}
+				return explainModel(cfg, modelName)
+			}
+
 			if len(args) == 0 {
 				// Show current default model
 				showCurrentModel(cfg)
@@ -51,9 +63,27 @@ Note: 'local-model' is a special value for using a local VLLM server
 		},
 	}
 
+	cmd.Flags().BoolVar(&explain, "explain", false,
+		"Explain which provider, protocol and API base the model resolves to instead of changing it")
+
 	return cmd
 }
 
+func explainModel(cfg *config.Config, modelName string) error {
+	if modelName == "" {
+		return fmt.Errorf("no default model is set; pass a model name to explain")
+	}
+	trace, err := providers.ExplainModelSelection(cfg, modelName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Provider selection for %s:\n", modelName)
+	for _, step := range trace {
+		fmt.Printf("  - %s\n", step)
+	}
+	return nil
+}
+
 func showCurrentModel(cfg *config.Config) {
 	defaultModel := cfg.Agents.Defaults.ModelName
 	if defaultModel == "" {
@@ -47,7 +47,8 @@ func TestNewModelCommand(t *testing.T) {
 
 	assert.Len(t, cmd.Aliases, 0)
 
-	assert.False(t, cmd.HasFlags())
+	assert.True(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.Flags().Lookup("explain"))
 
 	assert.Nil(t, cmd.Run)
 	assert.NotNil(t, cmd.RunE)
@@ -367,3 +368,22 @@ func TestListAvailableModels_MarkerLogic(t *testing.T) {
 	assert.Contains(t, output, "> - middle-model (openai/middle)")
 	assert.Contains(t, output, "  - last-model (openai/last)")
 }
+
+func TestExplainModel(t *testing.T) {
+	cfg := &config.Config{
+		ModelList: []config.ModelConfig{
+			{ModelName: "kimi", Model: "moonshot/kimi-k2", APIKey: "test"},
+		},
+	}
+
+	output := captureStdout(func() {
+		require.NoError(t, explainModel(cfg, "kimi"))
+	})
+
+	assert.Contains(t, output, "Provider selection for kimi:")
+	assert.Contains(t, output, `  - protocol "moonshot" from the prefix of model "moonshot/kimi-k2"`)
+	assert.Contains(t, output, `https://api.moonshot.cn/v1`)
+
+	assert.Error(t, explainModel(cfg, "missing"))
+	assert.Error(t, explainModel(cfg, ""))
+}
@@ -37,6 +37,8 @@ func NewSkillsCommand() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("error creating skills installer: %w", err)
 			}
+			installer.SetAllowedDomains(cfg.Tools.Skills.Github.AllowedDomains)
+			installer.SetMaxDownloadSize(cfg.Tools.Skills.Github.MaxDownloadSize)
 			d.installer = installer
 
 			// get global config directory and builtin skills directory
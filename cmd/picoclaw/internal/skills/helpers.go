@@ -67,6 +67,8 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
 		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
 		ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
+		AllowedDomains:        cfg.Tools.Skills.Github.AllowedDomains,
+		MaxDownloadSize:       cfg.Tools.Skills.Github.MaxDownloadSize,
 	})
 
 	registry := registryMgr.GetRegistry(registryName)
@@ -229,6 +231,8 @@ func skillsSearchCmd(query string) {
 	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
 		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
 		ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
+		AllowedDomains:        cfg.Tools.Skills.Github.AllowedDomains,
+		MaxDownloadSize:       cfg.Tools.Skills.Github.MaxDownloadSize,
 	})
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -5,14 +5,23 @@ import (
 )
 
 func NewStatusCommand() *cobra.Command {
+	var explain bool
+
 	cmd := &cobra.Command{
 		Use:     "status",
 		Aliases: []string{"s"},
 		Short:   "Show picoclaw status",
 		Run: func(cmd *cobra.Command, args []string) {
+			if explain {
+				explainCmd()
+				return
+			}
 			statusCmd()
 		},
 	}
 
+	cmd.Flags().BoolVar(&explain, "explain", false,
+		"Show each effective config value and whether it came from defaults, the config file, or the environment")
+
 	return cmd
 }
@@ -99,3 +99,21 @@ func statusCmd() {
 		}
 	}
 }
+
+func explainCmd() {
+	configPath := internal.GetConfigPath()
+	fields, err := config.Explain(configPath)
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Effective config (%s):\n", configPath)
+	for _, f := range fields {
+		line := fmt.Sprintf("  %-60s %-8s %s", f.Path, f.Source, f.Value)
+		if f.Source == config.SourceEnv {
+			line += fmt.Sprintf("  (%s)", f.EnvVar)
+		}
+		fmt.Println(line)
+	}
+}
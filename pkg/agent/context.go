@@ -26,6 +26,10 @@ type ContextBuilder struct {
 	toolDiscoveryBM25  bool
 	toolDiscoveryRegex bool
 
+	// Per-agent instructions from config, appended after the bootstrap files.
+	agentPrompt     string
+	agentPromptFile string // absolute path, re-read when it changes
+
 	// Cache for system prompt to avoid rebuilding on every call.
 	// This fixes issue #607: repeated reprocessing of the entire context.
 	// The cache auto-invalidates when workspace source files change (mtime check).
@@ -51,6 +55,21 @@ func (cb *ContextBuilder) WithToolDiscovery(useBM25, useRegex bool) *ContextBuil
 	return cb
 }
 
+// WithAgentPrompt sets agent-specific instructions. prompt is used as is;
+// file, when set, is read on every prompt build so edits take effect, and a
+// relative path is resolved against the workspace.
+func (cb *ContextBuilder) WithAgentPrompt(prompt, file string) *ContextBuilder {
+	cb.agentPrompt = strings.TrimSpace(prompt)
+	cb.agentPromptFile = ""
+	if file = strings.TrimSpace(file); file != "" {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(cb.workspace, file)
+		}
+		cb.agentPromptFile = file
+	}
+	return cb
+}
+
 func getGlobalConfigDir() string {
 	if home := os.Getenv(config.EnvHome); home != "" {
 		return home
@@ -140,6 +159,11 @@ func (cb *ContextBuilder) BuildSystemPrompt() string {
 		parts = append(parts, bootstrapContent)
 	}
 
+	// Agent-specific instructions from config
+	if agentPrompt := cb.loadAgentPrompt(); agentPrompt != "" {
+		parts = append(parts, "# Agent Instructions\n\n"+agentPrompt)
+	}
+
 	// Skills - show summary, AI can read full content with read_file tool
 	skillsSummary := cb.skillsLoader.BuildSkillsSummary()
 	if skillsSummary != "" {
@@ -160,6 +184,24 @@ The following skills extend your capabilities. To use a skill, read its SKILL.md
 	return strings.Join(parts, "\n\n---\n\n")
 }
 
+// loadAgentPrompt joins the inline agent prompt and the prompt file.
+func (cb *ContextBuilder) loadAgentPrompt() string {
+	var prompts []string
+	if cb.agentPrompt != "" {
+		prompts = append(prompts, cb.agentPrompt)
+	}
+	if cb.agentPromptFile != "" {
+		data, err := os.ReadFile(cb.agentPromptFile)
+		if err != nil {
+			logger.WarnCF("agent", "Failed to read agent system prompt file",
+				map[string]any{"path": cb.agentPromptFile, "error": err.Error()})
+		} else if content := strings.TrimSpace(string(data)); content != "" {
+			prompts = append(prompts, content)
+		}
+	}
+	return strings.Join(prompts, "\n\n")
+}
+
 // BuildSystemPromptWithCache returns the cached system prompt if available
 // and source files haven't changed, otherwise builds and caches it.
 // Source file changes are detected via mtime checks (cheap stat calls).
@@ -222,13 +264,17 @@ func (cb *ContextBuilder) InvalidateCache() {
 // invalidation (bootstrap files + memory). Skill roots are handled separately
 // because they require both directory-level and recursive file-level checks.
 func (cb *ContextBuilder) sourcePaths() []string {
-	return []string{
+	paths := []string{
 		filepath.Join(cb.workspace, "AGENTS.md"),
 		filepath.Join(cb.workspace, "SOUL.md"),
 		filepath.Join(cb.workspace, "USER.md"),
 		filepath.Join(cb.workspace, "IDENTITY.md"),
 		filepath.Join(cb.workspace, "memory", "MEMORY.md"),
 	}
+	if cb.agentPromptFile != "" {
+		paths = append(paths, cb.agentPromptFile)
+	}
+	return paths
 }
 
 // skillRoots returns all skill root directories that can affect
@@ -593,6 +639,27 @@ func (cb *ContextBuilder) BuildMessages(
 	return messages
 }
 
+// limitHistoryTurns returns the tail of history that contains at most
+// maxTurns user turns. The cut is always made at a user message so that
+// assistant tool calls stay paired with their tool results. A non-positive
+// maxTurns returns history unchanged.
+func limitHistoryTurns(history []providers.Message, maxTurns int) []providers.Message {
+	if maxTurns <= 0 {
+		return history
+	}
+	turns := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role != "user" {
+			continue
+		}
+		turns++
+		if turns == maxTurns {
+			return history[i:]
+		}
+	}
+	return history
+}
+
 func sanitizeHistoryForProvider(history []providers.Message) []providers.Message {
 	if len(history) == 0 {
 		return history
@@ -737,6 +804,14 @@ func (cb *ContextBuilder) AddAssistantMessage(
 	return messages
 }
 
+// ListSkills returns the installed skills visible to this agent.
+func (cb *ContextBuilder) ListSkills() []skills.SkillInfo {
+	if cb.skillsLoader == nil {
+		return nil
+	}
+	return cb.skillsLoader.ListSkills()
+}
+
 // GetSkillsInfo returns information about loaded skills.
 func (cb *ContextBuilder) GetSkillsInfo() map[string]any {
 	allSkills := cb.skillsLoader.ListSkills()
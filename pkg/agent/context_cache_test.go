@@ -664,6 +664,34 @@ func TestConcurrentBuildSystemPromptWithCache(t *testing.T) {
 	}
 }
 
+// TestAgentPromptFileChange verifies that editing an agent's system prompt
+// file invalidates the cached system prompt.
+func TestAgentPromptFileChange(t *testing.T) {
+	tmpDir := setupWorkspace(t, map[string]string{
+		"prompts/agent.md": "Persona v1.",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	cb := NewContextBuilder(tmpDir).WithAgentPrompt("", "prompts/agent.md")
+
+	sp1 := cb.BuildSystemPromptWithCache()
+	if !strings.Contains(sp1, "Persona v1.") {
+		t.Fatal("initial prompt should contain the agent prompt file")
+	}
+
+	promptPath := filepath.Join(tmpDir, "prompts", "agent.md")
+	if err := os.WriteFile(promptPath, []byte("Persona v2."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(2 * time.Second)
+	os.Chtimes(promptPath, future, future)
+
+	sp2 := cb.BuildSystemPromptWithCache()
+	if !strings.Contains(sp2, "Persona v2.") || strings.Contains(sp2, "Persona v1.") {
+		t.Error("cache should be invalidated when the agent prompt file changes")
+	}
+}
+
 // BenchmarkBuildMessagesWithCache measures caching performance.
 
 // TestEmptyWorkspaceBaselineDetectsNewFiles verifies that when the cache is
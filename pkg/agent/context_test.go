@@ -281,3 +281,33 @@ func TestSanitizeHistoryForProvider_PartialToolResultsInMiddle(t *testing.T) {
 	}
 	assertRoles(t, result, "user", "assistant", "tool", "assistant", "user", "user", "assistant", "tool", "assistant")
 }
+
+func TestLimitHistoryTurns(t *testing.T) {
+	history := []providers.Message{
+		msg("user", "one"),
+		msg("assistant", "r1"),
+		msg("user", "two"),
+		assistantWithTools("A"),
+		toolResult("A"),
+		msg("assistant", "r2"),
+		msg("user", "three"),
+		msg("assistant", "r3"),
+	}
+
+	if got := limitHistoryTurns(history, 0); len(got) != len(history) {
+		t.Fatalf("maxTurns=0 should keep all messages, got %d", len(got))
+	}
+	if got := limitHistoryTurns(history, 5); len(got) != len(history) {
+		t.Fatalf("maxTurns larger than history should keep all messages, got %d", len(got))
+	}
+
+	got := limitHistoryTurns(history, 2)
+	if len(got) != 6 || got[0].Content != "two" {
+		t.Fatalf("expected window starting at 'two' with 6 messages, got %d starting with %q", len(got), got[0].Content)
+	}
+
+	got = limitHistoryTurns(history, 1)
+	if len(got) != 2 || got[0].Content != "three" {
+		t.Fatalf("expected last turn only, got %+v", got)
+	}
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	defaultEvalSampleRate  = 0.1
+	defaultEvalMaxFileSize = 10 * 1024 * 1024
+)
+
+var (
+	evalEmailRe = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// evalPhoneRe matches "+"-prefixed international numbers and grouped
+	// numbers like 555-123-4567, but not dates or plain IDs.
+	evalPhoneRe = regexp.MustCompile(`\+\d[\d\s().-]{6,}\d|\b\d{3}[\s.-]\d{3,4}[\s.-]\d{4}\b`)
+	// evalFileNameRe replaces characters that are unsafe in file names.
+	evalFileNameRe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// evalMessage is one prompt message in an eval record.
+type evalMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// evalRecord is one sampled prompt/response pair, stored as a JSON line.
+type evalRecord struct {
+	Time             time.Time     `json:"time"`
+	AgentID          string        `json:"agent_id"`
+	Model            string        `json:"model"`
+	Channel          string        `json:"channel,omitempty"`
+	Messages         []evalMessage `json:"messages"`
+	Response         string        `json:"response"`
+	ToolCalls        []string      `json:"tool_calls,omitempty"`
+	PromptTokens     int           `json:"prompt_tokens,omitempty"`
+	CompletionTokens int           `json:"completion_tokens,omitempty"`
+}
+
+// evalLogger writes a sample of model calls to one JSONL file per model,
+// rotating a file to "<name>.1" once it reaches maxSize. A nil *evalLogger
+// records nothing.
+type evalLogger struct {
+	mu      sync.Mutex
+	dir     string
+	rate    float64
+	maxSize int64
+	random  func() float64
+}
+
+func newEvalLogger(dir string, cfg config.EvalLogConfig) *evalLogger {
+	rate := cfg.SampleRate
+	if rate <= 0 {
+		rate = defaultEvalSampleRate
+	}
+	maxSize := cfg.MaxFileSize
+	if maxSize <= 0 {
+		maxSize = defaultEvalMaxFileSize
+	}
+	return &evalLogger{
+		dir:     dir,
+		rate:    min(rate, 1),
+		maxSize: maxSize,
+		random:  rand.Float64,
+	}
+}
+
+// sample reports whether the next call should be recorded.
+func (l *evalLogger) sample() bool {
+	return l.rate >= 1 || l.random() < l.rate
+}
+
+// record samples and writes one model call. Failures are logged, never
+// returned, so evaluation logging cannot break a turn.
+func (l *evalLogger) record(
+	agentID, model, channel string,
+	messages []providers.Message,
+	resp *providers.LLMResponse,
+) {
+	if l == nil || resp == nil || !l.sample() {
+		return
+	}
+
+	rec := evalRecord{
+		Time:     time.Now().UTC(),
+		AgentID:  agentID,
+		Model:    model,
+		Channel:  channel,
+		Messages: make([]evalMessage, 0, len(messages)),
+		Response: redactEvalText(resp.Content),
+	}
+	for _, m := range messages {
+		rec.Messages = append(rec.Messages, evalMessage{Role: m.Role, Content: redactEvalText(m.Content)})
+	}
+	for _, tc := range resp.ToolCalls {
+		rec.ToolCalls = append(rec.ToolCalls, tc.Name)
+	}
+	if resp.Usage != nil {
+		rec.PromptTokens = resp.Usage.PromptTokens
+		rec.CompletionTokens = resp.Usage.CompletionTokens
+	}
+
+	if err := l.write(model, rec); err != nil {
+		logger.WarnCF("agent", "Failed to write eval log record",
+			map[string]any{"model": model, "error": err.Error()})
+	}
+}
+
+func (l *evalLogger) write(model string, rec evalRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal eval record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(l.dir, 0o700); err != nil {
+		return err
+	}
+	path := filepath.Join(l.dir, evalFileName(model))
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(line)) > l.maxSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate eval log: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(line)
+	return err
+}
+
+// evalFileName maps a model name such as "openai/gpt-5.4" to a file name.
+func evalFileName(model string) string {
+	name := strings.Trim(evalFileNameRe.ReplaceAllString(model, "_"), "._")
+	if name == "" {
+		name = "unknown"
+	}
+	return name + ".jsonl"
+}
+
+// redactEvalText masks credentials, email addresses and phone numbers.
+func redactEvalText(s string) string {
+	s = logger.RedactString(s)
+	s = evalEmailRe.ReplaceAllString(s, "[EMAIL]")
+	return evalPhoneRe.ReplaceAllString(s, "[PHONE]")
+}
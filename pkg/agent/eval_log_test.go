@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestEvalLogger_Sample(t *testing.T) {
+	tests := []struct {
+		name   string
+		rate   float64
+		random float64
+		want   bool
+	}{
+		{"below rate", 0.25, 0.1, true},
+		{"above rate", 0.25, 0.5, false},
+		{"default rate", 0, 0.05, true},
+		{"default rate miss", 0, 0.2, false},
+		{"always", 1, 0.99, true},
+		{"clamped above one", 5, 0.99, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newEvalLogger(t.TempDir(), config.EvalLogConfig{Enabled: true, SampleRate: tt.rate})
+			l.random = func() float64 { return tt.random }
+			if got := l.sample(); got != tt.want {
+				t.Errorf("sample() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalLogger_RecordWritesRedactedJSONL(t *testing.T) {
+	dir := t.TempDir()
+	l := newEvalLogger(dir, config.EvalLogConfig{Enabled: true, SampleRate: 1})
+
+	messages := []providers.Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Content: "Mail jane.doe@example.com or call +1 415 555 0100, key sk-abcdefghijklmnopqrstuvwx"},
+	}
+	resp := &providers.LLMResponse{
+		Content:   "I'll email jane.doe@example.com.",
+		ToolCalls: []providers.ToolCall{{Name: "message"}},
+		Usage:     &providers.UsageInfo{PromptTokens: 12, CompletionTokens: 5},
+	}
+	l.record("main", "openai/gpt-5.4", "telegram", messages, resp)
+	l.record("main", "openai/gpt-5.4", "telegram", messages, resp)
+
+	f, err := os.Open(filepath.Join(dir, "openai_gpt-5.4.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []evalRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec evalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid JSONL line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	rec := records[0]
+	if rec.AgentID != "main" || rec.Model != "openai/gpt-5.4" || rec.Channel != "telegram" {
+		t.Errorf("unexpected record header: %+v", rec)
+	}
+	if len(rec.ToolCalls) != 1 || rec.ToolCalls[0] != "message" || rec.PromptTokens != 12 {
+		t.Errorf("tool calls/usage not recorded: %+v", rec)
+	}
+	user := rec.Messages[1].Content
+	for _, secret := range []string{"jane.doe@example.com", "415 555 0100", "sk-abcdefghijklmnopqrstuvwx"} {
+		if strings.Contains(user, secret) || strings.Contains(rec.Response, secret) {
+			t.Errorf("%q was not redacted: %q / %q", secret, user, rec.Response)
+		}
+	}
+	if !strings.Contains(user, "[EMAIL]") || !strings.Contains(user, "[PHONE]") {
+		t.Errorf("expected redaction markers in %q", user)
+	}
+}
+
+func TestEvalLogger_SkipsUnsampledCalls(t *testing.T) {
+	dir := t.TempDir()
+	l := newEvalLogger(dir, config.EvalLogConfig{Enabled: true, SampleRate: 0.5})
+	l.random = func() float64 { return 0.9 }
+
+	l.record("main", "model", "cli", nil, &providers.LLMResponse{Content: "hi"})
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("expected no files for an unsampled call, got %d", len(entries))
+	}
+
+	var nilLogger *evalLogger
+	nilLogger.record("main", "model", "cli", nil, &providers.LLMResponse{Content: "hi"})
+}
+
+func TestEvalLogger_RotatesAtMaxFileSize(t *testing.T) {
+	dir := t.TempDir()
+	l := newEvalLogger(dir, config.EvalLogConfig{Enabled: true, SampleRate: 1, MaxFileSize: 300})
+
+	resp := &providers.LLMResponse{Content: strings.Repeat("x", 100)}
+	for range 3 {
+		l.record("main", "model", "cli", nil, resp)
+	}
+
+	path := filepath.Join(dir, "model.jsonl")
+	for _, p := range []string{path, path + ".1"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("expected %s: %v", filepath.Base(p), err)
+		}
+		if info.Size() > 300 {
+			t.Errorf("%s is %d bytes, over the 300 byte cap", filepath.Base(p), info.Size())
+		}
+	}
+}
+
+func TestRedactEvalText_LeavesDatesAndIDs(t *testing.T) {
+	in := "Order 12345678 shipped on 2026-03-01 at 10:15:30."
+	if got := redactEvalText(in); got != in {
+		t.Errorf("redactEvalText(%q) = %q, want unchanged", in, got)
+	}
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Limits for per-turn sampling overrides, whether given as inline flags or
+// as inbound metadata (e.g. by the OpenAI-compatible API).
+const (
+	MinTemperatureOverride = 0.0
+	MaxTemperatureOverride = 2.0
+	MaxTokensOverride      = 200000
+)
+
+// inlineFlags are sampling overrides given at the start of a message, e.g.
+// "--temp 0.2 --max 2000 your question". They apply to that turn only.
+type inlineFlags struct {
+	Temperature *float64
+	MaxTokens   int
+}
+
+// parseInlineFlags strips leading --temp/--max flags (also --temperature,
+// --max-tokens, and the --flag=value form) from content and returns them
+// with the rest of the message. Content that does not start with one of
+// these flags is returned unchanged, so plain messages are unaffected. An
+// out-of-range or malformed value is an error.
+func parseInlineFlags(content string) (inlineFlags, string, error) {
+	var flags inlineFlags
+	rest := strings.TrimLeftFunc(content, unicode.IsSpace)
+	parsed := false
+
+	for strings.HasPrefix(rest, "--") {
+		token, after := cutField(rest)
+		name, value, hasValue := strings.Cut(token, "=")
+		if !isInlineFlag(name) {
+			break
+		}
+		if !hasValue {
+			value, after = cutField(after)
+			if value == "" {
+				return inlineFlags{}, "", fmt.Errorf("%s needs a value", name)
+			}
+		}
+
+		if err := flags.set(name, value); err != nil {
+			return inlineFlags{}, "", err
+		}
+		parsed = true
+		rest = after
+	}
+
+	if !parsed {
+		return inlineFlags{}, content, nil
+	}
+	if rest == "" {
+		return inlineFlags{}, "", fmt.Errorf("add your message after the flags")
+	}
+	return flags, rest, nil
+}
+
+// set validates value and stores it as the override for flag name.
+func (f *inlineFlags) set(name, value string) error {
+	switch name {
+	case "--temp", "--temperature":
+		t, err := strconv.ParseFloat(value, 64)
+		if err != nil || t < MinTemperatureOverride || t > MaxTemperatureOverride {
+			return fmt.Errorf("%s must be a number between %g and %g, got %q",
+				name, MinTemperatureOverride, MaxTemperatureOverride, value)
+		}
+		f.Temperature = &t
+	case "--max", "--max-tokens":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > MaxTokensOverride {
+			return fmt.Errorf("%s must be a whole number between 1 and %d, got %q",
+				name, MaxTokensOverride, value)
+		}
+		f.MaxTokens = n
+	default:
+		return fmt.Errorf("unknown flag %s", name)
+	}
+	return nil
+}
+
+func isInlineFlag(name string) bool {
+	switch name {
+	case "--temp", "--temperature", "--max", "--max-tokens":
+		return true
+	}
+	return false
+}
+
+// cutField splits s into its first whitespace-delimited field and the
+// remainder with leading whitespace removed.
+func cutField(s string) (field, rest string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimLeftFunc(s[i:], unicode.IsSpace)
+}
@@ -0,0 +1,154 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestParseInlineFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		wantTemp float64 // -1 = no override
+		wantMax  int
+		wantRest string
+	}{
+		{"plain message", "what is the weather?", -1, 0, "what is the weather?"},
+		{"temp and max", "--temp 0.2 --max 2000 your question", 0.2, 2000, "your question"},
+		{"long names with equals", "--temperature=1.5 --max-tokens=300 hi", 1.5, 300, "hi"},
+		{"only max", "  --max 50\nline one\nline two", -1, 50, "line one\nline two"},
+		{"unknown flag left alone", "--verbose explain grep", -1, 0, "--verbose explain grep"},
+		{"flags stop at first other word", "--temp 0 use --max 10 in the text", 0, 0, "use --max 10 in the text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, rest, err := parseInlineFlags(tt.in)
+			if err != nil {
+				t.Fatalf("parseInlineFlags(%q) error = %v", tt.in, err)
+			}
+			if rest != tt.wantRest {
+				t.Errorf("rest = %q, want %q", rest, tt.wantRest)
+			}
+			if tt.wantTemp < 0 {
+				if flags.Temperature != nil {
+					t.Errorf("temperature = %v, want no override", *flags.Temperature)
+				}
+			} else if flags.Temperature == nil || *flags.Temperature != tt.wantTemp {
+				t.Errorf("temperature = %v, want %v", flags.Temperature, tt.wantTemp)
+			}
+			if flags.MaxTokens != tt.wantMax {
+				t.Errorf("max tokens = %d, want %d", flags.MaxTokens, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestParseInlineFlags_RejectsBadValues(t *testing.T) {
+	for _, in := range []string{
+		"--temp 2.5 hi",
+		"--temp -0.1 hi",
+		"--temp warm hi",
+		"--max 0 hi",
+		"--max 1.5 hi",
+		"--max 999999 hi",
+		"--max",
+		"--temp 0.3",
+	} {
+		if _, _, err := parseInlineFlags(in); err == nil {
+			t.Errorf("parseInlineFlags(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+// optionsRecordingProvider records the options of the last Chat call.
+type optionsRecordingProvider struct {
+	lastOpts     map[string]any
+	lastMessages []providers.Message
+}
+
+func (p *optionsRecordingProvider) Chat(
+	_ context.Context,
+	messages []providers.Message,
+	_ []providers.ToolDefinition,
+	_ string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.lastOpts = opts
+	p.lastMessages = messages
+	return &providers.LLMResponse{Content: "ok"}, nil
+}
+
+func (p *optionsRecordingProvider) GetDefaultModel() string { return "test-model" }
+
+func TestProcessMessage_InlineFlagsOverrideProviderOptions(t *testing.T) {
+	temp := 0.7
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				Temperature:       &temp,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	provider := &optionsRecordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	send := func(content string) string {
+		t.Helper()
+		reply, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel: "telegram", SenderID: "telegram:1", ChatID: "chat-1", Content: content,
+		})
+		if err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+		return reply
+	}
+
+	send("--temp 0.2 --max 2000 summarise this")
+	if got := provider.lastOpts["temperature"]; got != 0.2 {
+		t.Errorf("temperature = %v, want 0.2", got)
+	}
+	if got := provider.lastOpts["max_tokens"]; got != 2000 {
+		t.Errorf("max_tokens = %v, want 2000", got)
+	}
+	if last := provider.lastMessages[len(provider.lastMessages)-1]; last.Content != "summarise this" {
+		t.Errorf("user message = %q, want the flags stripped", last.Content)
+	}
+
+	// The override lasts for that turn only.
+	send("and now without flags")
+	if got := provider.lastOpts["temperature"]; got != 0.7 {
+		t.Errorf("temperature = %v, want the configured 0.7", got)
+	}
+	if got := provider.lastOpts["max_tokens"]; got != 4096 {
+		t.Errorf("max_tokens = %v, want the configured 4096", got)
+	}
+
+	provider.lastOpts = nil
+	if reply := send("--temp 9 hi"); !strings.Contains(reply, "Invalid flags") {
+		t.Errorf("reply = %q, want an invalid flags message", reply)
+	}
+	if provider.lastOpts != nil {
+		t.Error("an invalid flag should not reach the provider")
+	}
+
+	// API callers pass the same overrides as metadata.
+	_, err := al.processMessage(context.Background(), bus.InboundMessage{
+		Channel: "api", SenderID: "api:alice", ChatID: "alice", Content: "hi",
+		Metadata: map[string]string{"temperature": "0.1", "max_tokens": "64"},
+	})
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if provider.lastOpts["temperature"] != 0.1 || provider.lastOpts["max_tokens"] != 64 {
+		t.Errorf("opts = %v, want temperature 0.1 and max_tokens 64 from metadata", provider.lastOpts)
+	}
+}
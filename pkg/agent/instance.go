@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
@@ -30,9 +31,12 @@ type AgentInstance struct {
 	MaxTokens                 int
 	Temperature               float64
 	ThinkingLevel             ThinkingLevel
+	RequestTimeout            time.Duration // per-call bound from the model's request_timeout; 0 = none
+	TextOnly                  bool          // model_list "vision": false; image attachments are not sent
 	ContextWindow             int
 	SummarizeMessageThreshold int
 	SummarizeTokenPercent     int
+	MaxHistoryTurns           int
 	Provider                  providers.LLMProvider
 	Sessions                  session.SessionStore
 	ContextBuilder            *ContextBuilder
@@ -48,6 +52,10 @@ type AgentInstance struct {
 	// LightCandidates holds the resolved provider candidates for the light model.
 	// Pre-computed at agent creation to avoid repeated model_list lookups at runtime.
 	LightCandidates []providers.FallbackCandidate
+
+	// EvalLog samples prompt/response pairs for offline evaluation; nil when
+	// agents.defaults.eval_log is disabled.
+	EvalLog *evalLogger
 }
 
 // NewAgentInstance creates an agent instance from config.
@@ -71,6 +79,23 @@ func NewAgentInstance(
 	allowWritePaths := compilePatterns(cfg.Tools.AllowWritePaths)
 
 	toolsRegistry := tools.NewToolRegistry()
+	if cfg.Tools.AuditLog {
+		// Kept outside the workspace so the agent's own file and exec tools
+		// cannot rewrite its audit trail.
+		auditPath := filepath.Join(config.HomeDir(), "logs", "tool_audit.jsonl")
+		rec, err := tools.NewFileAuditRecorder(auditPath)
+		if err != nil {
+			logger.WarnCF("agent", "Tool audit log disabled",
+				map[string]any{"path": auditPath, "error": err.Error()})
+		} else {
+			toolsRegistry.SetAuditRecorder(rec)
+		}
+	}
+	if agentCfg != nil {
+		if filter := tools.NameFilter(agentCfg.ToolsAllow, agentCfg.ToolsDeny); filter != nil {
+			toolsRegistry.SetFilter(filter)
+		}
+	}
 
 	if cfg.Tools.IsToolEnabled("read_file") {
 		maxReadFileSize := cfg.Tools.ReadFile.MaxReadFileSize
@@ -98,6 +123,9 @@ func NewAgentInstance(
 	if cfg.Tools.IsToolEnabled("append_file") {
 		toolsRegistry.Register(tools.NewAppendFileTool(workspace, restrict, allowWritePaths))
 	}
+	if cfg.Tools.IsToolEnabled("tail_log") {
+		toolsRegistry.Register(tools.NewTailLogTool(workspace, cfg.Tools.TailLog.AllowChats, cfg.Tools.TailLog.MaxBytes))
+	}
 
 	sessionsDir := filepath.Join(workspace, "sessions")
 	sessions := initSessionStore(sessionsDir)
@@ -107,6 +135,9 @@ func NewAgentInstance(
 		mcpDiscoveryActive && cfg.Tools.MCP.Discovery.UseBM25,
 		mcpDiscoveryActive && cfg.Tools.MCP.Discovery.UseRegex,
 	)
+	if cfg.Tools.IsToolEnabled("capabilities") {
+		toolsRegistry.Register(tools.NewCapabilitiesTool(toolsRegistry, contextBuilder.ListSkills))
+	}
 
 	agentID := routing.DefaultAgentID
 	agentName := ""
@@ -118,9 +149,13 @@ func NewAgentInstance(
 		agentName = agentCfg.Name
 		subagents = agentCfg.Subagents
 		skillsFilter = agentCfg.Skills
+		contextBuilder.WithAgentPrompt(agentCfg.SystemPrompt, agentCfg.SystemPromptFile)
 	}
 
 	maxIter := defaults.MaxToolIterations
+	if agentCfg != nil && agentCfg.MaxToolIterations > 0 {
+		maxIter = agentCfg.MaxToolIterations
+	}
 	if maxIter == 0 {
 		maxIter = 20
 	}
@@ -136,8 +171,12 @@ func NewAgentInstance(
 	}
 
 	var thinkingLevelStr string
+	var requestTimeout time.Duration
+	var textOnly bool
 	if mc, err := cfg.GetModelConfig(model); err == nil {
 		thinkingLevelStr = mc.ThinkingLevel
+		requestTimeout = time.Duration(mc.RequestTimeout) * time.Second
+		textOnly = !mc.SupportsVision()
 	}
 	thinkingLevel := parseThinkingLevel(thinkingLevelStr)
 
@@ -172,6 +211,11 @@ func NewAgentInstance(
 		}
 	}
 
+	var evalLog *evalLogger
+	if defaults.EvalLog.Enabled {
+		evalLog = newEvalLogger(filepath.Join(workspace, "logs", "eval"), defaults.EvalLog)
+	}
+
 	return &AgentInstance{
 		ID:                        agentID,
 		Name:                      agentName,
@@ -182,9 +226,12 @@ func NewAgentInstance(
 		MaxTokens:                 maxTokens,
 		Temperature:               temperature,
 		ThinkingLevel:             thinkingLevel,
+		RequestTimeout:            requestTimeout,
+		TextOnly:                  textOnly,
 		ContextWindow:             maxTokens,
 		SummarizeMessageThreshold: summarizeMessageThreshold,
 		SummarizeTokenPercent:     summarizeTokenPercent,
+		MaxHistoryTurns:           max(defaults.MaxHistoryTurns, 0),
 		Provider:                  provider,
 		Sessions:                  sessions,
 		ContextBuilder:            contextBuilder,
@@ -194,6 +241,7 @@ func NewAgentInstance(
 		Candidates:                candidates,
 		Router:                    router,
 		LightCandidates:           lightCandidates,
+		EvalLog:                   evalLog,
 	}
 }
 
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/media"
@@ -165,6 +166,54 @@ func TestNewAgentInstance_ResolveCandidatesFromModelListAlias(t *testing.T) {
 	}
 }
 
+func TestNewAgentInstance_RequestTimeoutFromModelConfig(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace: t.TempDir(),
+				ModelName: "slow-model",
+			},
+		},
+		ModelList: []config.ModelConfig{
+			{ModelName: "slow-model", Model: "openai/slow", APIKey: "key", RequestTimeout: 45},
+		},
+	}
+
+	agent := NewAgentInstance(nil, &cfg.Agents.Defaults, cfg, &mockProvider{})
+	if agent.RequestTimeout != 45*time.Second {
+		t.Fatalf("RequestTimeout = %s, want 45s", agent.RequestTimeout)
+	}
+}
+
+func TestCandidateRequestTimeout_UsesEachCandidatesModelConfig(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:      t.TempDir(),
+				ModelName:      "slow-model",
+				ModelFallbacks: []string{"fast-model", "bare-model"},
+			},
+		},
+		ModelList: []config.ModelConfig{
+			{ModelName: "slow-model", Model: "openai/slow", APIKey: "key", RequestTimeout: 45},
+			{ModelName: "fast-model", Model: "groq/fast", APIKey: "key", RequestTimeout: 5},
+			{ModelName: "bare-model", Model: "deepseek/bare", APIKey: "key"},
+		},
+	}
+
+	agent := NewAgentInstance(nil, &cfg.Agents.Defaults, cfg, &mockProvider{})
+	want := []time.Duration{45 * time.Second, 5 * time.Second, 0}
+	if len(agent.Candidates) != len(want) {
+		t.Fatalf("len(Candidates) = %d, want %d", len(agent.Candidates), len(want))
+	}
+	for i, candidate := range agent.Candidates {
+		if got := candidateRequestTimeout(cfg, candidate); got != want[i] {
+			t.Errorf("candidateRequestTimeout(%s/%s) = %s, want %s",
+				candidate.Provider, candidate.Model, got, want[i])
+		}
+	}
+}
+
 func TestNewAgentInstance_AllowsMediaTempDirForReadListAndExec(t *testing.T) {
 	workspace := t.TempDir()
 	mediaDir := media.TempDir()
@@ -280,3 +329,144 @@ func TestNewAgentInstance_InvalidExecConfigDoesNotExit(t *testing.T) {
 		t.Fatal("read_file tool should still be registered")
 	}
 }
+
+func TestNewAgentInstance_ToolsAllowAndDeny(t *testing.T) {
+	workspace := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace: workspace,
+				ModelName: "test-model",
+			},
+		},
+		Tools: config.ToolsConfig{
+			ReadFile:  config.ReadFileToolConfig{Enabled: true},
+			WriteFile: config.ToolConfig{Enabled: true},
+			ListDir:   config.ToolConfig{Enabled: true},
+			Exec:      config.ExecConfig{ToolConfig: config.ToolConfig{Enabled: true}},
+		},
+	}
+
+	all := NewAgentInstance(&config.AgentConfig{ID: "all"}, &cfg.Agents.Defaults, cfg, &mockProvider{})
+	for _, name := range []string{"read_file", "write_file", "list_dir", "exec"} {
+		if _, ok := all.Tools.Get(name); !ok {
+			t.Fatalf("agent without lists should have tool %q", name)
+		}
+	}
+
+	denied := NewAgentInstance(&config.AgentConfig{
+		ID:        "reader",
+		ToolsDeny: []string{"exec", "write_*"},
+	}, &cfg.Agents.Defaults, cfg, &mockProvider{})
+	for _, name := range []string{"exec", "write_file"} {
+		if _, ok := denied.Tools.Get(name); ok {
+			t.Errorf("denied tool %q should not be registered", name)
+		}
+	}
+	for _, name := range []string{"read_file", "list_dir"} {
+		if _, ok := denied.Tools.Get(name); !ok {
+			t.Errorf("tool %q should remain registered", name)
+		}
+	}
+
+	allowed := NewAgentInstance(&config.AgentConfig{
+		ID:         "lister",
+		ToolsAllow: []string{"list_dir"},
+	}, &cfg.Agents.Defaults, cfg, &mockProvider{})
+	if names := allowed.Tools.List(); len(names) != 1 || names[0] != "list_dir" {
+		t.Errorf("allow list should leave only list_dir, got %v", names)
+	}
+}
+
+func TestNewAgentInstance_AuditLogOutsideWorkspace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(config.EnvHome, home)
+	workspace := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{Workspace: workspace, ModelName: "test-model"},
+		},
+		Tools: config.ToolsConfig{
+			AuditLog: true,
+			ListDir:  config.ToolConfig{Enabled: true},
+		},
+	}
+
+	agent := NewAgentInstance(nil, &cfg.Agents.Defaults, cfg, &mockProvider{})
+	agent.Tools.Execute(context.Background(), "list_dir", map[string]any{"path": "."})
+
+	if _, err := os.Stat(filepath.Join(home, "logs", "tool_audit.jsonl")); err != nil {
+		t.Errorf("audit log not written under PICOCLAW_HOME: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "logs", "tool_audit.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("audit log should not be written to the workspace, stat err = %v", err)
+	}
+}
+
+func TestNewAgentInstance_AgentSystemPrompt(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "AGENTS.md"), []byte("Shared base rules."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "persona.md"), []byte("Speak like a pirate.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace: tmpDir,
+				Model:     "test-model",
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		agentCfg *config.AgentConfig
+		want     string
+	}{
+		{
+			name:     "inline",
+			agentCfg: &config.AgentConfig{ID: "inline", SystemPrompt: "  You review code.  "},
+			want:     "# Agent Instructions\n\nYou review code.",
+		},
+		{
+			name:     "file relative to workspace",
+			agentCfg: &config.AgentConfig{ID: "file", SystemPromptFile: "persona.md"},
+			want:     "# Agent Instructions\n\nSpeak like a pirate.",
+		},
+		{
+			name: "inline and file",
+			agentCfg: &config.AgentConfig{
+				ID:               "both",
+				SystemPrompt:     "You review code.",
+				SystemPromptFile: filepath.Join(tmpDir, "persona.md"),
+			},
+			want: "# Agent Instructions\n\nYou review code.\n\nSpeak like a pirate.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.agentCfg.Workspace = tmpDir
+			agent := NewAgentInstance(tt.agentCfg, &cfg.Agents.Defaults, cfg, &mockProvider{})
+			prompt := agent.ContextBuilder.BuildSystemPrompt()
+
+			if !strings.Contains(prompt, tt.want) {
+				t.Fatalf("system prompt missing %q:\n%s", tt.want, prompt)
+			}
+			// The agent prompt is merged into, not substituted for, the shared base.
+			base := strings.Index(prompt, "Shared base rules.")
+			own := strings.Index(prompt, "# Agent Instructions")
+			if base < 0 || own < base {
+				t.Fatalf("agent instructions should follow the shared base prompt:\n%s", prompt)
+			}
+		})
+	}
+
+	plain := NewAgentInstance(&config.AgentConfig{ID: "plain", Workspace: tmpDir}, &cfg.Agents.Defaults, cfg, &mockProvider{})
+	if strings.Contains(plain.ContextBuilder.BuildSystemPrompt(), "# Agent Instructions") {
+		t.Fatal("agent without a system prompt should not get an instructions section")
+	}
+}
@@ -26,8 +26,10 @@ import (
 	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/metrics"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/session"
 	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/state"
 	"github.com/sipeed/picoclaw/pkg/tools"
@@ -50,6 +52,9 @@ type AgentLoop struct {
 	mcp            mcpRuntime
 	mu             sync.RWMutex
 	reloadFunc     func() error
+	turns          activeTurns
+	inBand         chan queuedTurn // turns submitted by ProcessInbound
+	lastTurns      sync.Map        // session key → lastTurn, for /retry
 	// Track active requests for safe provider cleanup
 	activeRequests sync.WaitGroup
 }
@@ -67,19 +72,36 @@ type processOptions struct {
 	EnableSummary     bool     // Whether to trigger summarization
 	SendResponse      bool     // Whether to send response via bus
 	NoHistory         bool     // If true, don't load session history (for heartbeat)
+	DryRun            bool     // If true, tools are not executed and nothing is sent or persisted
+	InBand            bool     // Reply goes back to the caller; nothing is published to the channel
+	Temperature       *float64 // Per-turn temperature override from inline flags
+	MaxTokens         int      // Per-turn max_tokens override from inline flags; 0 = agent default
 }
 
 const (
 	defaultResponse           = "The model returned an empty response. This may indicate a provider error or token limit."
-	toolLimitResponse         = "I've reached `max_tool_iterations` without a final response. Increase `max_tool_iterations` in config.json if this task needs more tool steps."
 	sessionKeyAgentPrefix     = "agent:"
 	metadataKeyAccountID      = "account_id"
 	metadataKeyGuildID        = "guild_id"
 	metadataKeyTeamID         = "team_id"
 	metadataKeyParentPeerKind = "parent_peer_kind"
 	metadataKeyParentPeerID   = "parent_peer_id"
+	metadataKeyModel          = "model"
+	metadataKeyDryRun         = "dry_run"
+	metadataKeyTemperature    = "temperature"
+	metadataKeyMaxTokens      = "max_tokens"
 )
 
+// toolLimitResponse is the reply used when a turn stops at max_tool_iterations
+// without the model producing a final answer.
+func toolLimitResponse(limit int) string {
+	return fmt.Sprintf(
+		"Stopped after %d tool iterations without a final response. "+
+			"Increase `max_tool_iterations` in config.json if this task needs more tool steps.",
+		limit,
+	)
+}
+
 func NewAgentLoop(
 	cfg *config.Config,
 	msgBus *bus.MessageBus,
@@ -109,6 +131,7 @@ func NewAgentLoop(
 		summarizing: sync.Map{},
 		fallback:    fallbackChain,
 		cmdRegistry: commands.NewRegistry(commands.BuiltinDefinitions()),
+		inBand:      make(chan queuedTurn),
 	}
 
 	return al
@@ -187,13 +210,14 @@ func registerSharedTools(
 		// Message tool
 		if cfg.Tools.IsToolEnabled("message") {
 			messageTool := tools.NewMessageTool()
-			messageTool.SetSendCallback(func(channel, chatID, content string) error {
-				pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			messageTool.SetSendCallback(func(ctx context.Context, channel, chatID, content string, editLast bool) error {
+				pubCtx, pubCancel := context.WithTimeout(ctx, 5*time.Second)
 				defer pubCancel()
 				return msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
-					Channel: channel,
-					ChatID:  chatID,
-					Content: content,
+					Channel:  channel,
+					ChatID:   chatID,
+					Content:  content,
+					EditLast: editLast,
 				})
 			})
 			agent.Tools.Register(messageTool)
@@ -219,6 +243,8 @@ func registerSharedTools(
 			registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
 				MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
 				ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
+				AllowedDomains:        cfg.Tools.Skills.Github.AllowedDomains,
+				MaxDownloadSize:       cfg.Tools.Skills.Github.MaxDownloadSize,
 			})
 
 			if find_skills_enable {
@@ -231,6 +257,7 @@ func registerSharedTools(
 
 			if install_skills_enable {
 				agent.Tools.Register(tools.NewInstallSkillTool(registryMgr, agent.Workspace))
+				agent.Tools.Register(tools.NewUpdateSkillTool(registryMgr, agent.Workspace))
 			}
 		}
 
@@ -241,6 +268,7 @@ func registerSharedTools(
 		if (spawnEnabled || spawnStatusEnabled) && cfg.Tools.IsToolEnabled("subagent") {
 			subagentManager := tools.NewSubagentManager(provider, agent.Model, agent.Workspace)
 			subagentManager.SetLLMOptions(agent.MaxTokens, agent.Temperature)
+			subagentManager.SetRequestTimeout(agent.RequestTimeout)
 			// Clone the parent's tool registry so subagents can use all
 			// tools registered so far (file, web, etc.) but NOT spawn/
 			// spawn_status which are added below — preventing recursive
@@ -270,81 +298,153 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 		return err
 	}
 
+	// Turns run one at a time, whether they arrive on the bus or in-band
+	// through ProcessInbound. While one is in progress both sources are
+	// still drained so /cancel can interrupt it; other turns wait in
+	// pending, up to the configured queue limits, and are processed in
+	// arrival order.
+	var pending turnQueue
 	for al.running.Load() {
-		select {
-		case <-ctx.Done():
-			return nil
-		case msg, ok := <-al.bus.InboundChan():
-			if !ok {
+		turn, ok := pending.pop()
+		if !ok {
+			select {
+			case <-ctx.Done():
 				return nil
-			}
-			// Process message
-			func() {
-				defer func() {
-					if al.channelManager != nil {
-						al.channelManager.InvokeTypingStop(msg.Channel, msg.ChatID)
-					}
-				}()
-				// TODO: Re-enable media cleanup after inbound media is properly consumed by the agent.
-				// Currently disabled because files are deleted before the LLM can access their content.
-				// defer func() {
-				// 	if al.mediaStore != nil && msg.MediaScope != "" {
-				// 		if releaseErr := al.mediaStore.ReleaseAll(msg.MediaScope); releaseErr != nil {
-				// 			logger.WarnCF("agent", "Failed to release media", map[string]any{
-				// 				"scope": msg.MediaScope,
-				// 				"error": releaseErr.Error(),
-				// 			})
-				// 		}
-				// 	}
-				// }()
-
-				response, err := al.processMessage(ctx, msg)
-				if err != nil {
-					response = fmt.Sprintf("Error processing message: %v", err)
+			case msg, ok := <-al.bus.InboundChan():
+				if !ok {
+					return nil
 				}
+				turn = queuedTurn{msg: msg}
+			case turn = <-al.inBand:
+			default:
+				time.Sleep(time.Microsecond * 200)
+				continue
+			}
+		}
 
-				if response != "" {
-					// Check if the message tool already sent a response during this round.
-					// If so, skip publishing to avoid duplicate messages to the user.
-					// Use default agent's tools to check (message tool is shared).
-					alreadySent := false
-					defaultAgent := al.GetRegistry().GetDefaultAgent()
-					if defaultAgent != nil {
-						if tool, ok := defaultAgent.Tools.Get("message"); ok {
-							if mt, ok := tool.(*tools.MessageTool); ok {
-								alreadySent = mt.HasSentInRound()
-							}
-						}
-					}
-					if !alreadySent {
-						al.bus.PublishOutbound(ctx, bus.OutboundMessage{
-							Channel: msg.Channel,
-							ChatID:  msg.ChatID,
-							Content: response,
-						})
-						logger.InfoCF("agent", "Published outbound response",
-							map[string]any{
-								"channel":     msg.Channel,
-								"chat_id":     msg.ChatID,
-								"content_len": len(response),
-							})
-					} else {
-						logger.DebugCF(
-							"agent",
-							"Skipped outbound (message tool already sent)",
-							map[string]any{"channel": msg.Channel},
-						)
-					}
+		if al.handleCancelCommand(ctx, turn) {
+			continue
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			al.handleInbound(ctx, turn)
+		}()
+		for waiting := true; waiting; {
+			var next queuedTurn
+			select {
+			case <-done:
+				waiting = false
+				continue
+			case msg, ok := <-al.bus.InboundChan():
+				if !ok {
+					<-done
+					return nil
 				}
-			}()
-		default:
-			time.Sleep(time.Microsecond * 200)
+				next = queuedTurn{msg: msg}
+			case next = <-al.inBand:
+			}
+			if !al.handleCancelCommand(ctx, next) {
+				al.enqueueTurn(ctx, &pending, next)
+			}
 		}
 	}
 
 	return nil
 }
 
+// handleInbound processes one turn and publishes the response, or returns
+// it to the caller for in-band turns.
+func (al *AgentLoop) handleInbound(ctx context.Context, turn queuedTurn) {
+	msg := turn.msg
+	// Messages published by channels already carry a trace ID; those
+	// from cron, heartbeat or subagents get one here.
+	if msg.TraceID == "" {
+		msg.TraceID = bus.NewTraceID()
+	}
+	ctx = bus.WithTraceID(ctx, msg.TraceID)
+	defer func() {
+		if al.channelManager != nil {
+			al.channelManager.InvokeTypingStop(msg.Channel, msg.ChatID)
+		}
+	}()
+	// TODO: Re-enable media cleanup after inbound media is properly consumed by the agent.
+	// Currently disabled because files are deleted before the LLM can access their content.
+	// defer func() {
+	// 	if al.mediaStore != nil && msg.MediaScope != "" {
+	// 		if releaseErr := al.mediaStore.ReleaseAll(msg.MediaScope); releaseErr != nil {
+	// 			logger.WarnCF("agent", "Failed to release media", map[string]any{
+	// 				"scope": msg.MediaScope,
+	// 				"error": releaseErr.Error(),
+	// 			})
+	// 		}
+	// 	}
+	// }()
+
+	if turn.inBand() {
+		// The turn also ends when the caller gives up on it.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(withInBand(ctx))
+		defer cancel()
+		stop := context.AfterFunc(turn.ctx, cancel)
+		defer stop()
+	}
+
+	turnCtx, endTurn := al.turns.begin(ctx, msg.Channel, msg.ChatID)
+	response, err := al.processMessage(turnCtx, msg)
+	if endTurn() {
+		// The /cancel reply already told the user; drop the partial turn.
+		if turn.inBand() {
+			turn.respond("", errTurnCancelled)
+		}
+		return
+	}
+	if turn.inBand() {
+		turn.respond(response, err)
+		return
+	}
+	if err != nil {
+		response = fmt.Sprintf("Error processing message: %v", err)
+	}
+
+	if response != "" {
+		// Check if the message tool already sent a response during this round.
+		// If so, skip publishing to avoid duplicate messages to the user.
+		// Use default agent's tools to check (message tool is shared).
+		alreadySent := false
+		defaultAgent := al.GetRegistry().GetDefaultAgent()
+		if defaultAgent != nil {
+			if tool, ok := defaultAgent.Tools.Get("message"); ok {
+				if mt, ok := tool.(*tools.MessageTool); ok {
+					alreadySent = mt.HasSentInRound()
+				}
+			}
+		}
+		if !alreadySent {
+			al.bus.PublishOutbound(ctx, bus.OutboundMessage{
+				Channel: msg.Channel,
+				ChatID:  msg.ChatID,
+				Content: response,
+				Final:   true,
+			})
+			logger.InfoCF("agent", "Published outbound response",
+				map[string]any{
+					"channel":     msg.Channel,
+					"chat_id":     msg.ChatID,
+					"content_len": len(response),
+					"trace_id":    msg.TraceID,
+				})
+		} else {
+			logger.DebugCF(
+				"agent",
+				"Skipped outbound (message tool already sent)",
+				map[string]any{"channel": msg.Channel},
+			)
+		}
+	}
+}
+
 func (al *AgentLoop) Stop() {
 	al.running.Store(false)
 }
@@ -682,6 +782,26 @@ func (al *AgentLoop) ProcessDirectWithChannel(
 	return al.processMessage(ctx, msg)
 }
 
+// ProcessInbound runs msg as a turn and returns the reply instead of
+// publishing it. It is used by callers that answer in-band, such as the
+// gateway's OpenAI-compatible API. The turn goes through the same queue as
+// bus messages, so Run must be running; it returns ErrTurnQueueFull when
+// the message is shed.
+func (al *AgentLoop) ProcessInbound(ctx context.Context, msg bus.InboundMessage) (string, error) {
+	reply := make(chan turnResult, 1)
+	select {
+	case al.inBand <- queuedTurn{msg: msg, ctx: ctx, reply: reply}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	select {
+	case res := <-reply:
+		return res.content, res.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
 // ProcessHeartbeat processes a heartbeat request without session history.
 // Each heartbeat is independent and doesn't accumulate context.
 func (al *AgentLoop) ProcessHeartbeat(
@@ -720,6 +840,7 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 			"chat_id":     msg.ChatID,
 			"sender_id":   msg.SenderID,
 			"session_key": msg.SessionKey,
+			"trace_id":    msg.TraceID,
 		},
 	)
 
@@ -774,6 +895,14 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		DefaultResponse:   defaultResponse,
 		EnableSummary:     true,
 		SendResponse:      false,
+		InBand:            isInBand(ctx),
+		DryRun:            inboundMetadata(msg, metadataKeyDryRun) == "true",
+	}
+
+	if opts.DryRun {
+		if name, ok := al.registeredCommand(msg.Content); ok {
+			return fmt.Sprintf("[dry run] /%s was not run: commands are not available in a dry run.", name), nil
+		}
 	}
 
 	// context-dependent commands check their own Runtime fields and report
@@ -782,9 +911,117 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		return response, nil
 	}
 
+	flags, content, err := parseInlineFlags(opts.UserMessage)
+	if err == nil {
+		// Callers such as the OpenAI-compatible API pass overrides as metadata.
+		if v := inboundMetadata(msg, metadataKeyTemperature); v != "" {
+			err = flags.set("--temp", v)
+		}
+		if v := inboundMetadata(msg, metadataKeyMaxTokens); v != "" && err == nil {
+			err = flags.set("--max", v)
+		}
+	}
+	if err != nil {
+		return fmt.Sprintf("Invalid flags: %v", err), nil
+	}
+	opts.UserMessage = content
+	opts.Temperature = flags.Temperature
+	opts.MaxTokens = flags.MaxTokens
+
+	if modelName := inboundMetadata(msg, metadataKeyModel); modelName != "" {
+		turnAgent, err := al.withModelOverride(agent, modelName)
+		if err != nil {
+			return "", err
+		}
+		if stateful, ok := turnAgent.Provider.(providers.StatefulProvider); ok {
+			defer stateful.Close()
+		}
+		agent = turnAgent
+	}
+
+	if opts.DryRun {
+		agent = withDryRunSessions(agent, opts.SessionKey)
+		opts.EnableSummary = false
+	} else {
+		al.rememberTurn(agent, opts)
+	}
+
 	return al.runAgentLoop(ctx, agent, opts)
 }
 
+// withDryRunSessions returns a copy of agent whose session store is an
+// in-memory snapshot of sessionKey, so a dry-run turn sees the real history
+// but leaves nothing behind.
+func withDryRunSessions(agent *AgentInstance, sessionKey string) *AgentInstance {
+	sessions := session.NewSessionManager("")
+	sessions.SetHistory(sessionKey, agent.Sessions.GetHistory(sessionKey))
+	sessions.SetSummary(sessionKey, agent.Sessions.GetSummary(sessionKey))
+
+	dryRun := *agent
+	dryRun.Sessions = sessions
+	return &dryRun
+}
+
+// dryRunToolResult stands in for a tool call during a dry run.
+func dryRunToolResult(name string) *tools.ToolResult {
+	return tools.SilentResult(fmt.Sprintf(
+		"Dry run: %s was not executed. Assume it succeeded and continue without repeating the call.", name))
+}
+
+// formatDryRunReport appends the tool calls made during a dry-run turn to
+// the response so the caller can see what would have happened.
+func formatDryRunReport(content string, turn []providers.Message) string {
+	var calls []string
+	for _, msg := range turn {
+		for _, tc := range msg.ToolCalls {
+			args := "{}"
+			if tc.Function != nil && tc.Function.Arguments != "" {
+				args = tc.Function.Arguments
+			}
+			calls = append(calls, fmt.Sprintf("- %s %s", tc.Name, args))
+		}
+	}
+
+	report := "[dry run] No tool calls."
+	if len(calls) > 0 {
+		report = "[dry run] Tool calls not executed:\n" + strings.Join(calls, "\n")
+	}
+	return content + "\n\n" + report
+}
+
+// withModelOverride returns a copy of agent that uses the model_list entry
+// modelName for a single turn. Sessions, tools and context are shared with
+// the original; light-model routing is disabled so the caller's choice holds.
+func (al *AgentLoop) withModelOverride(agent *AgentInstance, modelName string) (*AgentInstance, error) {
+	cfg := al.GetConfig()
+	modelName = strings.TrimSpace(modelName)
+	modelCfg, err := resolvedModelConfig(cfg, modelName, agent.Workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, _, err := providers.CreateProviderFromConfig(modelCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize model %q: %w", modelName, err)
+	}
+
+	candidates := resolveModelCandidates(cfg, cfg.Agents.Defaults.Provider, modelCfg.Model, nil)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("model %q did not resolve to any provider candidates", modelName)
+	}
+
+	override := *agent
+	override.Model = modelName
+	override.Provider = provider
+	override.Candidates = candidates
+	override.ThinkingLevel = parseThinkingLevel(modelCfg.ThinkingLevel)
+	override.RequestTimeout = time.Duration(modelCfg.RequestTimeout) * time.Second
+	override.TextOnly = !modelCfg.SupportsVision()
+	override.Router = nil
+	override.LightCandidates = nil
+	return &override, nil
+}
+
 func (al *AgentLoop) resolveMessageRoute(msg bus.InboundMessage) (routing.ResolvedRoute, *AgentInstance, error) {
 	registry := al.GetRegistry()
 	route := registry.ResolveRoute(routing.RouteInput{
@@ -885,8 +1122,12 @@ func (al *AgentLoop) runAgentLoop(
 	agent *AgentInstance,
 	opts processOptions,
 ) (string, error) {
+	metrics.AgentTurns.Inc(agent.ID)
+	metrics.AgentTurnsInFlight.Inc()
+	defer metrics.AgentTurnsInFlight.Dec()
+
 	// 0. Record last channel for heartbeat notifications (skip internal channels and cli)
-	if opts.Channel != "" && opts.ChatID != "" {
+	if opts.Channel != "" && opts.ChatID != "" && !opts.DryRun && !opts.InBand {
 		if !constants.IsInternalChannel(opts.Channel) {
 			channelKey := fmt.Sprintf("%s:%s", opts.Channel, opts.ChatID)
 			if err := al.RecordLastChannel(channelKey); err != nil {
@@ -903,7 +1144,7 @@ func (al *AgentLoop) runAgentLoop(
 	var history []providers.Message
 	var summary string
 	if !opts.NoHistory {
-		history = agent.Sessions.GetHistory(opts.SessionKey)
+		history = limitHistoryTurns(agent.Sessions.GetHistory(opts.SessionKey), agent.MaxHistoryTurns)
 		summary = agent.Sessions.GetSummary(opts.SessionKey)
 	}
 	messages := agent.ContextBuilder.BuildMessages(
@@ -921,13 +1162,25 @@ func (al *AgentLoop) runAgentLoop(
 	cfg := al.GetConfig()
 	maxMediaSize := cfg.Agents.Defaults.GetMaxMediaSize()
 	messages = resolveMediaRefs(messages, al.mediaStore, maxMediaSize)
+	if agent.TextOnly {
+		messages = dropImages(messages)
+	}
 
 	// 2. Save user message to session
+	turnStart := len(agent.Sessions.GetHistory(opts.SessionKey))
 	agent.Sessions.AddMessage(opts.SessionKey, "user", opts.UserMessage)
 
 	// 3. Run LLM iteration loop
 	finalContent, iteration, err := al.runLLMIteration(ctx, agent, messages, opts)
 	if err != nil {
+		if ctx.Err() != nil {
+			// The turn was cancelled: drop its partial messages so the next
+			// turn continues from the last completed one.
+			if history := agent.Sessions.GetHistory(opts.SessionKey); turnStart <= len(history) {
+				agent.Sessions.SetHistory(opts.SessionKey, history[:turnStart])
+				agent.Sessions.Save(opts.SessionKey)
+			}
+		}
 		return "", err
 	}
 
@@ -937,12 +1190,26 @@ func (al *AgentLoop) runAgentLoop(
 	// 4. Handle empty response
 	if finalContent == "" {
 		if iteration >= agent.MaxIterations && agent.MaxIterations > 0 {
-			finalContent = toolLimitResponse
+			logger.WarnCF("agent", "Tool iteration limit reached",
+				map[string]any{
+					"agent_id":    agent.ID,
+					"session_key": opts.SessionKey,
+					"max":         agent.MaxIterations,
+				})
+			finalContent = toolLimitResponse(agent.MaxIterations)
 		} else {
 			finalContent = opts.DefaultResponse
 		}
 	}
 
+	if opts.DryRun {
+		var turn []providers.Message
+		if history := agent.Sessions.GetHistory(opts.SessionKey); turnStart < len(history) {
+			turn = history[turnStart:]
+		}
+		finalContent = formatDryRunReport(finalContent, turn)
+	}
+
 	// 5. Save final assistant message to session
 	agent.Sessions.AddMessage(opts.SessionKey, "assistant", finalContent)
 	agent.Sessions.Save(opts.SessionKey)
@@ -958,6 +1225,7 @@ func (al *AgentLoop) runAgentLoop(
 			Channel: opts.Channel,
 			ChatID:  opts.ChatID,
 			Content: finalContent,
+			Final:   true,
 		})
 	}
 
@@ -1044,7 +1312,7 @@ func (al *AgentLoop) runLLMIteration(
 	// Check if both the provider and channel support streaming
 	streamProvider, providerCanStream := agent.Provider.(providers.StreamingProvider)
 	var streamer bus.Streamer
-	if providerCanStream && !opts.NoHistory && !constants.IsInternalChannel(opts.Channel) {
+	if providerCanStream && !opts.NoHistory && !opts.InBand && !constants.IsInternalChannel(opts.Channel) {
 		streamer, _ = al.bus.GetStreamer(ctx, opts.Channel, opts.ChatID)
 	}
 
@@ -1080,17 +1348,26 @@ func (al *AgentLoop) runLLMIteration(
 			providerToolDefs = filterClientWebSearch(providerToolDefs)
 		}
 
+		maxTokens, temperature := agent.MaxTokens, agent.Temperature
+		if opts.MaxTokens > 0 {
+			maxTokens = opts.MaxTokens
+		}
+		if opts.Temperature != nil {
+			temperature = *opts.Temperature
+		}
+
 		// Log LLM request details
 		logger.DebugCF("agent", "LLM request",
 			map[string]any{
+				"trace_id":          bus.TraceIDFromContext(ctx),
 				"agent_id":          agent.ID,
 				"iteration":         iteration,
 				"model":             activeModel,
 				"messages_count":    len(messages),
 				"tools_count":       len(providerToolDefs),
 				"native_search":     useNativeSearch,
-				"max_tokens":        agent.MaxTokens,
-				"temperature":       agent.Temperature,
+				"max_tokens":        maxTokens,
+				"temperature":       temperature,
 				"system_prompt_len": len(messages[0].Content),
 			})
 
@@ -1107,8 +1384,8 @@ func (al *AgentLoop) runLLMIteration(
 		var err error
 
 		llmOpts := map[string]any{
-			"max_tokens":       agent.MaxTokens,
-			"temperature":      agent.Temperature,
+			"max_tokens":       maxTokens,
+			"temperature":      temperature,
 			"prompt_cache_key": agent.ID,
 		}
 		if useNativeSearch {
@@ -1131,12 +1408,15 @@ func (al *AgentLoop) runLLMIteration(
 
 			// Use streaming when available (streamer obtained, provider supports it)
 			if streamer != nil && streamProvider != nil {
-				return streamProvider.ChatStream(
-					ctx, messages, providerToolDefs, activeModel, llmOpts,
-					func(accumulated string) {
-						streamer.Update(ctx, accumulated)
-					},
-				)
+				return providers.CallWithTimeout(ctx, agent.RequestTimeout, activeModel,
+					func(ctx context.Context) (*providers.LLMResponse, error) {
+						return streamProvider.ChatStream(
+							ctx, messages, providerToolDefs, activeModel, llmOpts,
+							func(accumulated string) {
+								streamer.Update(ctx, accumulated)
+							},
+						)
+					})
 			}
 
 			if len(activeCandidates) > 1 && al.fallback != nil {
@@ -1144,7 +1424,14 @@ func (al *AgentLoop) runLLMIteration(
 					ctx,
 					activeCandidates,
 					func(ctx context.Context, provider, model string) (*providers.LLMResponse, error) {
-						return agent.Provider.Chat(ctx, messages, providerToolDefs, model, llmOpts)
+						timeout := candidateRequestTimeout(al.GetConfig(), providers.FallbackCandidate{
+							Provider: provider,
+							Model:    model,
+						})
+						return providers.CallWithTimeout(ctx, timeout, model,
+							func(ctx context.Context) (*providers.LLMResponse, error) {
+								return agent.Provider.Chat(ctx, messages, providerToolDefs, model, llmOpts)
+							})
 					},
 				)
 				if fbErr != nil {
@@ -1160,7 +1447,10 @@ func (al *AgentLoop) runLLMIteration(
 				}
 				return fbResult.Response, nil
 			}
-			return agent.Provider.Chat(ctx, messages, providerToolDefs, activeModel, llmOpts)
+			return providers.CallWithTimeout(ctx, agent.RequestTimeout, activeModel,
+				func(ctx context.Context) (*providers.LLMResponse, error) {
+					return agent.Provider.Chat(ctx, messages, providerToolDefs, activeModel, llmOpts)
+				})
 		}
 
 		// Retry loop for context/token errors
@@ -1212,7 +1502,7 @@ func (al *AgentLoop) runLLMIteration(
 					},
 				)
 
-				if retry == 0 && !constants.IsInternalChannel(opts.Channel) {
+				if retry == 0 && !opts.InBand && !constants.IsInternalChannel(opts.Channel) {
 					al.bus.PublishOutbound(ctx, bus.OutboundMessage{
 						Channel: opts.Channel,
 						ChatID:  opts.ChatID,
@@ -1221,7 +1511,7 @@ func (al *AgentLoop) runLLMIteration(
 				}
 
 				al.forceCompression(agent, opts.SessionKey)
-				newHistory := agent.Sessions.GetHistory(opts.SessionKey)
+				newHistory := limitHistoryTurns(agent.Sessions.GetHistory(opts.SessionKey), agent.MaxHistoryTurns)
 				newSummary := agent.Sessions.GetSummary(opts.SessionKey)
 				messages = agent.ContextBuilder.BuildMessages(
 					newHistory, newSummary, "",
@@ -1235,20 +1525,30 @@ func (al *AgentLoop) runLLMIteration(
 		if err != nil {
 			logger.ErrorCF("agent", "LLM call failed",
 				map[string]any{
+					"trace_id":  bus.TraceIDFromContext(ctx),
 					"agent_id":  agent.ID,
 					"iteration": iteration,
 					"model":     activeModel,
 					"error":     err.Error(),
 				})
+			metrics.ProviderErrors.Inc(activeModel)
 			return "", iteration, fmt.Errorf("LLM call failed after retries: %w", err)
 		}
 
-		go al.handleReasoning(
-			ctx,
-			response.Reasoning,
-			opts.Channel,
-			al.targetReasoningChannelID(opts.Channel),
-		)
+		if response.Usage != nil {
+			metrics.TokensUsed.Add(float64(response.Usage.PromptTokens), activeModel, "prompt")
+			metrics.TokensUsed.Add(float64(response.Usage.CompletionTokens), activeModel, "completion")
+		}
+		agent.EvalLog.record(agent.ID, activeModel, opts.Channel, messages, response)
+
+		if !opts.DryRun {
+			go al.handleReasoning(
+				ctx,
+				response.Reasoning,
+				opts.Channel,
+				al.targetReasoningChannelID(opts.Channel),
+			)
+		}
 
 		logger.DebugCF("agent", "LLM response",
 			map[string]any{
@@ -1368,7 +1668,7 @@ func (al *AgentLoop) runLLMIteration(
 					})
 
 				// Send tool feedback to chat channel if enabled
-				if al.cfg.Agents.Defaults.IsToolFeedbackEnabled() && opts.Channel != "" {
+				if al.cfg.Agents.Defaults.IsToolFeedbackEnabled() && opts.Channel != "" && !opts.DryRun {
 					feedbackPreview := utils.Truncate(
 						string(argsJSON),
 						al.cfg.Agents.Defaults.GetToolFeedbackMaxArgsLength(),
@@ -1391,7 +1691,7 @@ func (al *AgentLoop) runLLMIteration(
 					// Send ForUser content directly to the user (immediate feedback),
 					// mirroring the synchronous tool execution path.
 					if !result.Silent && result.ForUser != "" {
-						outCtx, outCancel := context.WithTimeout(context.Background(), 5*time.Second)
+						outCtx, outCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
 						defer outCancel()
 						_ = al.bus.PublishOutbound(outCtx, bus.OutboundMessage{
 							Channel: opts.Channel,
@@ -1426,6 +1726,11 @@ func (al *AgentLoop) runLLMIteration(
 					})
 				}
 
+				if opts.DryRun {
+					agentResults[idx].result = dryRunToolResult(tc.Name)
+					return
+				}
+
 				toolResult := agent.Tools.ExecuteWithContext(
 					ctx,
 					tc.Name,
@@ -1831,17 +2136,20 @@ func (al *AgentLoop) retryLLMCall(
 		al.activeRequests.Add(1)
 		resp, err = func() (*providers.LLMResponse, error) {
 			defer al.activeRequests.Done()
-			return agent.Provider.Chat(
-				ctx,
-				[]providers.Message{{Role: "user", Content: prompt}},
-				nil,
-				agent.Model,
-				map[string]any{
-					"max_tokens":       agent.MaxTokens,
-					"temperature":      llmTemperature,
-					"prompt_cache_key": agent.ID,
-				},
-			)
+			return providers.CallWithTimeout(ctx, agent.RequestTimeout, agent.Model,
+				func(ctx context.Context) (*providers.LLMResponse, error) {
+					return agent.Provider.Chat(
+						ctx,
+						[]providers.Message{{Role: "user", Content: prompt}},
+						nil,
+						agent.Model,
+						map[string]any{
+							"max_tokens":       agent.MaxTokens,
+							"temperature":      llmTemperature,
+							"prompt_cache_key": agent.ID,
+						},
+					)
+				})
 		}()
 
 		if err == nil && resp != nil && resp.Content != "" {
@@ -1975,6 +2283,23 @@ func (al *AgentLoop) handleCommand(
 	}
 }
 
+// registeredCommand returns the name of the registered command content
+// invokes, if any. Unknown commands fall through to the LLM.
+func (al *AgentLoop) registeredCommand(content string) (string, bool) {
+	if al.cmdRegistry == nil {
+		return "", false
+	}
+	name, ok := commands.CommandName(content)
+	if !ok {
+		return "", false
+	}
+	def, found := al.cmdRegistry.Lookup(name)
+	if !found {
+		return "", false
+	}
+	return def.Name, true
+}
+
 func (al *AgentLoop) buildCommandsRuntime(agent *AgentInstance, opts *processOptions) *commands.Runtime {
 	registry := al.GetRegistry()
 	cfg := al.GetConfig()
@@ -2005,6 +2330,9 @@ func (al *AgentLoop) buildCommandsRuntime(agent *AgentInstance, opts *processOpt
 		return al.reloadFunc()
 	}
 	if agent != nil {
+		rt.ListCapabilities = func() string {
+			return tools.FormatCapabilities(agent.Tools, agent.ContextBuilder.ListSkills())
+		}
 		rt.GetModelInfo = func() (string, string) {
 			return agent.Model, resolvedCandidateProvider(agent.Candidates, cfg.Agents.Defaults.Provider)
 		}
@@ -2031,6 +2359,8 @@ func (al *AgentLoop) buildCommandsRuntime(agent *AgentInstance, opts *processOpt
 			agent.Provider = nextProvider
 			agent.Candidates = nextCandidates
 			agent.ThinkingLevel = parseThinkingLevel(modelCfg.ThinkingLevel)
+			agent.RequestTimeout = time.Duration(modelCfg.RequestTimeout) * time.Second
+			agent.TextOnly = !modelCfg.SupportsVision()
 
 			if oldProvider != nil && oldProvider != nextProvider {
 				if stateful, ok := oldProvider.(providers.StatefulProvider); ok {
@@ -2040,6 +2370,20 @@ func (al *AgentLoop) buildCommandsRuntime(agent *AgentInstance, opts *processOpt
 			return oldModel, nil
 		}
 
+		rt.CancelTurn = func() bool {
+			if opts == nil {
+				return false
+			}
+			return al.turns.cancel(opts.Channel, opts.ChatID)
+		}
+
+		rt.RetryLast = func(ctx context.Context) (string, bool, error) {
+			if opts == nil {
+				return "", false, nil
+			}
+			return al.retryLastTurn(ctx, agent, opts.SessionKey)
+		}
+
 		rt.ClearHistory = func() error {
 			if opts == nil {
 				return fmt.Errorf("process options not available")
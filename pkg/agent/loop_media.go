@@ -87,6 +87,41 @@ func resolveMediaRefs(messages []providers.Message, store media.MediaStore, maxS
 	return result
 }
 
+// dropImages removes image data URLs from messages for models without vision
+// support, noting the omission in the message text so the model can tell the
+// user. Returns a new slice; original messages are not mutated.
+func dropImages(messages []providers.Message) []providers.Message {
+	result := make([]providers.Message, len(messages))
+	copy(result, messages)
+
+	for i, m := range result {
+		if len(m.Media) == 0 {
+			continue
+		}
+		kept := make([]string, 0, len(m.Media))
+		dropped := 0
+		for _, ref := range m.Media {
+			if strings.HasPrefix(ref, "data:image/") {
+				dropped++
+				continue
+			}
+			kept = append(kept, ref)
+		}
+		if dropped == 0 {
+			continue
+		}
+		result[i].Media = kept
+		note := "[image omitted: the current model cannot view images]"
+		if m.Content == "" {
+			result[i].Content = note
+		} else {
+			result[i].Content = m.Content + " " + note
+		}
+	}
+
+	return result
+}
+
 // detectMIME determines the MIME type from metadata or magic-bytes detection.
 // Returns empty string if detection fails.
 func detectMIME(localPath string, meta media.MediaMeta) string {
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -17,7 +19,9 @@ import (
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/metrics"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/providers/common"
 	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
@@ -116,6 +120,11 @@ func TestProcessMessage_IncludesCurrentSenderInDynamicContext(t *testing.T) {
 	if response != "Mock response" {
 		t.Fatalf("processMessage() response = %q, want %q", response, "Mock response")
 	}
+	var scraped strings.Builder
+	metrics.WriteText(&scraped)
+	if !strings.Contains(scraped.String(), `picoclaw_agent_turns_total{agent="main"}`) {
+		t.Fatalf("agent turn not recorded in metrics:\n%s", scraped.String())
+	}
 	if len(provider.lastMessages) == 0 {
 		t.Fatal("provider did not receive any messages")
 	}
@@ -547,6 +556,327 @@ func (h testHelper) executeAndGetResponse(tb testing.TB, ctx context.Context, ms
 
 const responseTimeout = 3 * time.Second
 
+func TestRun_PropagatesTraceIDToOutbound(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	al := NewAgentLoop(cfg, msgBus, &simpleMockProvider{response: "ok"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	receive := func() bus.OutboundMessage {
+		t.Helper()
+		select {
+		case out := <-msgBus.OutboundChan():
+			return out
+		case <-time.After(responseTimeout):
+			t.Fatal("timed out waiting for outbound message")
+			return bus.OutboundMessage{}
+		}
+	}
+
+	inbound := bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "user1",
+		ChatID:   "chat1",
+		Content:  "hello",
+		Peer:     bus.Peer{Kind: "direct", ID: "user1"},
+		TraceID:  "trace-abc",
+	}
+	if err := msgBus.PublishInbound(ctx, inbound); err != nil {
+		t.Fatalf("PublishInbound() error = %v", err)
+	}
+	if out := receive(); out.TraceID != "trace-abc" {
+		t.Fatalf("outbound TraceID = %q, want %q", out.TraceID, "trace-abc")
+	}
+
+	inbound.TraceID = ""
+	if err := msgBus.PublishInbound(ctx, inbound); err != nil {
+		t.Fatalf("PublishInbound() error = %v", err)
+	}
+	if out := receive(); out.TraceID == "" {
+		t.Fatal("expected a generated trace ID on outbound for an untraced inbound message")
+	}
+}
+
+// blockingOnceProvider blocks its first Chat call until the context is
+// cancelled and answers later calls immediately.
+type blockingOnceProvider struct {
+	started chan struct{}
+	calls   atomic.Int32
+}
+
+func (m *blockingOnceProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	if m.calls.Add(1) == 1 {
+		close(m.started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &providers.LLMResponse{Content: "fresh answer"}, nil
+}
+
+func (m *blockingOnceProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func TestRun_CancelInterruptsTurn(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	provider := &blockingOnceProvider{started: make(chan struct{})}
+	al := NewAgentLoop(cfg, msgBus, provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	receive := func() bus.OutboundMessage {
+		t.Helper()
+		select {
+		case out := <-msgBus.OutboundChan():
+			return out
+		case <-time.After(responseTimeout):
+			t.Fatal("timed out waiting for outbound message")
+			return bus.OutboundMessage{}
+		}
+	}
+	publish := func(content string) {
+		t.Helper()
+		err := msgBus.PublishInbound(ctx, bus.InboundMessage{
+			Channel:  "telegram",
+			SenderID: "user1",
+			ChatID:   "chat1",
+			Content:  content,
+			Peer:     bus.Peer{Kind: "direct", ID: "user1"},
+		})
+		if err != nil {
+			t.Fatalf("PublishInbound() error = %v", err)
+		}
+	}
+
+	publish("research everything")
+	select {
+	case <-provider.started:
+	case <-time.After(responseTimeout):
+		t.Fatal("turn did not start")
+	}
+
+	publish("/cancel")
+	if out := receive(); out.Content != "Cancelled." {
+		t.Fatalf("cancel reply = %q, want %q", out.Content, "Cancelled.")
+	}
+
+	publish("hello again")
+	if out := receive(); out.Content != "fresh answer" {
+		t.Fatalf("reply after cancel = %q, want %q", out.Content, "fresh answer")
+	}
+
+	// The cancelled turn left nothing behind in the session.
+	agent := al.GetRegistry().GetDefaultAgent()
+	history := agent.Sessions.GetHistory(routing.BuildAgentMainSessionKey(agent.ID))
+	if len(history) != 2 || history[0].Content != "hello again" {
+		t.Fatalf("session history = %+v, want only the second turn", history)
+	}
+
+	publish("/cancel")
+	if out := receive(); out.Content != "Nothing to cancel." {
+		t.Fatalf("idle cancel reply = %q, want %q", out.Content, "Nothing to cancel.")
+	}
+}
+
+func TestRun_ShedsTurnsBeyondQueueLimits(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:                t.TempDir(),
+				Model:                    "test-model",
+				MaxTokens:                4096,
+				MaxToolIterations:        10,
+				MaxQueuedTurns:           2,
+				MaxQueuedTurnsPerChannel: 1,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	provider := &blockingOnceProvider{started: make(chan struct{})}
+	al := NewAgentLoop(cfg, msgBus, provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	receive := func() bus.OutboundMessage {
+		t.Helper()
+		select {
+		case out := <-msgBus.OutboundChan():
+			return out
+		case <-time.After(responseTimeout):
+			t.Fatal("timed out waiting for outbound message")
+			return bus.OutboundMessage{}
+		}
+	}
+	publish := func(channel, content string) {
+		t.Helper()
+		err := msgBus.PublishInbound(ctx, bus.InboundMessage{
+			Channel:  channel,
+			SenderID: "user1",
+			ChatID:   "chat1",
+			Content:  content,
+			Peer:     bus.Peer{Kind: "direct", ID: "user1"},
+		})
+		if err != nil {
+			t.Fatalf("PublishInbound() error = %v", err)
+		}
+	}
+	expect := func(channel, content string) {
+		t.Helper()
+		out := receive()
+		if out.Channel != channel || out.Content != content {
+			t.Fatalf("outbound = %s %q, want %s %q", out.Channel, out.Content, channel, content)
+		}
+	}
+
+	publish("telegram", "long task")
+	select {
+	case <-provider.started:
+	case <-time.After(responseTimeout):
+		t.Fatal("turn did not start")
+	}
+
+	publish("telegram", "queued")       // 1 of 1 for telegram
+	publish("telegram", "over channel") // per-channel limit
+	expect("telegram", busyReply)
+	publish("discord", "queued too") // 2 of 2 overall
+	publish("slack", "over total")   // global limit
+	expect("slack", busyReply)
+
+	publish("telegram", "/cancel")
+	expect("telegram", "Cancelled.")
+	expect("telegram", "fresh answer")
+	expect("discord", "fresh answer")
+}
+
+func TestProcessInbound_QueuesBehindRunningTurn(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:                t.TempDir(),
+				Model:                    "test-model",
+				MaxTokens:                4096,
+				MaxToolIterations:        10,
+				MaxQueuedTurnsPerChannel: 1,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	provider := &blockingOnceProvider{started: make(chan struct{})}
+	al := NewAgentLoop(cfg, msgBus, provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go al.Run(ctx)
+
+	apiMsg := func(chatID, content string) bus.InboundMessage {
+		return bus.InboundMessage{
+			Channel:  "api",
+			SenderID: "api:" + chatID,
+			ChatID:   chatID,
+			Content:  content,
+			Peer:     bus.Peer{Kind: "direct", ID: chatID},
+		}
+	}
+	type result struct {
+		reply string
+		err   error
+	}
+	submit := func(msg bus.InboundMessage) <-chan result {
+		ch := make(chan result, 1)
+		go func() {
+			reply, err := al.ProcessInbound(ctx, msg)
+			ch <- result{reply, err}
+		}()
+		return ch
+	}
+	wait := func(ch <-chan result) result {
+		t.Helper()
+		select {
+		case res := <-ch:
+			return res
+		case <-time.After(responseTimeout):
+			t.Fatal("timed out waiting for ProcessInbound")
+			return result{}
+		}
+	}
+
+	first := submit(apiMsg("alice", "long task"))
+	select {
+	case <-provider.started:
+	case <-time.After(responseTimeout):
+		t.Fatal("turn did not start")
+	}
+
+	queued := submit(apiMsg("bob", "queued"))
+	time.Sleep(50 * time.Millisecond) // let Run queue bob before carol arrives
+	if res := wait(submit(apiMsg("carol", "over limit"))); !errors.Is(res.err, ErrTurnQueueFull) {
+		t.Fatalf("shed turn error = %v, want ErrTurnQueueFull", res.err)
+	}
+	select {
+	case res := <-queued:
+		t.Fatalf("queued turn ran alongside the running one: %+v", res)
+	default:
+	}
+
+	if res := wait(submit(apiMsg("alice", "/cancel"))); res.reply != "Cancelled." {
+		t.Fatalf("cancel reply = %q, want %q", res.reply, "Cancelled.")
+	}
+	if res := wait(first); !errors.Is(res.err, errTurnCancelled) {
+		t.Fatalf("cancelled turn error = %v, want errTurnCancelled", res.err)
+	}
+	if res := wait(queued); res.err != nil || res.reply != "fresh answer" {
+		t.Fatalf("queued turn = %+v, want the reply", res)
+	}
+
+	// In-band replies are never published to the bus.
+	select {
+	case out := <-msgBus.OutboundChan():
+		t.Fatalf("unexpected outbound message %+v", out)
+	default:
+	}
+}
+
 func TestProcessMessage_UsesRouteSessionKey(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "agent-test-*")
 	if err != nil {
@@ -934,6 +1264,247 @@ func TestProcessMessage_SwitchModelRoutesSubsequentRequestsToSelectedProvider(t
 	}
 }
 
+func TestProcessMessage_ModelOverrideAppliesToSingleRequest(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "agent-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	localCalls := 0
+	localModel := ""
+	localServer := newChatCompletionTestServer(t, "local", "local reply", &localCalls, &localModel)
+	defer localServer.Close()
+
+	remoteCalls := 0
+	remoteModel := ""
+	remoteServer := newChatCompletionTestServer(t, "remote", "remote reply", &remoteCalls, &remoteModel)
+	defer remoteServer.Close()
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Provider:          "openai",
+				Model:             "local",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		ModelList: []config.ModelConfig{
+			{
+				ModelName: "local",
+				Model:     "openai/local-model",
+				APIKey:    "local-key",
+				APIBase:   localServer.URL,
+			},
+			{
+				ModelName: "deepseek",
+				Model:     "openrouter/deepseek/deepseek-v3.2",
+				APIKey:    "remote-key",
+				APIBase:   remoteServer.URL,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	provider, _, err := providers.CreateProvider(cfg)
+	if err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+	al := NewAgentLoop(cfg, msgBus, provider)
+	helper := testHelper{al: al}
+
+	msg := func(content string, metadata map[string]string) bus.InboundMessage {
+		return bus.InboundMessage{
+			Channel:  "pico",
+			SenderID: "pico-user",
+			ChatID:   "pico:session-1",
+			Content:  content,
+			Peer:     bus.Peer{Kind: "direct", ID: "pico:session-1"},
+			Metadata: metadata,
+		}
+	}
+
+	overrideResp := helper.executeAndGetResponse(t, context.Background(),
+		msg("hello", map[string]string{"model": "deepseek"}))
+	if overrideResp != "remote reply" {
+		t.Fatalf("override response = %q, want %q", overrideResp, "remote reply")
+	}
+	if remoteModel != "deepseek-v3.2" {
+		t.Fatalf("remote model = %q, want %q", remoteModel, "deepseek-v3.2")
+	}
+	if localCalls != 0 {
+		t.Fatalf("local calls after override = %d, want 0", localCalls)
+	}
+
+	defaultResp := helper.executeAndGetResponse(t, context.Background(), msg("hello again", nil))
+	if defaultResp != "local reply" {
+		t.Fatalf("default response = %q, want %q", defaultResp, "local reply")
+	}
+	if localCalls != 1 || remoteCalls != 1 {
+		t.Fatalf("calls local=%d remote=%d, want 1 each", localCalls, remoteCalls)
+	}
+	if got := al.GetRegistry().GetDefaultAgent().Model; got != "local" {
+		t.Fatalf("default agent model = %q after override, want %q", got, "local")
+	}
+
+	_, err = al.processMessage(context.Background(), msg("hello", map[string]string{"model": "missing"}))
+	if err == nil || !strings.Contains(err.Error(), `model "missing" not found`) {
+		t.Fatalf("processMessage() error = %v, want unknown model error", err)
+	}
+	if localCalls != 1 || remoteCalls != 1 {
+		t.Fatalf("unknown model reached a provider: local=%d remote=%d", localCalls, remoteCalls)
+	}
+}
+
+// transferOnceProvider requests a transfer tool call once, then answers.
+type transferOnceProvider struct {
+	calls int
+}
+
+func (m *transferOnceProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.calls++
+	if m.calls == 1 {
+		return &providers.LLMResponse{
+			ToolCalls: []providers.ToolCall{{
+				ID:        "call_transfer",
+				Type:      "function",
+				Name:      "transfer_test_tool",
+				Arguments: map[string]any{"to": "0xabc", "amount": "1"},
+			}},
+		}, nil
+	}
+	return &providers.LLMResponse{Content: "Sent 1 token to 0xabc."}, nil
+}
+
+func (m *transferOnceProvider) GetDefaultModel() string {
+	return "transfer-once-model"
+}
+
+// transferTestTool records whether it was executed.
+type transferTestTool struct {
+	executed int
+}
+
+func (m *transferTestTool) Name() string        { return "transfer_test_tool" }
+func (m *transferTestTool) Description() string { return "Transfers tokens in tests" }
+func (m *transferTestTool) Parameters() map[string]any {
+	return map[string]any{"type": "object", "properties": map[string]any{}}
+}
+
+func (m *transferTestTool) Execute(ctx context.Context, args map[string]any) *tools.ToolResult {
+	m.executed++
+	return tools.NewToolResult("transferred")
+}
+
+func TestProcessMessage_DryRunSkipsToolsAndSession(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "agent-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	provider := &transferOnceProvider{}
+	al := NewAgentLoop(cfg, msgBus, provider)
+	tool := &transferTestTool{}
+	al.RegisterTool(tool)
+
+	msg := bus.InboundMessage{
+		Channel:  "pico",
+		SenderID: "pico-user",
+		ChatID:   "pico:session-1",
+		Content:  "send 1 token to 0xabc",
+		Peer:     bus.Peer{Kind: "direct", ID: "pico:session-1"},
+		Metadata: map[string]string{"dry_run": "true"},
+	}
+	response, err := al.processMessage(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+
+	if tool.executed != 0 {
+		t.Fatalf("transfer tool executed %d times in dry run, want 0", tool.executed)
+	}
+	if provider.calls != 2 {
+		t.Fatalf("provider calls = %d, want 2", provider.calls)
+	}
+	if !strings.HasPrefix(response, "Sent 1 token to 0xabc.") {
+		t.Fatalf("response = %q, want the model's text first", response)
+	}
+	if !strings.Contains(response, `- transfer_test_tool {"amount":"1","to":"0xabc"}`) {
+		t.Fatalf("response = %q, want the would-be tool call listed", response)
+	}
+
+	route, agent, err := al.resolveMessageRoute(msg)
+	if err != nil {
+		t.Fatalf("resolveMessageRoute() error = %v", err)
+	}
+	sessionKey := resolveScopeKey(route, msg.SessionKey)
+	if history := agent.Sessions.GetHistory(sessionKey); len(history) != 0 {
+		t.Fatalf("dry run left %d messages in the session, want 0", len(history))
+	}
+}
+
+func TestProcessMessage_DryRunRefusesCommands(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &simpleMockProvider{response: "unexpected"})
+	msg := bus.InboundMessage{
+		Channel:  "pico",
+		SenderID: "pico-user",
+		ChatID:   "pico:session-1",
+		Content:  "/clear",
+		Peer:     bus.Peer{Kind: "direct", ID: "pico:session-1"},
+		Metadata: map[string]string{"dry_run": "true"},
+	}
+	route, agent, err := al.resolveMessageRoute(msg)
+	if err != nil {
+		t.Fatalf("resolveMessageRoute() error = %v", err)
+	}
+	sessionKey := resolveScopeKey(route, msg.SessionKey)
+	agent.Sessions.AddMessage(sessionKey, "user", "keep me")
+
+	response, err := al.processMessage(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if !strings.Contains(response, "[dry run] /clear was not run") {
+		t.Fatalf("response = %q, want the command refused", response)
+	}
+	if history := agent.Sessions.GetHistory(sessionKey); len(history) != 1 {
+		t.Fatalf("session has %d messages after a dry-run /clear, want 1", len(history))
+	}
+}
+
 // TestToolResult_SilentToolDoesNotSendUserMessage verifies silent tools don't trigger outbound
 func TestToolResult_SilentToolDoesNotSendUserMessage(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "agent-test-*")
@@ -1047,6 +1618,70 @@ func (m *failFirstMockProvider) GetDefaultModel() string {
 	return "mock-fail-model"
 }
 
+func TestRetryCommand_RerunsLastMessageAfterFailure(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	provider := &failFirstMockProvider{
+		failures:    1,
+		failError:   errors.New("invalid request: malformed payload"),
+		successResp: "second time lucky",
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	agent := al.registry.GetDefaultAgent()
+
+	const sessionKey = "retry-session"
+	run := func(content string) (string, error) {
+		return al.ProcessDirectWithChannel(context.Background(), content, sessionKey, "test", "chat-1")
+	}
+
+	if _, err := run("summarise my inbox"); err == nil {
+		t.Fatal("expected the first attempt to fail")
+	}
+
+	reply, err := run("/retry")
+	if err != nil {
+		t.Fatalf("/retry error = %v", err)
+	}
+	if reply != "second time lucky" {
+		t.Fatalf("/retry reply = %q, want the agent's answer", reply)
+	}
+	if provider.currentCall != 2 {
+		t.Fatalf("provider called %d times, want 2", provider.currentCall)
+	}
+
+	// The failed attempt's user message was replaced, not duplicated.
+	var users []string
+	for _, m := range agent.Sessions.GetHistory(routing.BuildAgentMainSessionKey(agent.ID)) {
+		if m.Role == "user" {
+			users = append(users, m.Content)
+		}
+	}
+	if len(users) != 1 || users[0] != "summarise my inbox" {
+		t.Fatalf("user messages in session = %q, want the retried message once", users)
+	}
+}
+
+func TestRetryCommand_NothingToRetry(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	reply, err := al.ProcessDirectWithChannel(context.Background(), "/retry", "fresh-session", "test", "chat-1")
+	if err != nil {
+		t.Fatalf("/retry error = %v", err)
+	}
+	if !strings.Contains(reply, "Nothing to retry") {
+		t.Fatalf("/retry reply = %q, want a polite nothing-to-retry message", reply)
+	}
+}
+
 // TestAgentLoop_ContextExhaustionRetry verify that the agent retries on context errors
 func TestAgentLoop_ContextExhaustionRetry(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "agent-test-*")
@@ -1187,8 +1822,8 @@ func TestAgentLoop_ToolLimitUsesDedicatedFallback(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
 	}
-	if response != toolLimitResponse {
-		t.Fatalf("response = %q, want %q", response, toolLimitResponse)
+	if response != toolLimitResponse(1) {
+		t.Fatalf("response = %q, want %q", response, toolLimitResponse(1))
 	}
 
 	defaultAgent := al.registry.GetDefaultAgent()
@@ -1207,8 +1842,63 @@ func TestAgentLoop_ToolLimitUsesDedicatedFallback(t *testing.T) {
 		t.Fatalf("history len = %d, want 4", len(history))
 	}
 	assertRoles(t, history, "user", "assistant", "tool", "assistant")
-	if history[3].Content != toolLimitResponse {
-		t.Fatalf("final assistant content = %q, want %q", history[3].Content, toolLimitResponse)
+	if history[3].Content != toolLimitResponse(1) {
+		t.Fatalf("final assistant content = %q, want %q", history[3].Content, toolLimitResponse(1))
+	}
+}
+
+// countingToolLimitProvider always requests a tool call and counts requests.
+type countingToolLimitProvider struct {
+	toolLimitOnlyProvider
+	calls int
+}
+
+func (m *countingToolLimitProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.calls++
+	return m.toolLimitOnlyProvider.Chat(ctx, messages, tools, model, opts)
+}
+
+func TestAgentLoop_ToolLimitHonorsPerAgentOverride(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "agent-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+			List: []config.AgentConfig{
+				{ID: "main", Default: true, MaxToolIterations: 3},
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	provider := &countingToolLimitProvider{}
+	al := NewAgentLoop(cfg, msgBus, provider)
+	al.RegisterTool(&toolLimitTestTool{})
+
+	response, err := al.ProcessDirectWithChannel(context.Background(), "hello", "tool-limit", "test", "chat1")
+	if err != nil {
+		t.Fatalf("ProcessDirectWithChannel failed: %v", err)
+	}
+	if provider.calls != 3 {
+		t.Fatalf("provider calls = %d, want 3", provider.calls)
+	}
+	if !strings.Contains(response, "Stopped after 3 tool iterations") {
+		t.Fatalf("response = %q, want stop message for 3 iterations", response)
 	}
 }
 
@@ -1794,6 +2484,93 @@ func TestResolveMediaRefs_MixedImageAndFile(t *testing.T) {
 	}
 }
 
+func newVisionTestLoop(t *testing.T, vision *bool) (*AgentLoop, *recordingProvider, string) {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		ModelList: []config.ModelConfig{
+			{ModelName: "test-model", Model: "openai/test-model", APIKey: "test", Vision: vision},
+		},
+	}
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	store := media.NewFileMediaStore()
+	al.SetMediaStore(store)
+	pngPath := filepath.Join(t.TempDir(), "photo.png")
+	pngHeader := []byte{
+		0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A,
+		0x00, 0x00, 0x00, 0x0D, 0x49, 0x48, 0x44, 0x52,
+		0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x08, 0x02,
+		0x00, 0x00, 0x00, 0x90, 0x77, 0x53, 0xDE,
+	}
+	if err := os.WriteFile(pngPath, pngHeader, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := store.Store(pngPath, media.MediaMeta{}, "telegram:chat-1:msg-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return al, provider, ref
+}
+
+func TestProcessMessage_InboundImageReachesProviderAsImagePart(t *testing.T) {
+	al, provider, ref := newVisionTestLoop(t, nil)
+
+	_, err := al.processMessage(context.Background(), bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:42",
+		ChatID:   "chat-1",
+		Content:  "what is this? [image: photo]",
+		Media:    []string{ref},
+	})
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+
+	last := provider.lastMessages[len(provider.lastMessages)-1]
+	if len(last.Media) != 1 || !strings.HasPrefix(last.Media[0], "data:image/png;base64,") {
+		t.Fatalf("user message media = %v, want one PNG data URL", last.Media)
+	}
+
+	// The OpenAI-compatible wire format turns it into an image_url part.
+	wire, _ := json.Marshal(common.SerializeMessages([]providers.Message{last}))
+	if !strings.Contains(string(wire), `"type":"image_url"`) {
+		t.Errorf("serialized request has no image_url part: %s", wire)
+	}
+}
+
+func TestProcessMessage_TextOnlyModelDropsImages(t *testing.T) {
+	vision := false
+	al, provider, ref := newVisionTestLoop(t, &vision)
+
+	_, err := al.processMessage(context.Background(), bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "telegram:42",
+		ChatID:   "chat-1",
+		Content:  "what is this?",
+		Media:    []string{ref},
+	})
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+
+	last := provider.lastMessages[len(provider.lastMessages)-1]
+	if len(last.Media) != 0 {
+		t.Fatalf("user message media = %v, want none for a text-only model", last.Media)
+	}
+	if !strings.Contains(last.Content, "[image omitted") {
+		t.Errorf("content = %q, want a note that the image was omitted", last.Content)
+	}
+}
+
 // --- Native search helper tests ---
 
 type nativeSearchProvider struct {
@@ -1874,3 +2651,35 @@ func TestFilterClientWebSearch_EmptyInput(t *testing.T) {
 		t.Fatalf("len(result) = %d, want 0", len(result))
 	}
 }
+
+func TestMessageTool_PublishesWithTurnTraceID(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	cfg.Tools.Message.Enabled = true
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, &mockProvider{})
+
+	agent := al.GetRegistry().GetDefaultAgent()
+	tool, ok := agent.Tools.Get("message")
+	if !ok {
+		t.Fatal("message tool not registered")
+	}
+
+	ctx := tools.WithToolContext(bus.WithTraceID(context.Background(), "trace-618"), "telegram", "chat-1")
+	if result := tool.Execute(ctx, map[string]any{"content": "hello"}); result.IsError {
+		t.Fatalf("Execute() error = %s", result.ForLLM)
+	}
+
+	out := <-msgBus.OutboundChan()
+	if out.TraceID != "trace-618" {
+		t.Errorf("TraceID = %q, want the turn's trace ID", out.TraceID)
+	}
+}
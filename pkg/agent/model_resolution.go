@@ -3,6 +3,7 @@ package agent
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/providers"
@@ -64,6 +65,23 @@ func resolveModelCandidates(
 	)
 }
 
+// candidateRequestTimeout returns the request_timeout of the model_list entry
+// a fallback candidate resolved from, or 0 when no entry matches or it sets
+// none.
+func candidateRequestTimeout(cfg *config.Config, candidate providers.FallbackCandidate) time.Duration {
+	if cfg == nil {
+		return 0
+	}
+	key := providers.ModelKey(candidate.Provider, candidate.Model)
+	for i := range cfg.ModelList {
+		ref := providers.ParseModelRef(cfg.ModelList[i].Model, "openai")
+		if ref != nil && providers.ModelKey(ref.Provider, ref.Model) == key {
+			return time.Duration(cfg.ModelList[i].RequestTimeout) * time.Second
+		}
+	}
+	return 0
+}
+
 func resolvedCandidateModel(candidates []providers.FallbackCandidate, fallback string) string {
 	if len(candidates) > 0 && strings.TrimSpace(candidates[0].Model) != "" {
 		return candidates[0].Model
@@ -2,10 +2,13 @@ package agent
 
 import (
 	"context"
+	"strings"
 	"testing"
 
+	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
 type mockRegistryProvider struct{}
@@ -203,3 +206,53 @@ func TestAgentInstance_FallbackExplicitEmpty(t *testing.T) {
 		t.Errorf("expected 0 fallbacks (explicit empty), got %d: %v", len(agent.Fallbacks), agent.Fallbacks)
 	}
 }
+
+func TestAgentLoop_SpawnToolEnforcesAllowAgents(t *testing.T) {
+	cfg := testCfg([]config.AgentConfig{
+		{
+			ID:      "parent",
+			Default: true,
+			Subagents: &config.SubagentsConfig{
+				AllowAgents: []string{"helper"},
+			},
+		},
+		{ID: "helper"},
+		{ID: "other"},
+	})
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+	cfg.Tools.Spawn.Enabled = true
+	cfg.Tools.Subagent.Enabled = true
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockRegistryProvider{})
+	spawnFor := func(agentID string) tools.Tool {
+		t.Helper()
+		agent, ok := al.GetRegistry().GetAgent(agentID)
+		if !ok {
+			t.Fatalf("agent %q not registered", agentID)
+		}
+		tool, ok := agent.Tools.Get("spawn")
+		if !ok {
+			t.Fatalf("agent %q has no spawn tool", agentID)
+		}
+		return tool
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	denied := spawnFor("parent").Execute(ctx, map[string]any{"task": "summarize", "agent_id": "other"})
+	if !denied.IsError || !strings.Contains(denied.ForLLM, "not allowed to spawn agent 'other'") {
+		t.Errorf("spawning a target not in allow_agents = %+v, want not-allowed error", denied)
+	}
+
+	allowed := spawnFor("parent").Execute(ctx, map[string]any{"task": "summarize", "agent_id": "helper"})
+	if allowed.IsError {
+		t.Errorf("spawning an allowed target failed: %s", allowed.ForLLM)
+	}
+
+	// Agents without a subagents config may not target other agents.
+	fromHelper := spawnFor("helper").Execute(ctx, map[string]any{"task": "summarize", "agent_id": "parent"})
+	if !fromHelper.IsError {
+		t.Error("agent without allow_agents should not be able to spawn other agents")
+	}
+}
@@ -0,0 +1,279 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// activeTurns tracks the turn in progress for each chat so that /cancel can
+// interrupt it.
+type activeTurns struct {
+	mu    sync.Mutex
+	turns map[string]*activeTurn
+}
+
+type activeTurn struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+func turnKey(channel, chatID string) string {
+	return channel + ":" + chatID
+}
+
+// begin registers a turn for the chat and returns its context. The returned
+// end func must be called when the turn finishes; it reports whether the
+// turn was cancelled by cancel.
+func (t *activeTurns) begin(ctx context.Context, channel, chatID string) (context.Context, func() bool) {
+	turnCtx, cancel := context.WithCancel(ctx)
+	turn := &activeTurn{cancel: cancel}
+	key := turnKey(channel, chatID)
+
+	t.mu.Lock()
+	if t.turns == nil {
+		t.turns = make(map[string]*activeTurn)
+	}
+	t.turns[key] = turn
+	t.mu.Unlock()
+
+	return turnCtx, func() bool {
+		cancel()
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.turns[key] == turn {
+			delete(t.turns, key)
+		}
+		return turn.cancelled
+	}
+}
+
+// cancel cancels the turn in progress for the chat. It returns false when
+// there is none.
+func (t *activeTurns) cancel(channel, chatID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	turn, ok := t.turns[turnKey(channel, chatID)]
+	if !ok || turn.cancelled {
+		return false
+	}
+	turn.cancelled = true
+	turn.cancel()
+	return true
+}
+
+// busyReply answers messages shed because the turn queue is full.
+const busyReply = "I'm busy with other requests right now. Please try again in a moment."
+
+// ErrTurnQueueFull is returned by ProcessInbound when the message is shed
+// because the turn queue is full.
+var ErrTurnQueueFull = errors.New("agent is busy: turn queue is full")
+
+// errTurnCancelled is returned by ProcessInbound when /cancel interrupted
+// the turn.
+var errTurnCancelled = errors.New("turn cancelled")
+
+// queuedTurn is a message waiting for the turn pipeline. In-band turns,
+// submitted through ProcessInbound, carry the caller's context and a reply
+// channel; their result goes back to the caller instead of being published
+// to the message's channel.
+type queuedTurn struct {
+	msg   bus.InboundMessage
+	ctx   context.Context
+	reply chan<- turnResult
+}
+
+// turnResult is the outcome of an in-band turn.
+type turnResult struct {
+	content string
+	err     error
+}
+
+func (t queuedTurn) inBand() bool {
+	return t.reply != nil
+}
+
+// respond delivers the result of an in-band turn. It never blocks: reply is
+// buffered for exactly one result.
+func (t queuedTurn) respond(content string, err error) {
+	t.reply <- turnResult{content: content, err: err}
+}
+
+type inBandKey struct{}
+
+// withInBand marks ctx as belonging to an in-band turn.
+func withInBand(ctx context.Context) context.Context {
+	return context.WithValue(ctx, inBandKey{}, true)
+}
+
+// isInBand reports whether ctx belongs to an in-band turn.
+func isInBand(ctx context.Context) bool {
+	v, _ := ctx.Value(inBandKey{}).(bool)
+	return v
+}
+
+// turnQueue holds the turns waiting for the turn in progress, in arrival
+// order, and counts them per channel so both limits can be enforced.
+type turnQueue struct {
+	turns      []queuedTurn
+	perChannel map[string]int
+}
+
+// push queues turn unless that would exceed maxTotal queued turns or
+// maxPerChannel for its channel (0 = unlimited). It reports whether turn
+// was queued.
+func (q *turnQueue) push(turn queuedTurn, maxTotal, maxPerChannel int) bool {
+	channel := turn.msg.Channel
+	if maxTotal > 0 && len(q.turns) >= maxTotal {
+		return false
+	}
+	if maxPerChannel > 0 && q.perChannel[channel] >= maxPerChannel {
+		return false
+	}
+	if q.perChannel == nil {
+		q.perChannel = make(map[string]int)
+	}
+	q.turns = append(q.turns, turn)
+	q.perChannel[channel]++
+	return true
+}
+
+// pop removes the oldest queued turn.
+func (q *turnQueue) pop() (queuedTurn, bool) {
+	if len(q.turns) == 0 {
+		return queuedTurn{}, false
+	}
+	turn := q.turns[0]
+	q.turns = q.turns[1:]
+	channel := turn.msg.Channel
+	if q.perChannel[channel]--; q.perChannel[channel] <= 0 {
+		delete(q.perChannel, channel)
+	}
+	return turn, true
+}
+
+// enqueueTurn queues turn behind the turn in progress, or sheds it with a
+// busy reply (ErrTurnQueueFull for in-band turns) when the configured queue
+// limits are reached.
+func (al *AgentLoop) enqueueTurn(ctx context.Context, q *turnQueue, turn queuedTurn) {
+	defaults := al.GetConfig().Agents.Defaults
+	if q.push(turn, defaults.MaxQueuedTurns, defaults.MaxQueuedTurnsPerChannel) {
+		return
+	}
+
+	msg := turn.msg
+	logger.WarnCF("agent", "Turn queue full, shedding message", map[string]any{
+		"channel":  msg.Channel,
+		"chat_id":  msg.ChatID,
+		"queued":   len(q.turns),
+		"trace_id": msg.TraceID,
+	})
+	if turn.inBand() {
+		turn.respond("", ErrTurnQueueFull)
+		return
+	}
+	if constants.IsInternalChannel(msg.Channel) {
+		return
+	}
+	al.bus.PublishOutbound(ctx, bus.OutboundMessage{
+		Channel: msg.Channel,
+		ChatID:  msg.ChatID,
+		Content: busyReply,
+		TraceID: msg.TraceID,
+		Final:   true,
+	})
+}
+
+// handleCancelCommand runs /cancel outside the turn pipeline, which is busy
+// with the turn it has to interrupt. It returns false for any other message,
+// and for a dry-run /cancel, which processMessage refuses.
+func (al *AgentLoop) handleCancelCommand(ctx context.Context, turn queuedTurn) bool {
+	msg := turn.msg
+	if name, ok := al.registeredCommand(msg.Content); !ok || name != "cancel" {
+		return false
+	}
+	if inboundMetadata(msg, metadataKeyDryRun) == "true" {
+		return false
+	}
+
+	rt := &commands.Runtime{
+		Config: al.GetConfig(),
+		CancelTurn: func() bool {
+			return al.turns.cancel(msg.Channel, msg.ChatID)
+		},
+	}
+	var reply string
+	commands.NewExecutor(al.cmdRegistry, rt).Execute(ctx, commands.Request{
+		Channel:  msg.Channel,
+		ChatID:   msg.ChatID,
+		SenderID: msg.SenderID,
+		Text:     msg.Content,
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	})
+	if turn.inBand() {
+		turn.respond(reply, nil)
+	} else if reply != "" {
+		al.bus.PublishOutbound(ctx, bus.OutboundMessage{
+			Channel: msg.Channel,
+			ChatID:  msg.ChatID,
+			Content: reply,
+			TraceID: msg.TraceID,
+			Final:   true,
+		})
+	}
+	return true
+}
+
+// lastTurn is the last message a session sent to the agent, kept so /retry
+// can run it again.
+type lastTurn struct {
+	opts       processOptions
+	historyLen int // session history length before the message was added
+}
+
+// rememberTurn records opts as the last turn of its session.
+func (al *AgentLoop) rememberTurn(agent *AgentInstance, opts processOptions) {
+	al.lastTurns.Store(opts.SessionKey, lastTurn{
+		opts:       opts,
+		historyLen: len(agent.Sessions.GetHistory(opts.SessionKey)),
+	})
+}
+
+// retryLastTurn re-runs the session's last message with agent's current
+// model. Whatever the previous attempt left in the session (the user message,
+// a partial or complete reply) is removed first so the message is not
+// duplicated. found is false when the session has no message to retry.
+func (al *AgentLoop) retryLastTurn(
+	ctx context.Context,
+	agent *AgentInstance,
+	sessionKey string,
+) (reply string, found bool, err error) {
+	v, ok := al.lastTurns.Load(sessionKey)
+	if !ok {
+		return "", false, nil
+	}
+	last := v.(lastTurn)
+
+	history := agent.Sessions.GetHistory(sessionKey)
+	if n := last.historyLen; n < len(history) &&
+		history[n].Role == "user" && history[n].Content == last.opts.UserMessage {
+		agent.Sessions.SetHistory(sessionKey, history[:n])
+		agent.Sessions.Save(sessionKey)
+	}
+
+	logger.InfoCF("agent", "Retrying last message", map[string]any{
+		"agent_id":    agent.ID,
+		"session_key": sessionKey,
+		"model":       agent.Model,
+	})
+	reply, err = al.runAgentLoop(ctx, agent, last.opts)
+	return reply, true, err
+}
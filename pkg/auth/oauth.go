@@ -2,6 +2,7 @@ package auth
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
@@ -28,6 +29,9 @@ type OAuthProviderConfig struct {
 	Scopes       string
 	Originator   string
 	Port         int
+	// JSONTokenRequest sends token requests as a JSON body instead of a
+	// form, as Anthropic's token endpoint expects.
+	JSONTokenRequest bool
 }
 
 func OpenAIOAuthConfig() OAuthProviderConfig {
@@ -40,6 +44,18 @@ func OpenAIOAuthConfig() OAuthProviderConfig {
 	}
 }
 
+// AnthropicOAuthConfig returns the OAuth configuration used to refresh
+// Claude subscription credentials stored under "anthropic".
+func AnthropicOAuthConfig() OAuthProviderConfig {
+	return OAuthProviderConfig{
+		Issuer:           "https://claude.ai",
+		TokenURL:         "https://console.anthropic.com/v1/oauth/token",
+		ClientID:         "9d1c250a-e61b-44d9-88ed-5944d1962f5e",
+		Scopes:           "org:create_api_key user:profile user:inference",
+		JSONTokenRequest: true,
+	}
+}
+
 // GoogleAntigravityOAuthConfig returns the OAuth configuration for Google Cloud Code Assist (Antigravity).
 // Client credentials are the same ones used by OpenCode/pi-ai for Cloud Code Assist access.
 func GoogleAntigravityOAuthConfig() OAuthProviderConfig {
@@ -404,7 +420,18 @@ func RefreshAccessToken(cred *AuthCredential, cfg OAuthProviderConfig) (*AuthCre
 		tokenURL = cfg.TokenURL
 	}
 
-	resp, err := http.PostForm(tokenURL, data)
+	var resp *http.Response
+	var err error
+	if cfg.JSONTokenRequest {
+		body, _ := json.Marshal(map[string]string{
+			"grant_type":    "refresh_token",
+			"refresh_token": cred.RefreshToken,
+			"client_id":     cfg.ClientID,
+		})
+		resp, err = http.Post(tokenURL, "application/json", bytes.NewReader(body))
+	} else {
+		resp, err = http.PostForm(tokenURL, data)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("refreshing token: %w", err)
 	}
@@ -276,6 +276,42 @@ func TestRefreshAccessToken(t *testing.T) {
 	}
 }
 
+func TestRefreshAccessTokenJSONRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			http.Error(w, "content type "+ct, http.StatusBadRequest)
+			return
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil ||
+			body["grant_type"] != "refresh_token" || body["refresh_token"] != "old-refresh-token" ||
+			body["client_id"] != "test-client" {
+			http.Error(w, "bad body", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "claude-access", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	cfg := AnthropicOAuthConfig()
+	cfg.TokenURL = server.URL
+	cfg.ClientID = "test-client"
+	cred := &AuthCredential{
+		AccessToken:  "old-token",
+		RefreshToken: "old-refresh-token",
+		Provider:     "anthropic",
+		AuthMethod:   "oauth",
+	}
+
+	refreshed, err := RefreshAccessToken(cred, cfg)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() error: %v", err)
+	}
+	if refreshed.AccessToken != "claude-access" || refreshed.RefreshToken != "old-refresh-token" {
+		t.Errorf("refreshed = %+v, want the new access token and the old refresh token", refreshed)
+	}
+}
+
 func TestRefreshAccessTokenNoRefreshToken(t *testing.T) {
 	cfg := OpenAIOAuthConfig()
 	cred := &AuthCredential{
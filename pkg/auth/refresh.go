@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// refreshLeadTime is how long before expiry a credential is considered due
+// for refresh, both by NeedsRefresh and by the background refresher.
+const refreshLeadTime = 5 * time.Minute
+
+// refreshRetryInterval is how long the background refresher waits after a
+// failed refresh, or when there is no refreshable credential yet.
+var refreshRetryInterval = time.Minute
+
+// refreshAccessToken is swapped out in tests.
+var refreshAccessToken = RefreshAccessToken
+
+// refreshMu serializes refreshes so the background refresher and a token
+// source never spend the same refresh token twice.
+var refreshMu sync.Mutex
+
+// OAuthConfigForProvider returns the OAuth client configuration used to
+// refresh credentials stored under provider, if that provider supports
+// refresh.
+func OAuthConfigForProvider(provider string) (OAuthProviderConfig, bool) {
+	switch provider {
+	case "openai":
+		return OpenAIOAuthConfig(), true
+	case "anthropic":
+		return AnthropicOAuthConfig(), true
+	case "google-antigravity":
+		return GoogleAntigravityOAuthConfig(), true
+	default:
+		return OAuthProviderConfig{}, false
+	}
+}
+
+// FreshCredential returns the stored credential for provider, refreshing and
+// saving it first if it is an OAuth credential close to expiry. It returns
+// (nil, nil) when no credential is stored.
+func FreshCredential(provider string) (*AuthCredential, error) {
+	cred, err := GetCredential(provider)
+	if err != nil || cred == nil || !refreshable(cred) || !cred.NeedsRefresh() {
+		return cred, err
+	}
+
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	// Another caller may have refreshed while we waited for the lock.
+	cred, err = GetCredential(provider)
+	if err != nil || cred == nil || !cred.NeedsRefresh() {
+		return cred, err
+	}
+
+	oauthCfg, ok := OAuthConfigForProvider(provider)
+	if !ok {
+		return cred, nil
+	}
+	refreshed, err := refreshAccessToken(cred, oauthCfg)
+	if err != nil {
+		return nil, fmt.Errorf("refreshing token: %w", err)
+	}
+	if err := SetCredential(provider, refreshed); err != nil {
+		return nil, fmt.Errorf("saving refreshed token: %w", err)
+	}
+	return refreshed, nil
+}
+
+func refreshable(cred *AuthCredential) bool {
+	return cred.AuthMethod == "oauth" && cred.RefreshToken != "" && !cred.ExpiresAt.IsZero()
+}
+
+// StartRefresher refreshes the OAuth credential stored under provider ahead of
+// its expiry until ctx is cancelled, so long-running sessions do not hit 401s
+// between requests. Providers without refresh support are ignored.
+func StartRefresher(ctx context.Context, provider string) {
+	if _, ok := OAuthConfigForProvider(provider); !ok {
+		logger.DebugCF("auth", "OAuth refresh not supported for provider",
+			map[string]any{"provider": provider})
+		return
+	}
+	go runRefresher(ctx, provider)
+}
+
+func runRefresher(ctx context.Context, provider string) {
+	// The first check runs immediately; later ones are spaced at least
+	// refreshRetryInterval apart so a failing or very short-lived token
+	// cannot turn this into a busy loop.
+	var minWait time.Duration
+	for {
+		wait := refreshRetryInterval
+		cred, err := GetCredential(provider)
+		if err == nil && cred != nil && refreshable(cred) {
+			wait = max(time.Until(cred.ExpiresAt.Add(-refreshLeadTime)), minWait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		minWait = refreshRetryInterval
+
+		if _, err := FreshCredential(provider); err != nil {
+			logger.WarnCF("auth", "Background OAuth refresh failed",
+				map[string]any{"provider": provider, "error": err.Error()})
+		}
+	}
+}
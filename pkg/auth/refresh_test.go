@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// withFakeRefresh isolates the auth store and replaces the token endpoint
+// with fn for the duration of the test.
+func withFakeRefresh(t *testing.T, fn func(*AuthCredential, OAuthProviderConfig) (*AuthCredential, error)) {
+	t.Helper()
+	t.Setenv("PICOCLAW_HOME", t.TempDir())
+	prevRefresh, prevRetry := refreshAccessToken, refreshRetryInterval
+	refreshAccessToken = fn
+	refreshRetryInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		refreshAccessToken = prevRefresh
+		refreshRetryInterval = prevRetry
+	})
+}
+
+func expiringCredential(token string, expiresIn time.Duration) *AuthCredential {
+	return &AuthCredential{
+		AccessToken:  token,
+		RefreshToken: "refresh-" + token,
+		ExpiresAt:    time.Now().Add(expiresIn),
+		Provider:     "openai",
+		AuthMethod:   "oauth",
+	}
+}
+
+func TestFreshCredential_RefreshesExpiringToken(t *testing.T) {
+	var calls atomic.Int32
+	withFakeRefresh(t, func(cred *AuthCredential, _ OAuthProviderConfig) (*AuthCredential, error) {
+		calls.Add(1)
+		if cred.RefreshToken != "refresh-old" {
+			t.Errorf("refresh token = %q, want refresh-old", cred.RefreshToken)
+		}
+		return expiringCredential("new", time.Hour), nil
+	})
+	if err := SetCredential("openai", expiringCredential("old", time.Minute)); err != nil {
+		t.Fatalf("SetCredential() error = %v", err)
+	}
+
+	cred, err := FreshCredential("openai")
+	if err != nil {
+		t.Fatalf("FreshCredential() error = %v", err)
+	}
+	if cred.AccessToken != "new" {
+		t.Errorf("AccessToken = %q, want new", cred.AccessToken)
+	}
+	stored, _ := GetCredential("openai")
+	if stored.AccessToken != "new" {
+		t.Errorf("stored AccessToken = %q, want new", stored.AccessToken)
+	}
+
+	// The refreshed token is valid for an hour, so no further refresh.
+	if _, err := FreshCredential("openai"); err != nil {
+		t.Fatalf("FreshCredential() error = %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("refresh calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestFreshCredential_SkipsNonOAuthAndUnsupported(t *testing.T) {
+	withFakeRefresh(t, func(*AuthCredential, OAuthProviderConfig) (*AuthCredential, error) {
+		t.Error("refresh must not be called")
+		return nil, errors.New("unexpected")
+	})
+
+	token := expiringCredential("pasted", time.Minute)
+	token.AuthMethod = "token"
+	SetCredential("openai", token)
+	SetCredential("github-copilot", expiringCredential("copilot", time.Minute))
+
+	for _, provider := range []string{"openai", "github-copilot", "missing"} {
+		if _, err := FreshCredential(provider); err != nil {
+			t.Errorf("FreshCredential(%q) error = %v", provider, err)
+		}
+	}
+}
+
+func TestStartRefresher_RefreshesAheadOfExpiry(t *testing.T) {
+	refreshed := make(chan struct{}, 1)
+	withFakeRefresh(t, func(*AuthCredential, OAuthProviderConfig) (*AuthCredential, error) {
+		select {
+		case refreshed <- struct{}{}:
+		default:
+		}
+		return expiringCredential("renewed", time.Hour), nil
+	})
+	// Expires in 2 minutes: inside the refresh lead time, so due now.
+	SetCredential("openai", expiringCredential("stale", 2*time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	StartRefresher(ctx, "openai")
+
+	select {
+	case <-refreshed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("background refresher did not refresh the expiring credential")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		cred, _ := GetCredential("openai")
+		if cred != nil && cred.AccessToken == "renewed" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stored credential = %+v, want renewed token", cred)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFreshCredential_RefreshesAnthropic(t *testing.T) {
+	withFakeRefresh(t, func(cred *AuthCredential, cfg OAuthProviderConfig) (*AuthCredential, error) {
+		if cfg.ClientID != AnthropicOAuthConfig().ClientID {
+			t.Errorf("refresh used client %q, want the Anthropic OAuth client", cfg.ClientID)
+		}
+		refreshed := expiringCredential("claude-new", time.Hour)
+		refreshed.Provider = "anthropic"
+		return refreshed, nil
+	})
+	stale := expiringCredential("claude-old", time.Minute)
+	stale.Provider = "anthropic"
+	SetCredential("anthropic", stale)
+
+	cred, err := FreshCredential("anthropic")
+	if err != nil {
+		t.Fatalf("FreshCredential() error = %v", err)
+	}
+	if cred.AccessToken != "claude-new" {
+		t.Errorf("AccessToken = %q, want claude-new", cred.AccessToken)
+	}
+}
@@ -36,7 +36,7 @@ func (c *AuthCredential) NeedsRefresh() bool {
 	if c.ExpiresAt.IsZero() {
 		return false
 	}
-	return time.Now().Add(5 * time.Minute).After(c.ExpiresAt)
+	return time.Now().Add(refreshLeadTime).After(c.ExpiresAt)
 }
 
 func authFilePath() string {
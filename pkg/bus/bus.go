@@ -7,13 +7,38 @@ import (
 	"sync/atomic"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/metrics"
 )
 
-// ErrBusClosed is returned when publishing to a closed MessageBus.
-var ErrBusClosed = errors.New("message bus closed")
+var (
+	// ErrBusClosed is returned when publishing to a closed MessageBus.
+	ErrBusClosed = errors.New("message bus closed")
+	// ErrBusFull is returned by the reject policy when a queue is full.
+	ErrBusFull = errors.New("message bus queue full")
+)
 
 const defaultBusBufferSize = 64
 
+// BackpressurePolicy decides what PublishInbound does when the inbound queue
+// is full. Outbound queues always block: replies are never shed.
+type BackpressurePolicy string
+
+const (
+	// PolicyBlock waits for room in the queue (or for ctx/Close). Default.
+	PolicyBlock BackpressurePolicy = "block"
+	// PolicyDropOldest discards the oldest queued message to make room.
+	PolicyDropOldest BackpressurePolicy = "drop_oldest"
+	// PolicyReject fails the publish with ErrBusFull.
+	PolicyReject BackpressurePolicy = "reject"
+)
+
+// Options configures a MessageBus. Zero values select the defaults: a
+// buffer of 64 messages per queue and the block policy for inbound.
+type Options struct {
+	BufferSize int
+	Policy     BackpressurePolicy
+}
+
 // StreamDelegate is implemented by the channel Manager to provide streaming
 // capabilities to the agent loop without tight coupling.
 type StreamDelegate interface {
@@ -34,24 +59,67 @@ type MessageBus struct {
 	inbound       chan InboundMessage
 	outbound      chan OutboundMessage
 	outboundMedia chan OutboundMediaMessage
+	policy        BackpressurePolicy // inbound only
 
 	closeOnce      sync.Once
 	done           chan struct{}
 	closed         atomic.Bool
 	wg             sync.WaitGroup
 	streamDelegate atomic.Value // stores StreamDelegate
+	removeMetrics  []func()
 }
 
 func NewMessageBus() *MessageBus {
-	return &MessageBus{
-		inbound:       make(chan InboundMessage, defaultBusBufferSize),
-		outbound:      make(chan OutboundMessage, defaultBusBufferSize),
-		outboundMedia: make(chan OutboundMediaMessage, defaultBusBufferSize),
+	return NewMessageBusWithOptions(Options{})
+}
+
+// NewMessageBusWithOptions creates a MessageBus with the given buffer size
+// and inbound backpressure policy. An unknown policy falls back to block.
+func NewMessageBusWithOptions(opts Options) *MessageBus {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = defaultBusBufferSize
+	}
+	policy := opts.Policy
+	switch policy {
+	case PolicyBlock, PolicyDropOldest, PolicyReject:
+	case "":
+		policy = PolicyBlock
+	default:
+		logger.WarnCF("bus", "Unknown backpressure policy, using block", map[string]any{
+			"policy": string(policy),
+		})
+		policy = PolicyBlock
+	}
+	mb := &MessageBus{
+		inbound:       make(chan InboundMessage, size),
+		outbound:      make(chan OutboundMessage, size),
+		outboundMedia: make(chan OutboundMediaMessage, size),
+		policy:        policy,
 		done:          make(chan struct{}),
 	}
+	// Queue depth is read at scrape time: consumers drain the channels
+	// directly, so sampling on publish alone would leave the gauge stale.
+	mb.removeMetrics = []func(){
+		queueDepthMetric("inbound", mb.inbound),
+		queueDepthMetric("outbound", mb.outbound),
+		queueDepthMetric("outbound_media", mb.outboundMedia),
+	}
+	return mb
+}
+
+func queueDepthMetric[T any](queue string, ch chan T) func() {
+	return metrics.BusQueueDepth.SetFunc(func() float64 { return float64(len(ch)) }, queue)
 }
 
-func publish[T any](ctx context.Context, mb *MessageBus, ch chan T, msg T) error {
+func publish[T any](
+	ctx context.Context,
+	mb *MessageBus,
+	queue string,
+	policy BackpressurePolicy,
+	ch chan T,
+	msg T,
+) error {
 	// check bus closed before acquiring wg, to avoid unnecessary wg.Add and potential deadlock
 	if mb.closed.Load() {
 		return ErrBusClosed
@@ -69,6 +137,35 @@ func publish[T any](ctx context.Context, mb *MessageBus, ch chan T, msg T) error
 	mb.wg.Add(1)
 	defer mb.wg.Done()
 
+	switch policy {
+	case PolicyReject:
+		select {
+		case ch <- msg:
+			return nil
+		default:
+			metrics.BusMessagesShed.Inc(queue, string(PolicyReject))
+			return ErrBusFull
+		}
+	case PolicyDropOldest:
+		for {
+			select {
+			case ch <- msg:
+				return nil
+			default:
+			}
+			// The queue is full: discard the oldest message and retry. A
+			// consumer may empty the slot first, in which case nothing is lost.
+			select {
+			case <-ch:
+				metrics.BusMessagesShed.Inc(queue, string(PolicyDropOldest))
+				logger.DebugCF("bus", "Dropped oldest message from full queue", map[string]any{
+					"queue": queue,
+				})
+			default:
+			}
+		}
+	}
+
 	select {
 	case ch <- msg:
 		return nil
@@ -80,15 +177,20 @@ func publish[T any](ctx context.Context, mb *MessageBus, ch chan T, msg T) error
 }
 
 func (mb *MessageBus) PublishInbound(ctx context.Context, msg InboundMessage) error {
-	return publish(ctx, mb, mb.inbound, msg)
+	return publish(ctx, mb, "inbound", mb.policy, mb.inbound, msg)
 }
 
 func (mb *MessageBus) InboundChan() <-chan InboundMessage {
 	return mb.inbound
 }
 
+// PublishOutbound queues msg for delivery. A message without a TraceID
+// inherits the one carried by ctx, if any.
 func (mb *MessageBus) PublishOutbound(ctx context.Context, msg OutboundMessage) error {
-	return publish(ctx, mb, mb.outbound, msg)
+	if msg.TraceID == "" {
+		msg.TraceID = TraceIDFromContext(ctx)
+	}
+	return publish(ctx, mb, "outbound", PolicyBlock, mb.outbound, msg)
 }
 
 func (mb *MessageBus) OutboundChan() <-chan OutboundMessage {
@@ -96,7 +198,7 @@ func (mb *MessageBus) OutboundChan() <-chan OutboundMessage {
 }
 
 func (mb *MessageBus) PublishOutboundMedia(ctx context.Context, msg OutboundMediaMessage) error {
-	return publish(ctx, mb, mb.outboundMedia, msg)
+	return publish(ctx, mb, "outbound_media", PolicyBlock, mb.outboundMedia, msg)
 }
 
 func (mb *MessageBus) OutboundMediaChan() <-chan OutboundMediaMessage {
@@ -121,6 +223,10 @@ func (mb *MessageBus) Close() {
 		// notify all blocked publishers to exit
 		close(mb.done)
 
+		for _, remove := range mb.removeMetrics {
+			remove()
+		}
+
 		// because every publisher will check mb.closed before acquiring wg
 		// so we can be sure that new publishers will not be added new messages after this point
 		mb.closed.Store(true)
@@ -1,10 +1,16 @@
 package bus
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/sipeed/picoclaw/pkg/metrics"
 )
 
 func TestPublishConsume(t *testing.T) {
@@ -61,6 +67,28 @@ func TestPublishOutboundSubscribe(t *testing.T) {
 	}
 }
 
+func TestPublishOutbound_InheritsTraceIDFromContext(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+
+	ctx := WithTraceID(context.Background(), "trace-1")
+
+	if err := mb.PublishOutbound(ctx, OutboundMessage{Channel: "telegram", ChatID: "123"}); err != nil {
+		t.Fatalf("PublishOutbound failed: %v", err)
+	}
+	if got := <-mb.OutboundChan(); got.TraceID != "trace-1" {
+		t.Fatalf("TraceID = %q, want %q", got.TraceID, "trace-1")
+	}
+
+	explicit := OutboundMessage{Channel: "telegram", ChatID: "123", TraceID: "trace-2"}
+	if err := mb.PublishOutbound(ctx, explicit); err != nil {
+		t.Fatalf("PublishOutbound failed: %v", err)
+	}
+	if got := <-mb.OutboundChan(); got.TraceID != "trace-2" {
+		t.Fatalf("TraceID = %q, want explicit %q", got.TraceID, "trace-2")
+	}
+}
+
 func TestPublishInbound_ContextCancel(t *testing.T) {
 	mb := NewMessageBus()
 	defer mb.Close()
@@ -231,6 +259,184 @@ func TestPublishInbound_FullBuffer(t *testing.T) {
 	}
 }
 
+// slowConsume reads n inbound messages, pausing delay before each read, and
+// returns their contents once done.
+func slowConsume(mb *MessageBus, n int, delay time.Duration) <-chan []string {
+	out := make(chan []string, 1)
+	go func() {
+		var got []string
+		for range n {
+			time.Sleep(delay)
+			msg, ok := <-mb.InboundChan()
+			if !ok {
+				break
+			}
+			got = append(got, msg.Content)
+		}
+		out <- got
+	}()
+	return out
+}
+
+func publishN(t *testing.T, mb *MessageBus, n int) []error {
+	t.Helper()
+	errs := make([]error, n)
+	for i := range n {
+		errs[i] = mb.PublishInbound(context.Background(), InboundMessage{Content: fmt.Sprint(i)})
+	}
+	return errs
+}
+
+func TestBackpressure_BlockWaitsForSlowConsumer(t *testing.T) {
+	mb := NewMessageBusWithOptions(Options{BufferSize: 2, Policy: PolicyBlock})
+	defer mb.Close()
+
+	consumed := slowConsume(mb, 5, 20*time.Millisecond)
+
+	start := time.Now()
+	for i, err := range publishN(t, mb, 5) {
+		if err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("publishing returned after %s, want it to wait for the consumer", elapsed)
+	}
+
+	if got := strings.Join(<-consumed, ","); got != "0,1,2,3,4" {
+		t.Errorf("consumed %q, want every message in order", got)
+	}
+}
+
+func TestBackpressure_DropOldestKeepsNewest(t *testing.T) {
+	mb := NewMessageBusWithOptions(Options{BufferSize: 2, Policy: PolicyDropOldest})
+	defer mb.Close()
+
+	// The consumer only starts after the burst, so the queue overflows.
+	for i, err := range publishN(t, mb, 5) {
+		if err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+	consumed := slowConsume(mb, 2, 10*time.Millisecond)
+
+	if got := strings.Join(<-consumed, ","); got != "3,4" {
+		t.Errorf("consumed %q, want the two newest messages", got)
+	}
+
+	var buf bytes.Buffer
+	metrics.WriteText(&buf)
+	if !strings.Contains(buf.String(), `picoclaw_bus_messages_shed_total{queue="inbound",policy="drop_oldest"}`) {
+		t.Error("expected drops to be counted in picoclaw_bus_messages_shed_total")
+	}
+}
+
+func TestQueueDepth_FollowsConsumers(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+	ctx := context.Background()
+
+	depth := func() string {
+		var buf bytes.Buffer
+		metrics.WriteText(&buf)
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if strings.HasPrefix(line, `picoclaw_bus_queue_depth{queue="inbound"}`) {
+				return strings.Fields(line)[1]
+			}
+		}
+		return ""
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := mb.PublishInbound(ctx, InboundMessage{Content: fmt.Sprint(i)}); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+	if got := depth(); got != "3" {
+		t.Fatalf("depth after publish = %q, want 3", got)
+	}
+
+	<-mb.InboundChan()
+	<-mb.InboundChan()
+	if got := depth(); got != "1" {
+		t.Errorf("depth after consume = %q, want 1", got)
+	}
+}
+
+func TestBackpressure_RejectReturnsErrBusFull(t *testing.T) {
+	mb := NewMessageBusWithOptions(Options{BufferSize: 2, Policy: PolicyReject})
+	defer mb.Close()
+
+	errs := publishN(t, mb, 4)
+	for i, err := range errs[:2] {
+		if err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+	for i, err := range errs[2:] {
+		if !errors.Is(err, ErrBusFull) {
+			t.Errorf("publish %d: err = %v, want ErrBusFull", i+2, err)
+		}
+	}
+
+	consumed := slowConsume(mb, 2, 10*time.Millisecond)
+	if got := strings.Join(<-consumed, ","); got != "0,1" {
+		t.Errorf("consumed %q, want the messages accepted before the queue filled", got)
+	}
+
+	// Once the consumer catches up, publishing succeeds again.
+	if err := mb.PublishInbound(context.Background(), InboundMessage{Content: "late"}); err != nil {
+		t.Errorf("publish after drain: %v", err)
+	}
+
+	var buf bytes.Buffer
+	metrics.WriteText(&buf)
+	if !strings.Contains(buf.String(), `picoclaw_bus_queue_depth{queue="inbound"} 1`) {
+		t.Errorf("expected queue depth gauge of 1 after the last publish, got:\n%s", buf.String())
+	}
+}
+
+func TestBackpressure_OutboundAlwaysBlocks(t *testing.T) {
+	mb := NewMessageBusWithOptions(Options{BufferSize: 1, Policy: PolicyReject})
+	defer mb.Close()
+
+	ctx := context.Background()
+	if err := mb.PublishOutbound(ctx, OutboundMessage{Content: "first"}); err != nil {
+		t.Fatalf("first publish: %v", err)
+	}
+
+	published := make(chan error, 1)
+	go func() {
+		published <- mb.PublishOutbound(ctx, OutboundMessage{Content: "second"})
+	}()
+	select {
+	case err := <-published:
+		t.Fatalf("publish to a full outbound queue returned %v, want it to wait", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	for _, want := range []string{"first", "second"} {
+		if got := (<-mb.OutboundChan()).Content; got != want {
+			t.Errorf("consumed %q, want %q", got, want)
+		}
+	}
+	if err := <-published; err != nil {
+		t.Errorf("second publish: %v", err)
+	}
+}
+
+func TestNewMessageBusWithOptions_Defaults(t *testing.T) {
+	mb := NewMessageBusWithOptions(Options{Policy: "bogus"})
+	defer mb.Close()
+
+	if cap(mb.inbound) != defaultBusBufferSize {
+		t.Errorf("buffer = %d, want %d", cap(mb.inbound), defaultBusBufferSize)
+	}
+	if mb.policy != PolicyBlock {
+		t.Errorf("policy = %q, want block for an unknown policy", mb.policy)
+	}
+}
+
 func TestCloseIdempotent(t *testing.T) {
 	mb := NewMessageBus()
 
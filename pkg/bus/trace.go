@@ -0,0 +1,33 @@
+package bus
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type traceIDKey struct{}
+
+// NewTraceID returns a fresh correlation ID for an inbound message.
+func NewTraceID() string {
+	return uuid.NewString()
+}
+
+// WithTraceID returns a context carrying traceID so that logs, provider
+// calls and outbound messages produced while handling one inbound message
+// can be correlated.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	if traceID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID stored by WithTraceID, or "".
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
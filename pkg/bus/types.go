@@ -27,6 +27,7 @@ type InboundMessage struct {
 	MediaScope string            `json:"media_scope,omitempty"` // media lifecycle scope
 	SessionKey string            `json:"session_key"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
+	TraceID    string            `json:"trace_id,omitempty"` // correlation ID assigned on inbound
 }
 
 type OutboundMessage struct {
@@ -34,6 +35,9 @@ type OutboundMessage struct {
 	ChatID           string `json:"chat_id"`
 	Content          string `json:"content"`
 	ReplyToMessageID string `json:"reply_to_message_id,omitempty"`
+	TraceID          string `json:"trace_id,omitempty"`  // trace ID of the inbound message being answered
+	Final            bool   `json:"final,omitempty"`     // the turn's final response, not progress or tool output
+	EditLast         bool   `json:"edit_last,omitempty"` // replace the last message sent to the chat (status updates)
 }
 
 // MediaPart describes a single media attachment to send.
@@ -16,6 +16,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/metrics"
 )
 
 var (
@@ -49,6 +50,8 @@ type Channel interface {
 	Start(ctx context.Context) error
 	Stop(ctx context.Context) error
 	Send(ctx context.Context, msg bus.OutboundMessage) error
+	// IsRunning reports whether the channel is connected. Channels must clear
+	// it once their connection is lost for good so the manager can restart them.
 	IsRunning() bool
 	IsAllowed(senderID string) bool
 	IsAllowedSender(sender bus.SenderInfo) bool
@@ -258,8 +261,17 @@ func (c *BaseChannel) HandleMessage(
 		resolvedSenderID = sender.CanonicalID
 	}
 
+	metrics.MessagesReceived.Inc(c.name)
+
 	scope := BuildMediaScope(c.name, chatID, messageID)
 
+	// Channels that correlate replies with this message pick its trace ID
+	// up front and pass it in ctx (bus.WithTraceID).
+	traceID := bus.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = bus.NewTraceID()
+	}
+
 	msg := bus.InboundMessage{
 		Channel:    c.name,
 		SenderID:   resolvedSenderID,
@@ -271,6 +283,7 @@ func (c *BaseChannel) HandleMessage(
 		MessageID:  messageID,
 		MediaScope: scope,
 		Metadata:   metadata,
+		TraceID:    traceID,
 	}
 
 	// Auto-trigger typing indicator, message reaction, and placeholder before publishing.
@@ -280,14 +293,15 @@ func (c *BaseChannel) HandleMessage(
 	// and the typing stop will still be called. This avoids the problem of compile-time interface
 	// checks incorrectly skipping indicators when streaming may not work at runtime.
 	if c.owner != nil && c.placeholderRecorder != nil {
+		caps := ChannelCapabilities(c.owner)
 		// Typing
-		if tc, ok := c.owner.(TypingCapable); ok {
+		if tc, ok := c.owner.(TypingCapable); ok && caps.Has(SupportsTyping) {
 			if stop, err := tc.StartTyping(ctx, chatID); err == nil {
 				c.placeholderRecorder.RecordTypingStop(c.name, chatID, stop)
 			}
 		}
 		// Reaction
-		if rc, ok := c.owner.(ReactionCapable); ok && messageID != "" {
+		if rc, ok := c.owner.(ReactionCapable); ok && caps.Has(SupportsReactions) && messageID != "" {
 			if undo, err := rc.ReactToMessage(ctx, chatID, messageID); err == nil {
 				c.placeholderRecorder.RecordReactionUndo(c.name, chatID, undo)
 			}
@@ -297,7 +311,7 @@ func (c *BaseChannel) HandleMessage(
 		// placeholder after transcription completes, so the user sees
 		// "Thinking…" only once the voice has been processed.
 		if !audioAnnotationRe.MatchString(content) {
-			if pc, ok := c.owner.(PlaceholderCapable); ok {
+			if pc, ok := c.owner.(PlaceholderCapable); ok && caps.Has(SupportsPlaceholder) {
 				if phID, err := pc.SendPlaceholder(ctx, chatID); err == nil && phID != "" {
 					c.placeholderRecorder.RecordPlaceholder(c.name, chatID, phID)
 				}
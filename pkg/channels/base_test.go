@@ -1,6 +1,7 @@
 package channels
 
 import (
+	"context"
 	"testing"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
@@ -263,3 +264,22 @@ func TestIsAllowedSender(t *testing.T) {
 		})
 	}
 }
+
+func TestBaseChannelHandleMessageAssignsTraceID(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	ch := NewBaseChannel("test", nil, mb, nil)
+
+	peer := bus.Peer{Kind: "direct", ID: "user1"}
+	ch.HandleMessage(context.Background(), peer, "m1", "user1", "chat1", "hello", nil, nil)
+	ch.HandleMessage(context.Background(), peer, "m2", "user1", "chat1", "again", nil, nil)
+
+	first := <-mb.InboundChan()
+	second := <-mb.InboundChan()
+	if first.TraceID == "" || second.TraceID == "" {
+		t.Fatalf("expected trace IDs on inbound messages, got %q and %q", first.TraceID, second.TraceID)
+	}
+	if first.TraceID == second.TraceID {
+		t.Fatalf("expected a distinct trace ID per message, both were %q", first.TraceID)
+	}
+}
@@ -0,0 +1,76 @@
+package channels
+
+// Capabilities is a bitmask of the optional features a channel supports.
+// The dispatch layer consults it before calling typing, reaction, placeholder,
+// streaming or media methods, so platforms that implement an interface but
+// cannot use it in a given deployment can opt out without type-assertion hacks.
+type Capabilities uint32
+
+const (
+	SupportsTyping Capabilities = 1 << iota
+	SupportsReactions
+	SupportsPlaceholder
+	SupportsEditing
+	SupportsDeleting
+	SupportsStreaming
+	SupportsMedia
+	SupportsCommandMenu
+)
+
+// Has reports whether all bits in flag are set.
+func (c Capabilities) Has(flag Capabilities) bool {
+	return c&flag == flag
+}
+
+// CapabilityReporter — channels that advertise their optional features
+// explicitly instead of relying on interface detection. A bare BaseChannel
+// implements none of the optional interfaces and therefore reports nothing;
+// concrete channels implement this to mask features off at runtime (e.g. a
+// platform account without reaction permissions).
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// DetectCapabilities derives a Capabilities set from the optional interfaces
+// that ch implements. It never calls ch.Capabilities.
+func DetectCapabilities(ch any) Capabilities {
+	var caps Capabilities
+	if ch == nil {
+		return caps
+	}
+	if _, ok := ch.(TypingCapable); ok {
+		caps |= SupportsTyping
+	}
+	if _, ok := ch.(ReactionCapable); ok {
+		caps |= SupportsReactions
+	}
+	if _, ok := ch.(PlaceholderCapable); ok {
+		caps |= SupportsPlaceholder
+	}
+	if _, ok := ch.(MessageEditor); ok {
+		caps |= SupportsEditing
+	}
+	if _, ok := ch.(MessageDeleter); ok {
+		caps |= SupportsDeleting
+	}
+	if _, ok := ch.(StreamingCapable); ok {
+		caps |= SupportsStreaming
+	}
+	if _, ok := ch.(MediaSender); ok {
+		caps |= SupportsMedia
+	}
+	if _, ok := ch.(CommandRegistrarCapable); ok {
+		caps |= SupportsCommandMenu
+	}
+	return caps
+}
+
+// ChannelCapabilities returns the features the dispatch layer should use for ch.
+// Channels that report their own capabilities are trusted; anything else falls
+// back to interface detection.
+func ChannelCapabilities(ch Channel) Capabilities {
+	if r, ok := ch.(CapabilityReporter); ok {
+		return r.Capabilities()
+	}
+	return DetectCapabilities(ch)
+}
@@ -0,0 +1,94 @@
+package channels
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// reactingChannel implements ReactionCapable and optionally reports an
+// explicit capability set.
+type reactingChannel struct {
+	mockChannel
+	reactions int
+}
+
+func (c *reactingChannel) ReactToMessage(ctx context.Context, chatID, messageID string) (func(), error) {
+	c.reactions++
+	return func() {}, nil
+}
+
+type reportingChannel struct {
+	reactingChannel
+	caps Capabilities
+}
+
+func (c *reportingChannel) Capabilities() Capabilities { return c.caps }
+
+type nopRecorder struct{}
+
+func (nopRecorder) RecordPlaceholder(channel, chatID, placeholderID string) {}
+func (nopRecorder) RecordTypingStop(channel, chatID string, stop func())    {}
+func (nopRecorder) RecordReactionUndo(channel, chatID string, undo func())  {}
+
+func TestDetectCapabilities(t *testing.T) {
+	bare := NewBaseChannel("bare", nil, nil, nil)
+	if caps := DetectCapabilities(bare); caps != 0 {
+		t.Errorf("bare BaseChannel capabilities = %b, want 0", caps)
+	}
+
+	caps := DetectCapabilities(&reactingChannel{})
+	for _, flag := range []Capabilities{SupportsReactions, SupportsPlaceholder, SupportsEditing} {
+		if !caps.Has(flag) {
+			t.Errorf("expected capability %b in %b", flag, caps)
+		}
+	}
+	if caps.Has(SupportsTyping) || caps.Has(SupportsStreaming) || caps.Has(SupportsMedia) {
+		t.Errorf("unexpected capabilities in %b", caps)
+	}
+}
+
+func TestChannelCapabilities_ReporterOverridesDetection(t *testing.T) {
+	ch := &reportingChannel{caps: SupportsEditing}
+	caps := ChannelCapabilities(ch)
+	if caps.Has(SupportsReactions) {
+		t.Error("reporter masked reactions but ChannelCapabilities still reports them")
+	}
+	if !caps.Has(SupportsEditing) {
+		t.Error("expected SupportsEditing from reporter")
+	}
+}
+
+func TestHandleMessage_ReactionsFollowCapabilities(t *testing.T) {
+	tests := []struct {
+		name          string
+		caps          Capabilities
+		wantReactions int
+	}{
+		{"declares reactions", SupportsReactions, 1},
+		{"does not declare reactions", SupportsTyping, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := bus.NewMessageBus()
+			defer mb.Close()
+
+			ch := &reportingChannel{caps: tt.caps}
+			ch.BaseChannel = *NewBaseChannel("test", nil, mb, nil)
+			ch.SetOwner(ch)
+			ch.SetPlaceholderRecorder(nopRecorder{})
+
+			ch.HandleMessage(context.Background(), bus.Peer{Kind: "direct", ID: "u1"},
+				"msg-1", "u1", "chat-1", "hello", nil, nil)
+
+			if ch.reactions != tt.wantReactions {
+				t.Errorf("reactions = %d, want %d", ch.reactions, tt.wantReactions)
+			}
+			if ch.placeholdersSent != 0 {
+				t.Errorf("placeholder sent without SupportsPlaceholder")
+			}
+		})
+	}
+}
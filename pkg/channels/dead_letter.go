@@ -0,0 +1,96 @@
+package channels
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// deadLetter is one outbound message that could not be delivered, stored as
+// a JSON line in the dead-letter file.
+type deadLetter struct {
+	Time     time.Time       `json:"time"`
+	Channel  string          `json:"channel"`
+	ChatID   string          `json:"chat_id"`
+	Content  string          `json:"content,omitempty"`
+	Parts    []bus.MediaPart `json:"parts,omitempty"`
+	Error    string          `json:"error"`
+	Attempts int             `json:"attempts"`
+	TraceID  string          `json:"trace_id,omitempty"`
+}
+
+// defaultDeadLetterMaxSize is the size at which the dead-letter file is
+// rotated when gateway.delivery.dead_letter_max_size is unset.
+const defaultDeadLetterMaxSize = 10 * 1024 * 1024
+
+// deadLetterLog appends undeliverable messages to a JSONL file, rotating it
+// to "<path>.1" once it reaches maxSize. A nil *deadLetterLog only logs them.
+type deadLetterLog struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+}
+
+func newDeadLetterLog(path string, maxSize int64) *deadLetterLog {
+	if maxSize <= 0 {
+		maxSize = defaultDeadLetterMaxSize
+	}
+	return &deadLetterLog{path: path, maxSize: maxSize}
+}
+
+// add records entry, logging it at error level even if the file cannot be
+// written.
+func (d *deadLetterLog) add(entry deadLetter) {
+	fields := map[string]any{
+		"channel":  entry.Channel,
+		"chat_id":  entry.ChatID,
+		"error":    entry.Error,
+		"attempts": entry.Attempts,
+		"trace_id": entry.TraceID,
+	}
+	if d == nil || d.path == "" {
+		logger.ErrorCF("channels", "Message dead-lettered", fields)
+		return
+	}
+
+	fields["file"] = d.path
+	logger.ErrorCF("channels", "Message dead-lettered", fields)
+	if err := d.append(entry); err != nil {
+		logger.ErrorCF("channels", "Failed to write dead letter", map[string]any{
+			"file":  d.path,
+			"error": err.Error(),
+		})
+	}
+}
+
+func (d *deadLetterLog) append(entry deadLetter) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o700); err != nil {
+		return err
+	}
+	if info, err := os.Stat(d.path); err == nil && info.Size()+int64(len(line))+1 > d.maxSize {
+		if err := os.Rename(d.path, d.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate dead-letter file: %w", err)
+		}
+	}
+	f, err := os.OpenFile(d.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
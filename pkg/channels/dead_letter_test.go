@@ -0,0 +1,178 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newDeliveryTestManager(t *testing.T, retries int) (*Manager, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "channels", "dead_letter.jsonl")
+	m := newTestManager()
+	m.config = &config.Config{Gateway: config.GatewayConfig{
+		Delivery: config.GatewayDeliveryConfig{MaxRetries: &retries},
+	}}
+	m.deadLetters = newDeadLetterLog(path, 0)
+	return m, path
+}
+
+func readDeadLetters(t *testing.T, path string) []deadLetter {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []deadLetter
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry deadLetter
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid dead letter line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestSendWithRetry_FailsTwiceThenSucceeds(t *testing.T) {
+	m, path := newDeliveryTestManager(t, 2)
+	var callCount int
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			callCount++
+			if callCount <= 2 {
+				return fmt.Errorf("wecom api error: %w", ErrTemporary)
+			}
+			return nil
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	m.sendWithRetry(context.Background(), "wecom", w, bus.OutboundMessage{ChatID: "u1", Content: "hello"})
+
+	if callCount != 3 {
+		t.Fatalf("Send calls = %d, want 3", callCount)
+	}
+	if entries := readDeadLetters(t, path); len(entries) != 0 {
+		t.Fatalf("dead letters = %+v, want none", entries)
+	}
+}
+
+func TestSendWithRetry_ExhaustedIsDeadLettered(t *testing.T) {
+	m, path := newDeliveryTestManager(t, 1)
+	var callCount int
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			callCount++
+			return fmt.Errorf("network down: %w", ErrTemporary)
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	msg := bus.OutboundMessage{ChatID: "u1", Content: "the answer", TraceID: "trace-1"}
+	m.sendWithRetry(context.Background(), "wecom", w, msg)
+
+	if callCount != 2 {
+		t.Fatalf("Send calls = %d, want 2 (max_retries = 1)", callCount)
+	}
+	entries := readDeadLetters(t, path)
+	if len(entries) != 1 {
+		t.Fatalf("dead letters = %d, want 1", len(entries))
+	}
+	got := entries[0]
+	if got.Channel != "wecom" || got.ChatID != "u1" || got.Content != "the answer" || got.TraceID != "trace-1" {
+		t.Errorf("dead letter = %+v, want the failed message", got)
+	}
+	if got.Attempts != 2 || !strings.Contains(got.Error, "network down") {
+		t.Errorf("dead letter attempts/error = %d/%q", got.Attempts, got.Error)
+	}
+}
+
+func TestSendWithRetry_ZeroMaxRetriesDisablesRetries(t *testing.T) {
+	m, path := newDeliveryTestManager(t, 0)
+	var callCount int
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			callCount++
+			return fmt.Errorf("network down: %w", ErrTemporary)
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	m.sendWithRetry(context.Background(), "wecom", w, bus.OutboundMessage{ChatID: "u1", Content: "hi"})
+
+	if callCount != 1 {
+		t.Fatalf("Send calls = %d, want 1 (max_retries = 0)", callCount)
+	}
+	if entries := readDeadLetters(t, path); len(entries) != 1 {
+		t.Fatalf("dead letters = %d, want 1", len(entries))
+	}
+
+	m.config = &config.Config{}
+	if got := m.sendRetries(); got != maxRetries {
+		t.Errorf("retries with max_retries unset = %d, want %d", got, maxRetries)
+	}
+}
+
+func TestDeadLetterLog_RotatesAtMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead_letter.jsonl")
+	d := newDeadLetterLog(path, 300)
+	for i := range 5 {
+		d.add(deadLetter{Channel: "telegram", ChatID: fmt.Sprint(i), Content: strings.Repeat("x", 100), Error: "boom"})
+	}
+
+	for _, p := range []string{path, path + ".1"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("stat %s: %v", p, err)
+		}
+		if info.Size() > 300 {
+			t.Errorf("%s is %d bytes, want at most 300", p, info.Size())
+		}
+	}
+	entries := readDeadLetters(t, path)
+	if len(entries) == 0 || entries[len(entries)-1].ChatID != "4" {
+		t.Errorf("current file = %+v, want it to end with the newest entry", entries)
+	}
+}
+
+func TestSendWithRetry_PermanentFailureIsDeadLettered(t *testing.T) {
+	m, path := newDeliveryTestManager(t, 3)
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			return fmt.Errorf("invalid chat: %w", ErrSendFailed)
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	m.sendWithRetry(context.Background(), "telegram", w, bus.OutboundMessage{ChatID: "x", Content: "hi"})
+
+	entries := readDeadLetters(t, path)
+	if len(entries) != 1 || entries[0].Attempts != 1 {
+		t.Fatalf("dead letters = %+v, want one entry after a single attempt", entries)
+	}
+}
+
+func TestDeadLetterPath(t *testing.T) {
+	t.Setenv(config.EnvHome, t.TempDir())
+	cfg := config.DefaultConfig()
+	if got, want := deadLetterPath(cfg), filepath.Join(config.HomeDir(), "channels", "dead_letter.jsonl"); got != want {
+		t.Errorf("default path = %q, want %q", got, want)
+	}
+	cfg.Gateway.Delivery.DeadLetterFile = "/var/log/picoclaw/dead.jsonl"
+	if got := deadLetterPath(cfg); got != "/var/log/picoclaw/dead.jsonl" {
+		t.Errorf("configured path = %q", got)
+	}
+}
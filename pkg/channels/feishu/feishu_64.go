@@ -110,6 +110,10 @@ func (c *FeishuChannel) Start(ctx context.Context) error {
 				"error": err.Error(),
 			})
 		}
+		// The websocket client has given up; let the channel supervisor restart us.
+		if runCtx.Err() == nil {
+			c.SetRunning(false)
+		}
 	}()
 
 	return nil
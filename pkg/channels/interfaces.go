@@ -20,6 +20,13 @@ type MessageEditor interface {
 	EditMessage(ctx context.Context, chatID string, messageID string, content string) error
 }
 
+// LastMessageTracker — channels that remember the ID of the last message they
+// sent to each chat, so status-style updates can edit it in place through
+// MessageEditor (see Manager.EditLastMessage).
+type LastMessageTracker interface {
+	LastMessageID(chatID string) (messageID string, ok bool)
+}
+
 // MessageDeleter — channels that can delete a message by ID.
 type MessageDeleter interface {
 	DeleteMessage(ctx context.Context, chatID string, messageID string) error
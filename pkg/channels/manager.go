@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -91,6 +92,8 @@ type Manager struct {
 	reactionUndos sync.Map          // "channel:chatID" → reactionEntry
 	streamActive  sync.Map          // "channel:chatID" → true (set when streamer.Finalize sent the message)
 	channelHashes map[string]string // channel name → config hash
+	restart       restartPolicy
+	deadLetters   *deadLetterLog
 }
 
 type asyncTask struct {
@@ -114,7 +117,7 @@ func (m *Manager) SendPlaceholder(ctx context.Context, channel, chatID string) b
 		return false
 	}
 	pc, ok := ch.(PlaceholderCapable)
-	if !ok {
+	if !ok || !ChannelCapabilities(ch).Has(SupportsPlaceholder) {
 		return false
 	}
 	phID, err := pc.SendPlaceholder(ctx, chatID)
@@ -214,6 +217,8 @@ func NewManager(cfg *config.Config, messageBus *bus.MessageBus, store media.Medi
 		config:        cfg,
 		mediaStore:    store,
 		channelHashes: make(map[string]string),
+		restart:       defaultRestartPolicy,
+		deadLetters:   newDeadLetterLog(deadLetterPath(cfg), cfg.Gateway.Delivery.DeadLetterMaxSize),
 	}
 
 	// Register as streaming delegate so the agent loop can obtain streamers
@@ -241,7 +246,7 @@ func (m *Manager) GetStreamer(ctx context.Context, channelName, chatID string) (
 	}
 
 	sc, ok := ch.(StreamingCapable)
-	if !ok {
+	if !ok || !ChannelCapabilities(ch).Has(SupportsStreaming) {
 		return nil, false
 	}
 
@@ -405,8 +410,9 @@ func (m *Manager) initChannels(channels *config.ChannelsConfig) error {
 }
 
 // SetupHTTPServer creates a shared HTTP server with the given listen address.
-// It registers health endpoints from the health server and discovers channels
-// that implement WebhookHandler and/or HealthChecker to register their handlers.
+// It registers health endpoints from the health server and the aggregated
+// /health/channels endpoint, and discovers channels that implement
+// WebhookHandler and/or HealthChecker to register their handlers.
 func (m *Manager) SetupHTTPServer(addr string, healthServer *health.Server) {
 	m.mux = http.NewServeMux()
 
@@ -414,6 +420,7 @@ func (m *Manager) SetupHTTPServer(addr string, healthServer *health.Server) {
 	if healthServer != nil {
 		healthServer.RegisterOnMux(m.mux)
 	}
+	m.mux.HandleFunc(channelsHealthPath, m.handleChannelsHealth)
 
 	// Discover and register webhook handlers and health checkers
 	for name, ch := range m.channels {
@@ -441,6 +448,15 @@ func (m *Manager) SetupHTTPServer(addr string, healthServer *health.Server) {
 	}
 }
 
+// HandleHTTP registers h for pattern on the gateway HTTP server, next to the
+// webhook and health endpoints. It must be called after SetupHTTPServer.
+func (m *Manager) HandleHTTP(pattern string, h http.Handler) {
+	if m.mux == nil {
+		return
+	}
+	m.mux.Handle(pattern, h)
+}
+
 func (m *Manager) StartAll(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -451,6 +467,14 @@ func (m *Manager) StartAll(ctx context.Context) error {
 
 	logger.InfoC("channels", "Starting all channels")
 
+	var tlsCfg config.GatewayTLSConfig
+	if m.httpServer != nil && m.config != nil {
+		tlsCfg = m.config.Gateway.TLS
+		if err := configureServerTLS(m.httpServer, tlsCfg, config.HomeDir()); err != nil {
+			return err
+		}
+	}
+
 	dispatchCtx, cancel := context.WithCancel(ctx)
 	m.dispatchTask = &asyncTask{cancel: cancel}
 
@@ -479,13 +503,19 @@ func (m *Manager) StartAll(ctx context.Context) error {
 	// Start the TTL janitor that cleans up stale typing/placeholder entries
 	go m.runTTLJanitor(dispatchCtx)
 
+	// Restart channels that failed to start or stop running on their own
+	if m.restart.interval > 0 {
+		go m.superviseChannels(dispatchCtx)
+	}
+
 	// Start shared HTTP server if configured
 	if m.httpServer != nil {
 		go func() {
 			logger.InfoCF("channels", "Shared HTTP server listening", map[string]any{
 				"addr": m.httpServer.Addr,
+				"tls":  tlsCfg.Enabled(),
 			})
-			if err := m.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			if err := listenAndServe(m.httpServer, tlsCfg); err != nil && err != http.ErrServerClosed {
 				logger.FatalCF("channels", "Shared HTTP server error", map[string]any{
 					"error": err.Error(),
 				})
@@ -590,10 +620,15 @@ func (m *Manager) runWorker(ctx context.Context, name string, w *channelWorker)
 			if !ok {
 				return
 			}
-			maxLen := 0
-			if mlp, ok := w.ch.(MessageLengthProvider); ok {
-				maxLen = mlp.MaxMessageLength()
+			if msg.EditLast {
+				if err := w.limiter.Wait(ctx); err != nil {
+					return
+				}
+				if m.editLast(ctx, name, w.ch, msg.ChatID, msg.Content) {
+					continue
+				}
 			}
+			maxLen := m.maxMessageLength(name, w.ch)
 			if maxLen > 0 && len([]rune(msg.Content)) > maxLen {
 				chunks := SplitMessage(msg.Content, maxLen)
 				for _, chunk := range chunks {
@@ -610,12 +645,29 @@ func (m *Manager) runWorker(ctx context.Context, name string, w *channelWorker)
 	}
 }
 
+// maxMessageLength returns the length outbound messages to the channel are
+// split at: gateway.delivery.max_message_length[name] when set, otherwise
+// the channel's own MaxMessageLength. Zero disables splitting.
+func (m *Manager) maxMessageLength(name string, ch Channel) int {
+	if m.config != nil {
+		if n := m.config.Gateway.Delivery.MaxMessageLength[name]; n > 0 {
+			return n
+		}
+	}
+	if mlp, ok := ch.(MessageLengthProvider); ok {
+		return mlp.MaxMessageLength()
+	}
+	return 0
+}
+
 // sendWithRetry sends a message through the channel with rate limiting and
 // retry logic. It classifies errors to determine the retry strategy:
 //   - ErrNotRunning / ErrSendFailed: permanent, no retry
 //   - ErrRateLimit: fixed delay retry
 //   - ErrTemporary / unknown: exponential backoff retry
 func (m *Manager) sendWithRetry(ctx context.Context, name string, w *channelWorker, msg bus.OutboundMessage) {
+	ctx = bus.WithTraceID(ctx, msg.TraceID)
+
 	// Rate limit: wait for token
 	if err := w.limiter.Wait(ctx); err != nil {
 		// ctx canceled, shutting down
@@ -628,7 +680,10 @@ func (m *Manager) sendWithRetry(ctx context.Context, name string, w *channelWork
 	}
 
 	var lastErr error
-	for attempt := 0; attempt <= maxRetries; attempt++ {
+	retries := m.sendRetries()
+	attempts := 0
+	for attempt := 0; attempt <= retries; attempt++ {
+		attempts++
 		lastErr = w.ch.Send(ctx, msg)
 		if lastErr == nil {
 			return
@@ -640,7 +695,7 @@ func (m *Manager) sendWithRetry(ctx context.Context, name string, w *channelWork
 		}
 
 		// Last attempt exhausted — don't sleep
-		if attempt == maxRetries {
+		if attempt == retries {
 			break
 		}
 
@@ -664,14 +719,36 @@ func (m *Manager) sendWithRetry(ctx context.Context, name string, w *channelWork
 	}
 
 	// All retries exhausted or permanent failure
-	logger.ErrorCF("channels", "Send failed", map[string]any{
-		"channel": name,
-		"chat_id": msg.ChatID,
-		"error":   lastErr.Error(),
-		"retries": maxRetries,
+	m.deadLetters.add(deadLetter{
+		Time:     time.Now(),
+		Channel:  name,
+		ChatID:   msg.ChatID,
+		Content:  msg.Content,
+		Error:    lastErr.Error(),
+		Attempts: attempts,
+		TraceID:  msg.TraceID,
 	})
 }
 
+// sendRetries returns how many times a failed send is retried, from
+// gateway.delivery.max_retries or maxRetries when unset.
+func (m *Manager) sendRetries() int {
+	if m.config != nil && m.config.Gateway.Delivery.MaxRetries != nil {
+		return max(*m.config.Gateway.Delivery.MaxRetries, 0)
+	}
+	return maxRetries
+}
+
+// deadLetterPath returns where undeliverable messages are recorded. The
+// default is outside the workspace: the file holds message content from
+// every chat, which the agent's file tools must not expose.
+func deadLetterPath(cfg *config.Config) string {
+	if cfg.Gateway.Delivery.DeadLetterFile != "" {
+		return cfg.Gateway.Delivery.DeadLetterFile
+	}
+	return filepath.Join(config.HomeDir(), "channels", "dead_letter.jsonl")
+}
+
 func dispatchLoop[M any](
 	ctx context.Context,
 	m *Manager,
@@ -782,7 +859,7 @@ func (m *Manager) runMediaWorker(ctx context.Context, name string, w *channelWor
 // retry logic. If the channel does not implement MediaSender, it silently skips.
 func (m *Manager) sendMediaWithRetry(ctx context.Context, name string, w *channelWorker, msg bus.OutboundMediaMessage) {
 	ms, ok := w.ch.(MediaSender)
-	if !ok {
+	if !ok || !ChannelCapabilities(w.ch).Has(SupportsMedia) {
 		logger.DebugCF("channels", "Channel does not support MediaSender, skipping media", map[string]any{
 			"channel": name,
 		})
@@ -795,7 +872,10 @@ func (m *Manager) sendMediaWithRetry(ctx context.Context, name string, w *channe
 	}
 
 	var lastErr error
-	for attempt := 0; attempt <= maxRetries; attempt++ {
+	retries := m.sendRetries()
+	attempts := 0
+	for attempt := 0; attempt <= retries; attempt++ {
+		attempts++
 		lastErr = ms.SendMedia(ctx, msg)
 		if lastErr == nil {
 			return
@@ -807,7 +887,7 @@ func (m *Manager) sendMediaWithRetry(ctx context.Context, name string, w *channe
 		}
 
 		// Last attempt exhausted — don't sleep
-		if attempt == maxRetries {
+		if attempt == retries {
 			break
 		}
 
@@ -831,11 +911,13 @@ func (m *Manager) sendMediaWithRetry(ctx context.Context, name string, w *channe
 	}
 
 	// All retries exhausted or permanent failure
-	logger.ErrorCF("channels", "SendMedia failed", map[string]any{
-		"channel": name,
-		"chat_id": msg.ChatID,
-		"error":   lastErr.Error(),
-		"retries": maxRetries,
+	m.deadLetters.add(deadLetter{
+		Time:     time.Now(),
+		Channel:  name,
+		ChatID:   msg.ChatID,
+		Parts:    msg.Parts,
+		Error:    lastErr.Error(),
+		Attempts: attempts,
 	})
 }
 
@@ -1013,10 +1095,7 @@ func (m *Manager) SendMessage(ctx context.Context, msg bus.OutboundMessage) erro
 		return fmt.Errorf("channel %s has no active worker", msg.Channel)
 	}
 
-	maxLen := 0
-	if mlp, ok := w.ch.(MessageLengthProvider); ok {
-		maxLen = mlp.MaxMessageLength()
-	}
+	maxLen := m.maxMessageLength(msg.Channel, w.ch)
 	if maxLen > 0 && len([]rune(msg.Content)) > maxLen {
 		for _, chunk := range SplitMessage(msg.Content, maxLen) {
 			chunkMsg := msg
@@ -1029,6 +1108,53 @@ func (m *Manager) SendMessage(ctx context.Context, msg bus.OutboundMessage) erro
 	return nil
 }
 
+// EditLastMessage replaces the content of the last message sent to chatID on
+// channelName, for status-style updates that should not clutter the chat.
+// Channels that cannot edit, or that have not sent to the chat yet, get the
+// content as a new message instead.
+func (m *Manager) EditLastMessage(ctx context.Context, channelName, chatID, content string) error {
+	m.mu.RLock()
+	ch, exists := m.channels[channelName]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("channel %s not found", channelName)
+	}
+
+	if m.editLast(ctx, channelName, ch, chatID, content) {
+		return nil
+	}
+	return m.SendMessage(ctx, bus.OutboundMessage{
+		Channel: channelName,
+		ChatID:  chatID,
+		Content: content,
+	})
+}
+
+// editLast edits the last message ch sent to chatID and reports whether it
+// did. Outbound messages with EditLast set go through here first.
+func (m *Manager) editLast(ctx context.Context, channelName string, ch Channel, chatID, content string) bool {
+	editor, canEdit := ch.(MessageEditor)
+	tracker, tracks := ch.(LastMessageTracker)
+	if !canEdit || !tracks {
+		return false
+	}
+	messageID, ok := tracker.LastMessageID(chatID)
+	if !ok {
+		return false
+	}
+	if err := editor.EditMessage(ctx, chatID, messageID, content); err != nil {
+		logger.WarnCF("channels", "Edit failed, sending a new message", map[string]any{
+			"channel":    channelName,
+			"chat_id":    chatID,
+			"message_id": messageID,
+			"error":      err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
 func (m *Manager) SendToChannel(ctx context.Context, channelName, chatID, content string) error {
 	m.mu.RLock()
 	_, exists := m.channels[channelName]
@@ -0,0 +1,58 @@
+package channels
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// channelsHealthPath is the aggregated health endpoint for all channels.
+const channelsHealthPath = "/health/channels"
+
+// ChannelHealth is the health of one registered channel.
+type ChannelHealth struct {
+	Running bool           `json:"running"`
+	Healthy bool           `json:"healthy"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// ChannelsHealth returns the health of every registered channel, keyed by
+// channel name. A channel is healthy when it is running and, if it
+// implements HealthReporter, reports itself healthy.
+func (m *Manager) ChannelsHealth() map[string]ChannelHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[string]ChannelHealth, len(m.channels))
+	for name, ch := range m.channels {
+		h := ChannelHealth{Running: ch.IsRunning()}
+		h.Healthy = h.Running
+		if hr, ok := ch.(HealthReporter); ok {
+			healthy, details := hr.HealthDetails()
+			h.Healthy = h.Healthy && healthy
+			h.Details = details
+		}
+		result[name] = h
+	}
+	return result
+}
+
+// handleChannelsHealth serves the combined channel health. It responds 503
+// when any channel is unhealthy so monitoring can alert on the status code.
+func (m *Manager) handleChannelsHealth(w http.ResponseWriter, r *http.Request) {
+	channels := m.ChannelsHealth()
+
+	status, code := "ok", http.StatusOK
+	for _, h := range channels {
+		if !h.Healthy {
+			status, code = "degraded", http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]any{
+		"status":   status,
+		"channels": channels,
+	})
+}
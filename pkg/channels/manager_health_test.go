@@ -0,0 +1,106 @@
+package channels
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// mockHealthReporter is a channel that reports token validity.
+type mockHealthReporter struct {
+	mockChannel
+	hasToken bool
+}
+
+func (m *mockHealthReporter) HealthDetails() (bool, map[string]any) {
+	return m.hasToken, map[string]any{"has_token": m.hasToken}
+}
+
+func getChannelsHealth(t *testing.T, m *Manager) (int, map[string]any) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.handleChannelsHealth(w, httptest.NewRequest(http.MethodGet, channelsHealthPath, nil))
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON body %q: %v", w.Body.String(), err)
+	}
+	return w.Code, body
+}
+
+func TestChannelsHealth_RunningAndStopped(t *testing.T) {
+	m := newTestManager()
+	running := &mockChannel{}
+	running.SetRunning(true)
+	m.channels["telegram"] = running
+	m.channels["discord"] = &mockChannel{}
+
+	health := m.ChannelsHealth()
+	if h := health["telegram"]; !h.Running || !h.Healthy {
+		t.Errorf("telegram = %+v, want running and healthy", h)
+	}
+	if h := health["discord"]; h.Running || h.Healthy {
+		t.Errorf("discord = %+v, want stopped and unhealthy", h)
+	}
+
+	code, body := getChannelsHealth(t, m)
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("status code = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if body["status"] != "degraded" {
+		t.Errorf("status = %v, want degraded", body["status"])
+	}
+	channels, _ := body["channels"].(map[string]any)
+	if len(channels) != 2 {
+		t.Fatalf("channels = %v, want telegram and discord", body["channels"])
+	}
+	if discord, _ := channels["discord"].(map[string]any); discord["running"] != false {
+		t.Errorf("discord = %v, want running false", discord)
+	}
+}
+
+func TestChannelsHealth_AllHealthy(t *testing.T) {
+	m := newTestManager()
+	ch := &mockChannel{}
+	ch.SetRunning(true)
+	m.channels["telegram"] = ch
+
+	code, body := getChannelsHealth(t, m)
+	if code != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("got %d %v, want 200 ok", code, body["status"])
+	}
+}
+
+func TestChannelsHealth_HealthReporter(t *testing.T) {
+	m := newTestManager()
+	ch := &mockHealthReporter{}
+	ch.SetRunning(true)
+	m.channels["wecom_app"] = ch
+
+	h := m.ChannelsHealth()["wecom_app"]
+	if !h.Running || h.Healthy {
+		t.Errorf("without token = %+v, want running but unhealthy", h)
+	}
+	if h.Details["has_token"] != false {
+		t.Errorf("details = %v, want has_token false", h.Details)
+	}
+
+	ch.hasToken = true
+	if h := m.ChannelsHealth()["wecom_app"]; !h.Healthy {
+		t.Errorf("with token = %+v, want healthy", h)
+	}
+}
+
+func TestSetupHTTPServer_RegistersChannelsHealth(t *testing.T) {
+	m := newTestManager()
+	m.SetupHTTPServer("127.0.0.1:0", nil)
+
+	w := httptest.NewRecorder()
+	m.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, channelsHealthPath, nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status code = %d, want %d", w.Code, http.StatusOK)
+	}
+}
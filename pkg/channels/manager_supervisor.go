@@ -0,0 +1,144 @@
+package channels
+
+import (
+	"context"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// restartPolicy controls how the Manager restarts channels that failed to
+// start or stopped running on their own. A zero interval disables
+// supervision.
+type restartPolicy struct {
+	interval    time.Duration // how often channels are checked
+	baseDelay   time.Duration // delay before the second attempt, doubled per attempt
+	maxDelay    time.Duration
+	maxAttempts int           // consecutive failed restarts before giving up
+	resetAfter  time.Duration // running this long after a restart clears the attempt count
+}
+
+var defaultRestartPolicy = restartPolicy{
+	interval:    10 * time.Second,
+	baseDelay:   5 * time.Second,
+	maxDelay:    5 * time.Minute,
+	maxAttempts: 5,
+	resetAfter:  10 * time.Minute,
+}
+
+// restartState tracks restart attempts for one channel.
+type restartState struct {
+	attempts    int
+	nextAttempt time.Time
+	restartedAt time.Time
+	gaveUp      bool
+}
+
+// delay returns the backoff before the attempt following the given number
+// of attempts.
+func (p restartPolicy) delay(attempts int) time.Duration {
+	d := p.baseDelay
+	for i := 1; i < attempts && d < p.maxDelay; i++ {
+		d *= 2
+	}
+	return min(d, p.maxDelay)
+}
+
+// superviseChannels periodically restarts channels that are not running,
+// with exponential backoff and up to restart.maxAttempts consecutive tries.
+// It exits when ctx is canceled, i.e. when StopAll runs.
+func (m *Manager) superviseChannels(ctx context.Context) {
+	ticker := time.NewTicker(m.restart.interval)
+	defer ticker.Stop()
+
+	states := make(map[string]*restartState)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.mu.RLock()
+			snapshot := make(map[string]Channel, len(m.channels))
+			for name, ch := range m.channels {
+				snapshot[name] = ch
+			}
+			m.mu.RUnlock()
+
+			for name := range states {
+				if _, ok := snapshot[name]; !ok {
+					delete(states, name)
+				}
+			}
+			for name, ch := range snapshot {
+				st := states[name]
+				if st == nil {
+					st = &restartState{}
+					states[name] = st
+				}
+				m.superviseChannel(ctx, name, ch, st, now)
+			}
+		}
+	}
+}
+
+// superviseChannel checks one channel and restarts it if it is due.
+func (m *Manager) superviseChannel(ctx context.Context, name string, ch Channel, st *restartState, now time.Time) {
+	if ch.IsRunning() {
+		if st.attempts > 0 && now.Sub(st.restartedAt) >= m.restart.resetAfter {
+			*st = restartState{}
+		}
+		return
+	}
+	if st.gaveUp || now.Before(st.nextAttempt) {
+		return
+	}
+	if st.attempts >= m.restart.maxAttempts {
+		st.gaveUp = true
+		logger.ErrorCF("channels", "Channel is down, giving up on restarts", map[string]any{
+			"channel":  name,
+			"attempts": st.attempts,
+		})
+		return
+	}
+
+	st.attempts++
+	logger.WarnCF("channels", "Channel is not running, restarting", map[string]any{
+		"channel": name,
+		"attempt": st.attempts,
+	})
+
+	// Stop first so a half-dead channel releases its connections.
+	if err := ch.Stop(ctx); err != nil {
+		logger.DebugCF("channels", "Error stopping channel before restart", map[string]any{
+			"channel": name,
+			"error":   err.Error(),
+		})
+	}
+	if err := ch.Start(ctx); err != nil {
+		st.nextAttempt = now.Add(m.restart.delay(st.attempts))
+		logger.ErrorCF("channels", "Failed to restart channel", map[string]any{
+			"channel":    name,
+			"error":      err.Error(),
+			"next_retry": st.nextAttempt.Format(time.RFC3339),
+		})
+		return
+	}
+	st.restartedAt = now
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ctx.Err() != nil || m.channels[name] != ch {
+		// StopAll or a reload raced with the restart.
+		ch.Stop(context.Background())
+		return
+	}
+	if _, ok := m.workers[name]; !ok {
+		w := newChannelWorker(name, ch)
+		m.workers[name] = w
+		go m.runWorker(ctx, name, w)
+		go m.runMediaWorker(ctx, name, w)
+	}
+	logger.InfoCF("channels", "Channel restarted", map[string]any{
+		"channel": name,
+	})
+}
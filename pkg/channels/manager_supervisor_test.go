@@ -0,0 +1,170 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// flakyChannel fails its first failStarts calls to Start.
+type flakyChannel struct {
+	BaseChannel
+	failStarts int32
+	starts     atomic.Int32
+}
+
+func (c *flakyChannel) Start(ctx context.Context) error {
+	if c.starts.Add(1) <= c.failStarts {
+		return errors.New("connection refused")
+	}
+	c.SetRunning(true)
+	return nil
+}
+
+func (c *flakyChannel) Stop(ctx context.Context) error {
+	c.SetRunning(false)
+	return nil
+}
+
+func (c *flakyChannel) Send(ctx context.Context, msg bus.OutboundMessage) error { return nil }
+
+var testRestartPolicy = restartPolicy{
+	interval:    5 * time.Millisecond,
+	baseDelay:   5 * time.Millisecond,
+	maxDelay:    20 * time.Millisecond,
+	maxAttempts: 3,
+	resetAfter:  time.Hour,
+}
+
+func newSupervisedManager(t *testing.T, name string, ch Channel) *Manager {
+	t.Helper()
+	mb := bus.NewMessageBus()
+	t.Cleanup(mb.Close)
+
+	m := newTestManager()
+	m.bus = mb
+	m.restart = testRestartPolicy
+	m.channels[name] = ch
+	if err := m.StartAll(context.Background()); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+	t.Cleanup(func() { m.StopAll(context.Background()) })
+	return m
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSupervisor_RestartsChannelThatFailedToStart(t *testing.T) {
+	ch := &flakyChannel{failStarts: 1}
+	m := newSupervisedManager(t, "flaky", ch)
+
+	waitFor(t, "channel restart", ch.IsRunning)
+	if got := ch.starts.Load(); got != 2 {
+		t.Errorf("Start calls = %d, want 2", got)
+	}
+	waitFor(t, "worker for restarted channel", func() bool {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return m.workers["flaky"] != nil
+	})
+}
+
+func TestSupervisor_RestartsCrashedChannel(t *testing.T) {
+	ch := &flakyChannel{}
+	newSupervisedManager(t, "flaky", ch)
+	if !ch.IsRunning() {
+		t.Fatal("channel should be running after StartAll")
+	}
+
+	// Simulate the channel's connection dying.
+	ch.SetRunning(false)
+
+	waitFor(t, "channel restart", func() bool { return ch.starts.Load() == 2 && ch.IsRunning() })
+}
+
+// droppingChannel runs a fake connection in the background, like the
+// websocket channels do, and clears its running flag when it drops.
+type droppingChannel struct {
+	BaseChannel
+	starts atomic.Int32
+	drop   chan struct{}
+}
+
+func (c *droppingChannel) Start(ctx context.Context) error {
+	c.starts.Add(1)
+	drop := make(chan struct{})
+	c.drop = drop
+	go func() {
+		select {
+		case <-drop:
+			c.SetRunning(false)
+		case <-ctx.Done():
+		}
+	}()
+	c.SetRunning(true)
+	return nil
+}
+
+func (c *droppingChannel) Stop(ctx context.Context) error {
+	c.SetRunning(false)
+	return nil
+}
+
+func (c *droppingChannel) Send(ctx context.Context, msg bus.OutboundMessage) error { return nil }
+
+func TestSupervisor_RestartsChannelAfterDroppedConnection(t *testing.T) {
+	ch := &droppingChannel{}
+	newSupervisedManager(t, "dropping", ch)
+	if !ch.IsRunning() {
+		t.Fatal("channel should be running after StartAll")
+	}
+
+	close(ch.drop)
+
+	waitFor(t, "channel restart", func() bool { return ch.starts.Load() == 2 && ch.IsRunning() })
+}
+
+func TestSupervisor_GivesUpAfterMaxAttempts(t *testing.T) {
+	ch := &flakyChannel{failStarts: 100}
+	newSupervisedManager(t, "flaky", ch)
+
+	// One start from StartAll plus maxAttempts restarts.
+	want := int32(1 + testRestartPolicy.maxAttempts)
+	waitFor(t, "restart attempts", func() bool { return ch.starts.Load() >= want })
+	time.Sleep(100 * time.Millisecond)
+	if got := ch.starts.Load(); got != want {
+		t.Errorf("Start calls = %d, want %d", got, want)
+	}
+}
+
+func TestRestartPolicyDelay(t *testing.T) {
+	p := restartPolicy{baseDelay: time.Second, maxDelay: 5 * time.Second}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{10, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.delay(tt.attempts); got != tt.want {
+			t.Errorf("delay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
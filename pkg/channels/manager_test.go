@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
 )
 
 // mockChannel is a test double that delegates Send to a configurable function.
@@ -1227,3 +1229,230 @@ func TestManager_SendPlaceholder(t *testing.T) {
 		t.Error("expected SendPlaceholder to fail for unknown channel")
 	}
 }
+
+func TestRunWorker_ConfiguredMaxMessageLengthKeepsCodeFences(t *testing.T) {
+	m := newTestManager()
+	m.config = &config.Config{Gateway: config.GatewayConfig{
+		Delivery: config.GatewayDeliveryConfig{MaxMessageLength: map[string]int{"test": 60}},
+	}}
+
+	received := make(chan string, 20)
+	ch := &mockChannelWithLength{
+		mockChannel: mockChannel{
+			sendFn: func(_ context.Context, msg bus.OutboundMessage) error {
+				received <- msg.Content
+				return nil
+			},
+		},
+		maxLen: 4000,
+	}
+	w := &channelWorker{
+		ch:      ch,
+		queue:   make(chan bus.OutboundMessage, 1),
+		done:    make(chan struct{}),
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+	go m.runWorker(t.Context(), "test", w)
+
+	var code strings.Builder
+	for i := range 8 {
+		fmt.Fprintf(&code, "fmt.Println(%d)\n", i)
+	}
+	content := "Here is the program:\n\n```go\n" + code.String() + "```\nDone."
+	w.queue <- bus.OutboundMessage{Channel: "test", ChatID: "1", Content: content}
+
+	var chunks []string
+	for joined := ""; !strings.Contains(joined, "Done."); joined = strings.Join(chunks, "\n") {
+		select {
+		case chunk := <-received:
+			chunks = append(chunks, chunk)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out; received %q", chunks)
+		}
+	}
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected the configured limit to split the message, got %q", chunks)
+	}
+	for i, chunk := range chunks {
+		if n := len([]rune(chunk)); n > 60 {
+			t.Errorf("chunk %d has %d runes, want <= 60: %q", i, n, chunk)
+		}
+		if strings.Count(chunk, "```")%2 != 0 {
+			t.Errorf("chunk %d leaves a code fence open: %q", i, chunk)
+		}
+	}
+}
+
+func TestMaxMessageLength_FallsBackToChannel(t *testing.T) {
+	m := newTestManager()
+	ch := &mockChannelWithLength{maxLen: 2048}
+	if got := m.maxMessageLength("wecom", ch); got != 2048 {
+		t.Errorf("without config = %d, want 2048", got)
+	}
+
+	m.config = &config.Config{Gateway: config.GatewayConfig{
+		Delivery: config.GatewayDeliveryConfig{MaxMessageLength: map[string]int{"telegram": 3000}},
+	}}
+	if got := m.maxMessageLength("wecom", ch); got != 2048 {
+		t.Errorf("other channel configured = %d, want 2048", got)
+	}
+	if got := m.maxMessageLength("telegram", ch); got != 3000 {
+		t.Errorf("configured = %d, want 3000", got)
+	}
+	if got := m.maxMessageLength("plain", &mockChannel{}); got != 0 {
+		t.Errorf("no limit = %d, want 0", got)
+	}
+}
+
+// mockTrackingEditor is a MessageEditor that also tracks the last message ID.
+type mockTrackingEditor struct {
+	mockMessageEditor
+	lastIDs map[string]string
+}
+
+func (m *mockTrackingEditor) LastMessageID(chatID string) (string, bool) {
+	id, ok := m.lastIDs[chatID]
+	return id, ok
+}
+
+func TestEditLastMessage_EditsInPlace(t *testing.T) {
+	m := newTestManager()
+	var edited []string
+	ch := &mockTrackingEditor{
+		mockMessageEditor: mockMessageEditor{
+			mockChannel: mockChannel{sendFn: func(context.Context, bus.OutboundMessage) error {
+				t.Fatal("Send should not be called when the edit succeeds")
+				return nil
+			}},
+			editFn: func(_ context.Context, chatID, messageID, content string) error {
+				edited = append(edited, chatID+"/"+messageID+"/"+content)
+				return nil
+			},
+		},
+		lastIDs: map[string]string{"123": "42"},
+	}
+	m.channels["test"] = ch
+	m.workers["test"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	if err := m.EditLastMessage(context.Background(), "test", "123", "transfer confirmed"); err != nil {
+		t.Fatalf("EditLastMessage() error = %v", err)
+	}
+	if len(edited) != 1 || edited[0] != "123/42/transfer confirmed" {
+		t.Fatalf("edits = %q, want one edit of message 42", edited)
+	}
+}
+
+func TestEditLastMessage_FallsBackToSend(t *testing.T) {
+	tests := []struct {
+		name string
+		ch   func(send func(context.Context, bus.OutboundMessage) error) Channel
+	}{
+		{
+			name: "channel does not track messages",
+			ch: func(send func(context.Context, bus.OutboundMessage) error) Channel {
+				return &mockChannel{sendFn: send}
+			},
+		},
+		{
+			name: "nothing sent to the chat yet",
+			ch: func(send func(context.Context, bus.OutboundMessage) error) Channel {
+				return &mockTrackingEditor{mockMessageEditor: mockMessageEditor{mockChannel: mockChannel{sendFn: send}}}
+			},
+		},
+		{
+			name: "edit fails",
+			ch: func(send func(context.Context, bus.OutboundMessage) error) Channel {
+				return &mockTrackingEditor{
+					mockMessageEditor: mockMessageEditor{
+						mockChannel: mockChannel{sendFn: send},
+						editFn: func(context.Context, string, string, string) error {
+							return errors.New("message to edit not found")
+						},
+					},
+					lastIDs: map[string]string{"123": "42"},
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager()
+			var sent []string
+			ch := tt.ch(func(_ context.Context, msg bus.OutboundMessage) error {
+				sent = append(sent, msg.Content)
+				return nil
+			})
+			m.channels["test"] = ch
+			m.workers["test"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+			if err := m.EditLastMessage(context.Background(), "test", "123", "agent progress: 50%"); err != nil {
+				t.Fatalf("EditLastMessage() error = %v", err)
+			}
+			if len(sent) != 1 || sent[0] != "agent progress: 50%" {
+				t.Fatalf("sent = %q, want the content as a new message", sent)
+			}
+		})
+	}
+}
+
+func TestRunWorker_EditLastOutbound(t *testing.T) {
+	m := newTestManager()
+	var mu sync.Mutex
+	var edited, sent []string
+	ch := &mockTrackingEditor{
+		mockMessageEditor: mockMessageEditor{
+			mockChannel: mockChannel{sendFn: func(_ context.Context, msg bus.OutboundMessage) error {
+				mu.Lock()
+				sent = append(sent, msg.ChatID+"/"+msg.Content)
+				mu.Unlock()
+				return nil
+			}},
+			editFn: func(_ context.Context, chatID, messageID, content string) error {
+				mu.Lock()
+				edited = append(edited, chatID+"/"+messageID+"/"+content)
+				mu.Unlock()
+				return nil
+			},
+		},
+		lastIDs: map[string]string{"123": "42"},
+	}
+	w := &channelWorker{
+		ch:      ch,
+		queue:   make(chan bus.OutboundMessage, 10),
+		done:    make(chan struct{}),
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+	go m.runWorker(t.Context(), "test", w)
+
+	w.queue <- bus.OutboundMessage{Channel: "test", ChatID: "123", Content: "transfer confirmed", EditLast: true}
+	// Nothing was sent to chat 456 yet, so this one goes out as a new message.
+	w.queue <- bus.OutboundMessage{Channel: "test", ChatID: "456", Content: "transfer pending", EditLast: true}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		done := len(edited)+len(sent) == 2
+		mu.Unlock()
+		if done || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(edited) != 1 || edited[0] != "123/42/transfer confirmed" {
+		t.Errorf("edits = %q, want one edit of message 42", edited)
+	}
+	if len(sent) != 1 || sent[0] != "456/transfer pending" {
+		t.Errorf("sent = %q, want the untracked chat's update as a new message", sent)
+	}
+}
+
+func TestEditLastMessage_UnknownChannel(t *testing.T) {
+	m := newTestManager()
+	if err := m.EditLastMessage(context.Background(), "nope", "1", "x"); err == nil {
+		t.Fatal("expected error for unknown channel")
+	}
+}
@@ -0,0 +1,120 @@
+package pico
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	idempotencyTTL        = 10 * time.Minute
+	idempotencyMaxEntries = 1000
+)
+
+// idempotencyEntry tracks one message.send idempotency key and the latest
+// reply the agent produced for it.
+type idempotencyEntry struct {
+	created time.Time
+	traceID string // trace ID of the inbound message the key started
+	reply   *PicoMessage
+}
+
+// idempotencyCache deduplicates retried message.send requests. Keys are
+// scoped to the session, expire after a TTL, and the map is bounded by
+// evicting the oldest entry when full. Replies are matched to keys by the
+// trace ID of the inbound message they answer, so concurrent or overlapping
+// turns in one session never capture each other's replies.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	order   []string          // insertion order, oldest first
+	byTrace map[string]string // trace ID → cache key
+	ttl     time.Duration
+	max     int
+	now     func() time.Time
+}
+
+func newIdempotencyCache(ttl time.Duration, maxEntries int) *idempotencyCache {
+	return &idempotencyCache{
+		entries: make(map[string]*idempotencyEntry),
+		byTrace: make(map[string]string),
+		ttl:     ttl,
+		max:     maxEntries,
+		now:     time.Now,
+	}
+}
+
+func idempotencyCacheKey(chatID, key string) string {
+	return chatID + "\x00" + key
+}
+
+// begin registers key for chatID, answered by the inbound message with
+// traceID. It returns false when the key was already seen within the TTL,
+// along with the cached reply if the original turn has produced one. An
+// empty key is always accepted and nothing is recorded.
+func (c *idempotencyCache) begin(chatID, key, traceID string) (*PicoMessage, bool) {
+	if key == "" {
+		return nil, true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.evictExpired(now)
+
+	cacheKey := idempotencyCacheKey(chatID, key)
+	if entry, ok := c.entries[cacheKey]; ok {
+		return entry.reply, false
+	}
+
+	for len(c.order) >= c.max {
+		c.remove(c.order[0])
+		c.order = c.order[1:]
+	}
+	c.entries[cacheKey] = &idempotencyEntry{created: now, traceID: traceID}
+	c.order = append(c.order, cacheKey)
+	if traceID != "" {
+		c.byTrace[traceID] = cacheKey
+	}
+	return nil, true
+}
+
+// recordReply stores msg as the reply for the key whose inbound message
+// carried traceID. Later replies in the same turn replace earlier ones, so
+// a retry receives the final answer. Replies to unkeyed messages are
+// ignored.
+func (c *idempotencyCache) recordReply(traceID string, msg PicoMessage) {
+	if traceID == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[c.byTrace[traceID]]; ok {
+		entry.reply = &msg
+	}
+}
+
+// evictExpired drops entries older than the TTL. Callers must hold c.mu.
+func (c *idempotencyCache) evictExpired(now time.Time) {
+	n := 0
+	for _, cacheKey := range c.order {
+		entry := c.entries[cacheKey]
+		if entry != nil && now.Sub(entry.created) < c.ttl {
+			break
+		}
+		c.remove(cacheKey)
+		n++
+	}
+	c.order = c.order[n:]
+}
+
+// remove deletes cacheKey and its trace mapping; the caller updates order.
+// Callers must hold c.mu.
+func (c *idempotencyCache) remove(cacheKey string) {
+	if entry, ok := c.entries[cacheKey]; ok && c.byTrace[entry.traceID] == cacheKey {
+		delete(c.byTrace, entry.traceID)
+	}
+	delete(c.entries, cacheKey)
+}
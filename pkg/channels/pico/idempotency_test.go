@@ -0,0 +1,154 @@
+package pico
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestIdempotencyCache_DuplicateReturnsLatestReply(t *testing.T) {
+	c := newIdempotencyCache(time.Minute, 10)
+
+	if _, ok := c.begin("pico:s1", "k1", "trace-1"); !ok {
+		t.Fatal("first begin should be accepted")
+	}
+	if reply, ok := c.begin("pico:s1", "k1", "trace-2"); ok || reply != nil {
+		t.Fatalf("in-flight duplicate = (%v, %v), want (nil, false)", reply, ok)
+	}
+
+	c.recordReply("trace-1", newMessage(TypeMessageCreate, map[string]any{"content": "working"}))
+	c.recordReply("trace-1", newMessage(TypeMessageCreate, map[string]any{"content": "done"}))
+
+	reply, ok := c.begin("pico:s1", "k1", "trace-3")
+	if ok || reply == nil {
+		t.Fatalf("completed duplicate = (%v, %v), want cached reply", reply, ok)
+	}
+	if got := reply.Payload["content"]; got != "done" {
+		t.Fatalf("cached content = %v, want %q", got, "done")
+	}
+
+	// The same key in another session is a different request.
+	if _, ok := c.begin("pico:s2", "k1", "trace-4"); !ok {
+		t.Fatal("key in another session should be accepted")
+	}
+}
+
+func TestIdempotencyCache_RepliesMatchTheirOwnTurn(t *testing.T) {
+	c := newIdempotencyCache(time.Minute, 10)
+
+	// Two keyed turns and an unkeyed one overlap in the same session.
+	c.begin("pico:s1", "k1", "trace-1")
+	c.begin("pico:s1", "k2", "trace-2")
+	c.begin("pico:s1", "", "trace-3")
+	c.recordReply("trace-2", newMessage(TypeMessageCreate, map[string]any{"content": "second"}))
+	c.recordReply("trace-3", newMessage(TypeMessageCreate, map[string]any{"content": "unkeyed"}))
+	c.recordReply("trace-1", newMessage(TypeMessageCreate, map[string]any{"content": "first"}))
+	c.recordReply("", newMessage(TypeMessageCreate, map[string]any{"content": "untraced"}))
+
+	for key, want := range map[string]string{"k1": "first", "k2": "second"} {
+		reply, _ := c.begin("pico:s1", key, "retry")
+		if reply == nil || reply.Payload["content"] != want {
+			t.Errorf("cached reply for %s = %v, want %q", key, reply, want)
+		}
+	}
+}
+
+func TestIdempotencyCache_ExpiresAndStaysBounded(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := newIdempotencyCache(time.Minute, 2)
+	c.now = func() time.Time { return now }
+
+	c.begin("pico:s1", "k1", "trace-1")
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.begin("pico:s1", "k1", "trace-2"); !ok {
+		t.Fatal("expired key should be accepted again")
+	}
+
+	c.begin("pico:s1", "k2", "trace-3")
+	c.begin("pico:s1", "k3", "trace-4")
+	if len(c.entries) != 2 || len(c.byTrace) != 2 {
+		t.Fatalf("entries = %d, traces = %d, want 2 each", len(c.entries), len(c.byTrace))
+	}
+	if _, ok := c.begin("pico:s1", "k1", "trace-5"); !ok {
+		t.Fatal("evicted key should be accepted again")
+	}
+}
+
+func TestHandleMessageSend_IdempotencyKeyDedupsTurns(t *testing.T) {
+	mb := bus.NewMessageBus()
+	ch, err := NewPicoChannel(config.PicoConfig{Token: "test-token"}, mb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = ch.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer ch.Stop(ctx)
+
+	srv := httptest.NewServer(ch)
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(
+		wsURL(srv.URL)+"/pico/ws?session_id=s1",
+		http.Header{"Authorization": {"Bearer test-token"}},
+	)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	send := func() {
+		t.Helper()
+		err := conn.WriteJSON(PicoMessage{
+			Type:    TypeMessageSend,
+			Payload: map[string]any{"content": "pay 1 token", "idempotency_key": "retry-1"},
+		})
+		if err != nil {
+			t.Fatalf("WriteJSON: %v", err)
+		}
+	}
+
+	send()
+	var inbound bus.InboundMessage
+	select {
+	case inbound = <-mb.InboundChan():
+		if inbound.Content != "pay 1 token" {
+			t.Fatalf("inbound content = %q", inbound.Content)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for inbound message")
+	}
+
+	err = ch.Send(ctx, bus.OutboundMessage{ChatID: "pico:s1", Content: "paid", TraceID: inbound.TraceID})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	var first PicoMessage
+	if err = conn.ReadJSON(&first); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+
+	send()
+	var replay PicoMessage
+	if err = conn.ReadJSON(&replay); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	if replay.Type != first.Type || replay.Payload["content"] != first.Payload["content"] {
+		t.Fatalf("replayed %+v, want %+v", replay, first)
+	}
+
+	select {
+	case msg := <-mb.InboundChan():
+		t.Fatalf("duplicate request started another turn: %q", msg.Content)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
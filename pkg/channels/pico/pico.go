@@ -58,6 +58,7 @@ type PicoChannel struct {
 	upgrader    websocket.Upgrader
 	connections sync.Map // connID → *picoConn
 	connCount   atomic.Int32
+	idempotency *idempotencyCache
 	ctx         context.Context
 	cancel      context.CancelFunc
 }
@@ -92,6 +93,7 @@ func NewPicoChannel(cfg config.PicoConfig, messageBus *bus.MessageBus) (*PicoCha
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
 		},
+		idempotency: newIdempotencyCache(idempotencyTTL, idempotencyMaxEntries),
 	}, nil
 }
 
@@ -150,6 +152,7 @@ func (c *PicoChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	outMsg := newMessage(TypeMessageCreate, map[string]any{
 		"content": msg.Content,
 	})
+	c.idempotency.recordReply(msg.TraceID, outMsg)
 
 	return c.broadcastToSession(msg.ChatID, outMsg)
 }
@@ -160,6 +163,11 @@ func (c *PicoChannel) EditMessage(ctx context.Context, chatID string, messageID
 		"message_id": messageID,
 		"content":    content,
 	})
+	// A retried request never saw the placeholder, so cache the final text
+	// as a fresh message rather than an update.
+	c.idempotency.recordReply(bus.TraceIDFromContext(ctx), newMessage(TypeMessageCreate, map[string]any{
+		"content": content,
+	}))
 	return c.broadcastToSession(chatID, outMsg)
 }
 
@@ -447,6 +455,27 @@ func (c *PicoChannel) handleMessageSend(pc *picoConn, msg PicoMessage) {
 		return
 	}
 
+	model, ok := msg.Payload["model"].(string)
+	if _, present := msg.Payload["model"]; present && !ok {
+		errMsg := newError("invalid_model", "model must be a string")
+		pc.writeJSON(errMsg)
+		return
+	}
+
+	dryRun, ok := msg.Payload["dry_run"].(bool)
+	if _, present := msg.Payload["dry_run"]; present && !ok {
+		errMsg := newError("invalid_dry_run", "dry_run must be a boolean")
+		pc.writeJSON(errMsg)
+		return
+	}
+
+	idempotencyKey, ok := msg.Payload["idempotency_key"].(string)
+	if _, present := msg.Payload["idempotency_key"]; present && !ok {
+		errMsg := newError("invalid_idempotency_key", "idempotency_key must be a string")
+		pc.writeJSON(errMsg)
+		return
+	}
+
 	sessionID := msg.SessionID
 	if sessionID == "" {
 		sessionID = pc.sessionID
@@ -462,6 +491,16 @@ func (c *PicoChannel) handleMessageSend(pc *picoConn, msg PicoMessage) {
 		"session_id": sessionID,
 		"conn_id":    pc.id,
 	}
+	// An optional model_list entry to use for this message only; the agent
+	// loop validates it and replies with an error if it is unknown.
+	if model = strings.TrimSpace(model); model != "" {
+		metadata["model"] = model
+	}
+	// Dry runs let operators try prompts without tools touching anything;
+	// the reply lists the tool calls the agent would have made.
+	if dryRun {
+		metadata["dry_run"] = "true"
+	}
 
 	logger.DebugCF("pico", "Received message", map[string]any{
 		"session_id": sessionID,
@@ -478,7 +517,26 @@ func (c *PicoChannel) handleMessageSend(pc *picoConn, msg PicoMessage) {
 		return
 	}
 
-	c.HandleMessage(c.ctx, peer, msg.ID, senderID, chatID, content, nil, metadata, sender)
+	// A client retrying after a timeout resends the same idempotency_key;
+	// answer from the first request instead of starting another turn. The
+	// key is tied to this message's trace ID, which the agent's replies
+	// carry back.
+	traceID := bus.NewTraceID()
+	if reply, ok := c.idempotency.begin(chatID, strings.TrimSpace(idempotencyKey), traceID); !ok {
+		logger.DebugCF("pico", "Duplicate message ignored", map[string]any{
+			"session_id":      sessionID,
+			"idempotency_key": idempotencyKey,
+			"replayed":        reply != nil,
+		})
+		if reply != nil {
+			replay := *reply
+			replay.SessionID = sessionID
+			pc.writeJSON(replay)
+		}
+		return
+	}
+
+	c.HandleMessage(bus.WithTraceID(c.ctx, traceID), peer, msg.ID, senderID, chatID, content, nil, metadata, sender)
 }
 
 // truncate truncates a string to maxLen runes.
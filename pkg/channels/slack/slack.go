@@ -89,6 +89,10 @@ func (c *SlackChannel) Start(ctx context.Context) error {
 				})
 			}
 		}
+		// The socket client has given up; let the channel supervisor restart us.
+		if c.ctx.Err() == nil {
+			c.SetRunning(false)
+		}
 	}()
 
 	c.SetRunning(true)
@@ -4,11 +4,14 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -26,6 +29,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
@@ -51,6 +55,8 @@ type TelegramChannel struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 
+	lastSent sync.Map // chatID → ID of the last message sent (string)
+
 	registerFunc     func(context.Context, []commands.Definition) error
 	commandRegCancel context.CancelFunc
 }
@@ -206,16 +212,18 @@ func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) err
 			}
 
 			if smallerLen <= 0 {
-				if err := c.sendChunk(ctx, sendChunkParams{
+				messageID, err := c.sendChunk(ctx, sendChunkParams{
 					chatID:        chatID,
 					threadID:      threadID,
 					content:       content,
 					replyToID:     replyToID,
 					mdFallback:    chunk,
 					useMarkdownV2: useMarkdownV2,
-				}); err != nil {
+				})
+				if err != nil {
 					return err
 				}
+				c.lastSent.Store(msg.ChatID, messageID)
 				replyToID = ""
 				continue
 			}
@@ -244,16 +252,18 @@ func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) err
 			continue
 		}
 
-		if err := c.sendChunk(ctx, sendChunkParams{
+		messageID, err := c.sendChunk(ctx, sendChunkParams{
 			chatID:        chatID,
 			threadID:      threadID,
 			content:       content,
 			replyToID:     replyToID,
 			mdFallback:    chunk,
 			useMarkdownV2: useMarkdownV2,
-		}); err != nil {
+		})
+		if err != nil {
 			return err
 		}
+		c.lastSent.Store(msg.ChatID, messageID)
 		// Only the first chunk should be a reply; subsequent chunks are normal messages.
 		replyToID = ""
 	}
@@ -272,10 +282,11 @@ type sendChunkParams struct {
 
 // sendChunk sends a single HTML/MarkdownV2 message, falling back to the original
 // markdown as plain text on parse failure so users never see raw HTML/MarkdownV2 tags.
+// It returns the ID of the sent message.
 func (c *TelegramChannel) sendChunk(
 	ctx context.Context,
 	params sendChunkParams,
-) error {
+) (string, error) {
 	tgMsg := tu.Message(tu.ID(params.chatID), params.content)
 	tgMsg.MessageThreadID = params.threadID
 	if params.useMarkdownV2 {
@@ -292,17 +303,27 @@ func (c *TelegramChannel) sendChunk(
 		}
 	}
 
-	if _, err := c.bot.SendMessage(ctx, tgMsg); err != nil {
+	sent, err := c.bot.SendMessage(ctx, tgMsg)
+	if err != nil {
 		logParseFailed(err, params.useMarkdownV2)
 
 		tgMsg.Text = params.mdFallback
 		tgMsg.ParseMode = ""
-		if _, err = c.bot.SendMessage(ctx, tgMsg); err != nil {
-			return fmt.Errorf("telegram send: %w", channels.ErrTemporary)
+		if sent, err = c.bot.SendMessage(ctx, tgMsg); err != nil {
+			return "", fmt.Errorf("telegram send: %w", channels.ErrTemporary)
 		}
 	}
 
-	return nil
+	return strconv.Itoa(sent.MessageID), nil
+}
+
+// LastMessageID implements channels.LastMessageTracker.
+func (c *TelegramChannel) LastMessageID(chatID string) (string, bool) {
+	v, ok := c.lastSent.Load(chatID)
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
 }
 
 // maxTypingDuration limits how long the typing indicator can run.
@@ -419,7 +440,10 @@ func (c *TelegramChannel) SendPlaceholder(ctx context.Context, chatID string) (s
 		return "", err
 	}
 
-	return fmt.Sprintf("%d", pMsg.MessageID), nil
+	// The placeholder is edited into the reply, so it becomes the last message.
+	messageID := fmt.Sprintf("%d", pMsg.MessageID)
+	c.lastSent.Store(chatID, messageID)
+	return messageID, nil
 }
 
 // SendMedia implements the channels.MediaSender interface.
@@ -617,14 +641,27 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		}
 	}
 
-	if message.Document != nil {
-		docPath := c.downloadFile(ctx, message.Document.FileID, "")
+	// A document on the replied-to message is fetched too, so replying to an
+	// uploaded file (e.g. a SKILL.md) with an instruction hands it to the agent.
+	documents := []*telego.Document{message.Document}
+	if reply := message.ReplyToMessage; reply != nil && reply.Document != nil && message.Document == nil {
+		if _, allowed := c.replyAuthor(reply); allowed {
+			documents = append(documents, reply.Document)
+		}
+	}
+	for _, doc := range documents {
+		if doc == nil {
+			continue
+		}
+		docPath, label := c.downloadDocument(ctx, doc)
 		if docPath != "" {
-			mediaPaths = append(mediaPaths, storeMedia(docPath, "document"))
+			mediaPaths = append(mediaPaths, storeMedia(docPath, documentName(doc)))
+		}
+		if label != "" {
 			if content != "" {
 				content += "\n"
 			}
-			content += "[file]"
+			content += label
 		}
 	}
 
@@ -645,6 +682,12 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		content = cleaned
 	}
 
+	// Prepend the message being replied to so requests like "summarize this"
+	// have the quoted content to work with.
+	if author, quoted := c.quotedReply(message); quoted != "" {
+		content = fmt.Sprintf("[quoted message from %s]: %s\n\n%s", author, quoted, content)
+	}
+
 	// For forum topics, embed the thread ID as "chatID/threadID" so replies
 	// route to the correct topic and each topic gets its own session.
 	// Only forum groups (IsForum) are handled; regular group reply threads
@@ -679,6 +722,10 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		"is_group":   fmt.Sprintf("%t", message.Chat.Type != "private"),
 	}
 
+	if message.ReplyToMessage != nil {
+		metadata["reply_to_message_id"] = fmt.Sprintf("%d", message.ReplyToMessage.MessageID)
+	}
+
 	// Set parent_peer metadata for per-topic agent binding.
 	if message.Chat.IsForum && threadID != 0 {
 		metadata["parent_peer_kind"] = "topic"
@@ -698,6 +745,158 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 	return nil
 }
 
+// maxQuotedReplyLen caps how much of a replied-to message is passed to the agent.
+const maxQuotedReplyLen = 2000
+
+// quotedReply returns the author and text of the message being replied to.
+// A partial quote selected by the user takes precedence over the full text.
+// Text from authors rejected by the allowlist is dropped, except for the
+// bot's own messages.
+func (c *TelegramChannel) quotedReply(message *telego.Message) (author, text string) {
+	reply := message.ReplyToMessage
+	if reply == nil {
+		return "", ""
+	}
+
+	text = reply.Text
+	if text == "" {
+		text = reply.Caption
+	}
+	if message.Quote != nil && message.Quote.Text != "" {
+		text = message.Quote.Text
+	}
+	if text == "" {
+		return "", ""
+	}
+
+	author, allowed := c.replyAuthor(reply)
+	if !allowed {
+		return "", ""
+	}
+	return author, utils.Truncate(text, maxQuotedReplyLen)
+}
+
+// replyAuthor names the author of a replied-to message and reports whether
+// their content may be passed to the agent: the bot's own messages always
+// may, anyone else must pass the allowlist.
+func (c *TelegramChannel) replyAuthor(reply *telego.Message) (name string, allowed bool) {
+	from := reply.From
+	if from == nil {
+		return "unknown", true
+	}
+
+	name = from.FirstName
+	if from.Username != "" {
+		name = from.Username
+	}
+	if c.bot != nil && from.IsBot && strings.EqualFold(from.Username, c.bot.Username()) {
+		return name, true
+	}
+
+	platformID := fmt.Sprintf("%d", from.ID)
+	author := bus.SenderInfo{
+		Platform:    "telegram",
+		PlatformID:  platformID,
+		CanonicalID: identity.BuildCanonicalID("telegram", platformID),
+		Username:    from.Username,
+		DisplayName: from.FirstName,
+	}
+	if !c.IsAllowedSender(author) {
+		logger.DebugCF("telegram", "Replied-to message author rejected by allowlist", map[string]any{
+			"user_id": platformID,
+		})
+		return name, false
+	}
+	return name, true
+}
+
+// maxDocumentSize returns the configured document size limit.
+func (c *TelegramChannel) maxDocumentSize() int64 {
+	if c.config == nil {
+		return config.DefaultTelegramMaxDocumentSize
+	}
+	return c.config.Channels.Telegram.GetMaxDocumentSize()
+}
+
+// downloadDocument fetches doc, keeping its original file name so the agent
+// can tell e.g. a SKILL.md from a JSON file, and returns the local path with
+// the placeholder text for the message. Documents over max_document_size are
+// not downloaded, or are discarded if the reported size was wrong, and JSON
+// and SKILL.md files that do not parse are dropped; the placeholder says so
+// instead.
+func (c *TelegramChannel) downloadDocument(ctx context.Context, doc *telego.Document) (path, label string) {
+	name := documentName(doc)
+	maxSize := c.maxDocumentSize()
+	if doc.FileSize > maxSize {
+		logger.WarnCF("telegram", "Document too large to download", map[string]any{
+			"file_name": name,
+			"size":      doc.FileSize,
+			"max":       maxSize,
+		})
+		return "", fmt.Sprintf("[file: %s too large to download]", name)
+	}
+
+	file, err := c.bot.GetFile(ctx, &telego.GetFileParams{FileID: doc.FileID})
+	if err != nil {
+		logger.ErrorCF("telegram", "Failed to get file", map[string]any{
+			"error": err.Error(),
+		})
+		return "", ""
+	}
+	if file.FilePath == "" {
+		return "", ""
+	}
+
+	path = utils.DownloadFile(c.bot.FileDownloadURL(file.FilePath), name, utils.DownloadOptions{
+		LoggerPrefix: "telegram",
+		MaxBytes:     maxSize,
+	})
+	if path == "" {
+		return "", fmt.Sprintf("[file: %s could not be downloaded]", name)
+	}
+	if err := validateDocument(path, name); err != nil {
+		os.Remove(path)
+		logger.WarnCF("telegram", "Rejected invalid document", map[string]any{
+			"file_name": name,
+			"error":     err.Error(),
+		})
+		return "", fmt.Sprintf("[file: %s rejected: %v]", name, err)
+	}
+	return path, "[file]"
+}
+
+// validateDocument checks the content of document types the agent acts on:
+// JSON files must parse and a SKILL.md must carry valid skill frontmatter.
+// Other files are passed through unchecked.
+func validateDocument(path, name string) error {
+	isJSON := strings.EqualFold(filepath.Ext(name), ".json")
+	isSkill := strings.EqualFold(name, "SKILL.md")
+	if !isJSON && !isSkill {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if isJSON && !json.Valid(data) {
+		return errors.New("invalid JSON")
+	}
+	if isSkill {
+		if err := skills.ValidateSkillFile(data); err != nil {
+			return fmt.Errorf("invalid SKILL.md: %w", err)
+		}
+	}
+	return nil
+}
+
+func documentName(doc *telego.Document) string {
+	if doc.FileName != "" {
+		return doc.FileName
+	}
+	return "document"
+}
+
 func (c *TelegramChannel) downloadPhoto(ctx context.Context, fileID string) string {
 	file, err := c.bot.GetFile(ctx, &telego.GetFileParams{FileID: fileID})
 	if err != nil {
@@ -18,3 +18,10 @@ type HealthChecker interface {
 	HealthPath() string
 	HealthHandler(w http.ResponseWriter, r *http.Request)
 }
+
+// HealthReporter is an optional interface for channels whose health depends
+// on more than IsRunning, e.g. holding a valid API access token. Details are
+// included in the aggregated /health/channels response.
+type HealthReporter interface {
+	HealthDetails() (healthy bool, details map[string]any)
+}
@@ -0,0 +1,71 @@
+package channels
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// httpServer is the part of *http.Server used to start the shared server.
+type httpServer interface {
+	ListenAndServe() error
+	ListenAndServeTLS(certFile, keyFile string) error
+}
+
+// configureServerTLS prepares srv for the autocert mode of cfg. Certificate
+// files need no setup; they are passed to ListenAndServeTLS. Autocert keys
+// are cached in homeDir/autocert by default, outside the agent's workspace.
+func configureServerTLS(srv *http.Server, cfg config.GatewayTLSConfig, homeDir string) error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if len(cfg.AutocertDomains) > 0 {
+		if cfg.CertFile != "" || cfg.KeyFile != "" {
+			return fmt.Errorf("gateway.tls: set either cert_file/key_file or autocert_domains, not both")
+		}
+		cacheDir := cfg.AutocertCacheDir
+		if cacheDir == "" {
+			cacheDir = filepath.Join(homeDir, "autocert")
+		}
+		// The cache holds the account and certificate private keys.
+		if err := os.MkdirAll(cacheDir, 0o700); err != nil {
+			return fmt.Errorf("gateway.tls: create autocert cache: %w", err)
+		}
+		if err := os.Chmod(cacheDir, 0o700); err != nil {
+			return fmt.Errorf("gateway.tls: restrict autocert cache: %w", err)
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cacheDir),
+		}
+		// Certificates are obtained with the TLS-ALPN-01 challenge, so the
+		// gateway must be reachable on port 443 for these domains.
+		srv.TLSConfig = manager.TLSConfig()
+		return nil
+	}
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return fmt.Errorf("gateway.tls: cert_file and key_file must be set together")
+	}
+	srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	return nil
+}
+
+// listenAndServe starts srv over HTTPS when cfg enables TLS, else over HTTP.
+func listenAndServe(srv httpServer, cfg config.GatewayTLSConfig) error {
+	switch {
+	case len(cfg.AutocertDomains) > 0:
+		// Certificates come from the autocert TLSConfig.
+		return srv.ListenAndServeTLS("", "")
+	case cfg.Enabled():
+		return srv.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+	default:
+		return srv.ListenAndServe()
+	}
+}
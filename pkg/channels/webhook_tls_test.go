@@ -0,0 +1,148 @@
+package channels
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// stubServer records how the shared server was started.
+type stubServer struct {
+	method   string
+	certFile string
+	keyFile  string
+}
+
+func (s *stubServer) ListenAndServe() error {
+	s.method = "ListenAndServe"
+	return http.ErrServerClosed
+}
+
+func (s *stubServer) ListenAndServeTLS(certFile, keyFile string) error {
+	s.method = "ListenAndServeTLS"
+	s.certFile, s.keyFile = certFile, keyFile
+	return http.ErrServerClosed
+}
+
+func TestListenAndServe_SelectsTLSFromConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.GatewayTLSConfig
+		wantMethod string
+		wantCert   string
+		wantKey    string
+	}{
+		{
+			name:       "plain http",
+			wantMethod: "ListenAndServe",
+		},
+		{
+			name:       "certificate files",
+			cfg:        config.GatewayTLSConfig{CertFile: "/etc/picoclaw/cert.pem", KeyFile: "/etc/picoclaw/key.pem"},
+			wantMethod: "ListenAndServeTLS",
+			wantCert:   "/etc/picoclaw/cert.pem",
+			wantKey:    "/etc/picoclaw/key.pem",
+		},
+		{
+			name:       "autocert",
+			cfg:        config.GatewayTLSConfig{AutocertDomains: []string{"bot.example.com"}},
+			wantMethod: "ListenAndServeTLS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &stubServer{}
+			if err := listenAndServe(srv, tt.cfg); err != http.ErrServerClosed {
+				t.Fatalf("listenAndServe() error = %v", err)
+			}
+			if srv.method != tt.wantMethod || srv.certFile != tt.wantCert || srv.keyFile != tt.wantKey {
+				t.Errorf("started with %s(%q, %q), want %s(%q, %q)",
+					srv.method, srv.certFile, srv.keyFile, tt.wantMethod, tt.wantCert, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestConfigureServerTLS(t *testing.T) {
+	t.Run("autocert installs certificate callback", func(t *testing.T) {
+		srv := &http.Server{}
+		home := t.TempDir()
+		cfg := config.GatewayTLSConfig{AutocertDomains: []string{"bot.example.com"}}
+		if err := configureServerTLS(srv, cfg, home); err != nil {
+			t.Fatalf("configureServerTLS() error = %v", err)
+		}
+		if srv.TLSConfig == nil || srv.TLSConfig.GetCertificate == nil {
+			t.Fatal("autocert should set TLSConfig.GetCertificate")
+		}
+		info, err := os.Stat(filepath.Join(home, "autocert"))
+		if err != nil {
+			t.Fatalf("autocert cache not created under home: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o700 {
+			t.Errorf("autocert cache mode = %o, want 700", perm)
+		}
+	})
+
+	t.Run("autocert tightens an existing cache dir", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "certs")
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		cfg := config.GatewayTLSConfig{AutocertDomains: []string{"bot.example.com"}, AutocertCacheDir: dir}
+		if err := configureServerTLS(&http.Server{}, cfg, t.TempDir()); err != nil {
+			t.Fatalf("configureServerTLS() error = %v", err)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o700 {
+			t.Errorf("autocert cache mode = %o, want 700", perm)
+		}
+	})
+
+	t.Run("certificate files", func(t *testing.T) {
+		srv := &http.Server{}
+		dir := t.TempDir()
+		cfg := config.GatewayTLSConfig{
+			CertFile: filepath.Join(dir, "cert.pem"),
+			KeyFile:  filepath.Join(dir, "key.pem"),
+		}
+		if err := configureServerTLS(srv, cfg, dir); err != nil {
+			t.Fatalf("configureServerTLS() error = %v", err)
+		}
+		if srv.TLSConfig == nil {
+			t.Fatal("TLSConfig should be set")
+		}
+	})
+
+	t.Run("plain http leaves server untouched", func(t *testing.T) {
+		srv := &http.Server{}
+		if err := configureServerTLS(srv, config.GatewayTLSConfig{}, t.TempDir()); err != nil {
+			t.Fatalf("configureServerTLS() error = %v", err)
+		}
+		if srv.TLSConfig != nil {
+			t.Error("TLSConfig should stay nil without TLS config")
+		}
+	})
+
+	for name, cfg := range map[string]config.GatewayTLSConfig{
+		"cert without key": {CertFile: "cert.pem"},
+		"key without cert": {KeyFile: "key.pem"},
+		"files and autocert": {
+			CertFile:        "cert.pem",
+			KeyFile:         "key.pem",
+			AutocertDomains: []string{"bot.example.com"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := configureServerTLS(&http.Server{}, cfg, t.TempDir()); err == nil {
+				t.Error("expected a configuration error")
+			}
+		})
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -894,24 +893,7 @@ func (c *WeComAIBotChannel) encryptEmptyResponse(timestamp, nonce string) string
 
 // encryptMessage encrypts a plain text message for WeCom AI Bot
 func (c *WeComAIBotChannel) encryptMessage(plaintext, receiveid string) (string, error) {
-	aesKey, err := decodeWeComAESKey(c.config.EncodingAESKey)
-	if err != nil {
-		return "", err
-	}
-
-	frame, err := packWeComFrame(plaintext, receiveid)
-	if err != nil {
-		return "", err
-	}
-
-	// PKCS7 padding then AES-CBC encrypt
-	paddedFrame := pkcs7Pad(frame, blockSize)
-	ciphertext, err := encryptAESCBC(aesKey, paddedFrame)
-	if err != nil {
-		return "", err
-	}
-
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	return encryptWeComMessage(plaintext, c.config.EncodingAESKey, receiveid)
 }
 
 // func (c *WeComAIBotChannel) downloadAndDecryptImage(
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -28,14 +29,37 @@ const (
 	wecomAPIBase = "https://qyapi.weixin.qq.com"
 )
 
+// wecomErrInvalidMediaID is returned by the message API when a media_id is
+// unknown or its temporary storage period has expired.
+const wecomErrInvalidMediaID = 40007
+
+// weComAPIError is a non-zero errcode returned by the WeCom message API.
+type weComAPIError struct {
+	Code int
+	Msg  string
+}
+
+func (e *weComAPIError) Error() string {
+	return fmt.Sprintf("API error: %s (code: %d)", e.Msg, e.Code)
+}
+
+func isInvalidMediaIDError(err error) bool {
+	var apiErr *weComAPIError
+	return errors.As(err, &apiErr) && apiErr.Code == wecomErrInvalidMediaID
+}
+
 // WeComAppChannel implements the Channel interface for WeCom App (企业微信自建应用)
 type WeComAppChannel struct {
 	*channels.BaseChannel
-	config        config.WeComAppConfig
-	client        *http.Client
-	accessToken   string
-	tokenExpiry   time.Time
-	tokenMu       sync.RWMutex
+	config      config.WeComAppConfig
+	client      *http.Client
+	apiBase     string
+	accessToken string
+	tokenExpiry time.Time
+	tokenMu     sync.RWMutex
+	// persistTokens keeps the access token in the auth store across
+	// restarts.
+	persistTokens bool
 	ctx           context.Context
 	cancel        context.CancelFunc
 	processedMsgs *MessageDeduplicator
@@ -114,9 +138,6 @@ type WeComSendMessageResponse struct {
 	InvalidTag   string `json:"invalidtag"`
 }
 
-// PKCS7Padding adds PKCS7 padding
-type PKCS7Padding struct{}
-
 // NewWeComAppChannel creates a new WeCom App channel instance
 func NewWeComAppChannel(cfg config.WeComAppConfig, messageBus *bus.MessageBus) (*WeComAppChannel, error) {
 	if cfg.CorpID == "" || cfg.CorpSecret == "" || cfg.AgentID == 0 {
@@ -141,6 +162,7 @@ func NewWeComAppChannel(cfg config.WeComAppConfig, messageBus *bus.MessageBus) (
 		BaseChannel:   base,
 		config:        cfg,
 		client:        &http.Client{Timeout: clientTimeout},
+		apiBase:       wecomAPIBase,
 		ctx:           ctx,
 		cancel:        cancel,
 		processedMsgs: NewMessageDeduplicator(wecomMaxProcessedMessages),
@@ -162,8 +184,11 @@ func (c *WeComAppChannel) Start(ctx context.Context) error {
 	}
 	c.ctx, c.cancel = context.WithCancel(ctx)
 
-	// Get initial access token
-	if err := c.refreshAccessToken(); err != nil {
+	// Reuse the token from the previous run while it is valid; WeCom
+	// rate-limits gettoken calls.
+	if c.loadPersistedToken() {
+		logger.DebugC("wecom_app", "Reusing cached access token")
+	} else if err := c.refreshAccessToken(); err != nil {
 		logger.WarnCF("wecom_app", "Failed to get initial access token", map[string]any{
 			"error": err.Error(),
 		})
@@ -263,21 +288,28 @@ func (c *WeComAppChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMe
 			continue
 		}
 
-		// Send media message using the media_id
-		if mediaType == "image" {
-			err = c.sendImageMessage(ctx, accessToken, msg.ChatID, mediaID)
-		} else {
-			// For non-image types, send as text fallback with caption
-			caption := part.Caption
-			if caption == "" {
-				caption = fmt.Sprintf("[%s: %s]", part.Type, part.Filename)
+		err = c.sendMediaByID(ctx, accessToken, msg.ChatID, mediaType, mediaID)
+		if isInvalidMediaIDError(err) {
+			// Temporary media expires after three days and WeCom may drop it
+			// earlier; upload a fresh copy once before giving up.
+			logger.WarnCF("wecom_app", "media_id rejected, re-uploading", map[string]any{
+				"type":     mediaType,
+				"media_id": mediaID,
+			})
+			if mediaID, err = c.uploadMedia(ctx, accessToken, mediaType, localPath); err == nil {
+				err = c.sendMediaByID(ctx, accessToken, msg.ChatID, mediaType, mediaID)
 			}
-			err = c.sendTextMessage(ctx, accessToken, msg.ChatID, caption)
 		}
-
 		if err != nil {
 			return err
 		}
+
+		// WeCom media messages carry no caption field; follow up with text.
+		if part.Caption != "" {
+			if err = c.sendTextMessage(ctx, accessToken, msg.ChatID, part.Caption); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -286,7 +318,7 @@ func (c *WeComAppChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMe
 // uploadMedia uploads a local file to WeCom temporary media storage.
 func (c *WeComAppChannel) uploadMedia(ctx context.Context, accessToken, mediaType, localPath string) (string, error) {
 	apiURL := fmt.Sprintf("%s/cgi-bin/media/upload?access_token=%s&type=%s",
-		wecomAPIBase, url.QueryEscape(accessToken), url.QueryEscape(mediaType))
+		c.apiBase, url.QueryEscape(accessToken), url.QueryEscape(mediaType))
 
 	file, err := os.Open(localPath)
 	if err != nil {
@@ -352,7 +384,7 @@ func (c *WeComAppChannel) uploadMedia(ctx context.Context, accessToken, mediaTyp
 
 // sendWeComMessage marshals payload and POSTs it to the WeCom message API.
 func (c *WeComAppChannel) sendWeComMessage(ctx context.Context, accessToken string, payload any) error {
-	apiURL := fmt.Sprintf("%s/cgi-bin/message/send?access_token=%s", wecomAPIBase, accessToken)
+	apiURL := fmt.Sprintf("%s/cgi-bin/message/send?access_token=%s", c.apiBase, accessToken)
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -404,12 +436,20 @@ func (c *WeComAppChannel) sendWeComMessage(ctx context.Context, accessToken stri
 	}
 
 	if sendResp.ErrCode != 0 {
-		return fmt.Errorf("API error: %s (code: %d)", sendResp.ErrMsg, sendResp.ErrCode)
+		return &weComAPIError{Code: sendResp.ErrCode, Msg: sendResp.ErrMsg}
 	}
 
 	return nil
 }
 
+// sendMediaByID sends a previously uploaded media_id as the matching message type.
+func (c *WeComAppChannel) sendMediaByID(ctx context.Context, accessToken, userID, mediaType, mediaID string) error {
+	if mediaType == "image" {
+		return c.sendImageMessage(ctx, accessToken, userID, mediaID)
+	}
+	return c.sendMediaMessage(ctx, accessToken, userID, mediaType, mediaID)
+}
+
 // sendImageMessage sends an image message using a media_id.
 func (c *WeComAppChannel) sendImageMessage(ctx context.Context, accessToken, userID, mediaID string) error {
 	msg := WeComImageMessage{
@@ -421,6 +461,18 @@ func (c *WeComAppChannel) sendImageMessage(ctx context.Context, accessToken, use
 	return c.sendWeComMessage(ctx, accessToken, msg)
 }
 
+// sendMediaMessage sends a voice, video or file message using a media_id.
+// mediaType doubles as the msgtype and the name of the payload object.
+func (c *WeComAppChannel) sendMediaMessage(ctx context.Context, accessToken, userID, mediaType, mediaID string) error {
+	msg := map[string]any{
+		"touser":  userID,
+		"msgtype": mediaType,
+		"agentid": c.config.AgentID,
+		mediaType: map[string]string{"media_id": mediaID},
+	}
+	return c.sendWeComMessage(ctx, accessToken, msg)
+}
+
 // WebhookPath returns the path for registering on the shared HTTP server.
 func (c *WeComAppChannel) WebhookPath() string {
 	if c.config.WebhookPath != "" {
@@ -513,15 +565,13 @@ func (c *WeComAppChannel) handleVerification(ctx context.Context, w http.Respons
 	// Decrypt echostr with CorpID verification
 	// For WeCom App (自建应用), receiveid should be corp_id
 	logger.DebugCF("wecom_app", "Attempting to decrypt echostr", map[string]any{
-		"encoding_aes_key": c.config.EncodingAESKey,
-		"corp_id":          c.config.CorpID,
+		"corp_id": c.config.CorpID,
 	})
 	decryptedEchoStr, err := decryptMessageWithVerify(echostr, c.config.EncodingAESKey, c.config.CorpID)
 	if err != nil {
 		logger.ErrorCF("wecom_app", "Failed to decrypt echostr", map[string]any{
-			"error":            err.Error(),
-			"encoding_aes_key": c.config.EncodingAESKey,
-			"corp_id":          c.config.CorpID,
+			"error":   err.Error(),
+			"corp_id": c.config.CorpID,
 		})
 		http.Error(w, "Decryption failed", http.StatusInternalServerError)
 		return
@@ -667,29 +717,51 @@ func (c *WeComAppChannel) processMessage(ctx context.Context, msg WeComXMLMessag
 	c.HandleMessage(ctx, peer, messageID, senderID, chatID, content, nil, metadata, appSender)
 }
 
-// tokenRefreshLoop periodically refreshes the access token
+// tokenRefreshRetryInterval is how long tokenRefreshLoop waits after a
+// failed refresh, or when there is no valid token to schedule from.
+const tokenRefreshRetryInterval = 30 * time.Second
+
+// tokenRefreshLoop refreshes the access token when it is about to expire.
 func (c *WeComAppChannel) tokenRefreshLoop() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
+	timer := time.NewTimer(c.nextTokenRefresh(time.Now()))
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-c.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-timer.C:
+			delay := tokenRefreshRetryInterval
 			if err := c.refreshAccessToken(); err != nil {
 				logger.ErrorCF("wecom_app", "Failed to refresh access token", map[string]any{
 					"error": err.Error(),
+					"retry": delay.String(),
 				})
+			} else {
+				delay = c.nextTokenRefresh(time.Now())
 			}
+			timer.Reset(delay)
 		}
 	}
 }
 
+// nextTokenRefresh returns how long after now the token should be refreshed.
+// tokenExpiry already leaves a margin before WeCom's real expiry.
+func (c *WeComAppChannel) nextTokenRefresh(now time.Time) time.Duration {
+	c.tokenMu.RLock()
+	expiry := c.tokenExpiry
+	c.tokenMu.RUnlock()
+
+	if d := expiry.Sub(now); d > 0 {
+		return d
+	}
+	return tokenRefreshRetryInterval
+}
+
 // refreshAccessToken gets a new access token from WeCom API
 func (c *WeComAppChannel) refreshAccessToken() error {
 	apiURL := fmt.Sprintf("%s/cgi-bin/gettoken?corpid=%s&corpsecret=%s",
-		wecomAPIBase, url.QueryEscape(c.config.CorpID), url.QueryEscape(c.config.CorpSecret))
+		c.apiBase, url.QueryEscape(c.config.CorpID), url.QueryEscape(c.config.CorpSecret))
 
 	resp, err := http.Get(apiURL)
 	if err != nil {
@@ -716,6 +788,12 @@ func (c *WeComAppChannel) refreshAccessToken() error {
 	c.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn-300) * time.Second) // Refresh 5 minutes early
 	c.tokenMu.Unlock()
 
+	if err := c.persistToken(); err != nil {
+		logger.WarnCF("wecom_app", "Failed to persist access token", map[string]any{
+			"error": err.Error(),
+		})
+	}
+
 	logger.DebugC("wecom_app", "Access token refreshed successfully")
 	return nil
 }
@@ -743,6 +821,12 @@ func (c *WeComAppChannel) sendTextMessage(ctx context.Context, accessToken, user
 	return c.sendWeComMessage(ctx, accessToken, msg)
 }
 
+// HealthDetails reports whether the channel holds an unexpired access token.
+func (c *WeComAppChannel) HealthDetails() (bool, map[string]any) {
+	hasToken := c.getAccessToken() != ""
+	return hasToken, map[string]any{"has_token": hasToken}
+}
+
 // handleHealth handles health check requests
 func (c *WeComAppChannel) handleHealth(w http.ResponseWriter, r *http.Request) {
 	status := map[string]any{
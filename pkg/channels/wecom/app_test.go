@@ -13,13 +13,17 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/media"
 )
 
 // generateTestAESKeyApp generates a valid test AES key for WeCom App
@@ -733,6 +737,139 @@ func TestWeComAppAccessToken(t *testing.T) {
 	})
 }
 
+func TestWeComAppPersistedAccessToken(t *testing.T) {
+	newChannel := func(t *testing.T, tokenCalls *int) *WeComAppChannel {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/cgi-bin/gettoken" {
+				t.Errorf("unexpected API call: %s", r.URL.Path)
+			}
+			*tokenCalls++
+			fmt.Fprint(w, `{"errcode":0,"errmsg":"ok","access_token":"fresh_token","expires_in":7200}`)
+		}))
+		t.Cleanup(server.Close)
+
+		ch, err := NewWeComAppChannel(config.WeComAppConfig{
+			CorpID:     "test_corp_id",
+			CorpSecret: "test_secret",
+			AgentID:    1000002,
+		}, bus.NewMessageBus())
+		if err != nil {
+			t.Fatalf("NewWeComAppChannel: %v", err)
+		}
+		ch.apiBase = server.URL
+		ch.persistTokens = true
+		t.Setenv(config.EnvHome, t.TempDir())
+		return ch
+	}
+	writeCache := func(t *testing.T, accountID, token string, expiresAt time.Time) {
+		t.Helper()
+		err := auth.SetCredential(appTokenCredential, &auth.AuthCredential{
+			AccessToken: token,
+			AccountID:   accountID,
+			ExpiresAt:   expiresAt,
+			Provider:    appTokenCredential,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := func(t *testing.T, ch *WeComAppChannel) {
+		t.Helper()
+		if err := ch.Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		t.Cleanup(func() { ch.Stop(context.Background()) })
+	}
+
+	t.Run("valid cached token is reused", func(t *testing.T) {
+		var calls int
+		ch := newChannel(t, &calls)
+		writeCache(t, "test_corp_id/1000002", "cached_token", time.Now().Add(time.Hour))
+
+		start(t, ch)
+		if calls != 0 {
+			t.Errorf("gettoken called %d times, want 0", calls)
+		}
+		if got := ch.getAccessToken(); got != "cached_token" {
+			t.Errorf("getAccessToken() = %q, want %q", got, "cached_token")
+		}
+	})
+
+	t.Run("expired cached token is refreshed and saved", func(t *testing.T) {
+		var calls int
+		ch := newChannel(t, &calls)
+		writeCache(t, "test_corp_id/1000002", "stale_token", time.Now().Add(-time.Minute))
+
+		start(t, ch)
+		if calls != 1 {
+			t.Errorf("gettoken called %d times, want 1", calls)
+		}
+		if got := ch.getAccessToken(); got != "fresh_token" {
+			t.Errorf("getAccessToken() = %q, want %q", got, "fresh_token")
+		}
+
+		saved, err := auth.GetCredential(appTokenCredential)
+		if err != nil || saved == nil {
+			t.Fatalf("token not saved to the auth store: %v", err)
+		}
+		if saved.AccessToken != "fresh_token" || !saved.ExpiresAt.After(time.Now()) {
+			t.Errorf("saved token = %+v, want fresh_token with future expiry", saved)
+		}
+		info, err := os.Stat(filepath.Join(os.Getenv(config.EnvHome), "auth.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("auth store mode = %o, want 600", perm)
+		}
+	})
+
+	t.Run("token cached for another app is ignored", func(t *testing.T) {
+		var calls int
+		ch := newChannel(t, &calls)
+		writeCache(t, "other_corp/1000002", "other_token", time.Now().Add(time.Hour))
+
+		start(t, ch)
+		if calls != 1 || ch.getAccessToken() != "fresh_token" {
+			t.Errorf("calls = %d, token = %q; want a fresh token", calls, ch.getAccessToken())
+		}
+	})
+}
+
+func TestWeComAppNextTokenRefresh(t *testing.T) {
+	for _, expiresIn := range []int{7200, 3600} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"errcode":0,"errmsg":"ok","access_token":"tok","expires_in":%d}`, expiresIn)
+		}))
+		ch, err := NewWeComAppChannel(config.WeComAppConfig{
+			CorpID:     "test_corp_id",
+			CorpSecret: "test_secret",
+			AgentID:    1000002,
+		}, bus.NewMessageBus())
+		if err != nil {
+			t.Fatalf("NewWeComAppChannel: %v", err)
+		}
+		ch.apiBase = server.URL
+
+		if got := ch.nextTokenRefresh(time.Now()); got != tokenRefreshRetryInterval {
+			t.Errorf("without a token, next refresh = %v, want retry interval %v", got, tokenRefreshRetryInterval)
+		}
+
+		if err := ch.refreshAccessToken(); err != nil {
+			t.Fatalf("refreshAccessToken: %v", err)
+		}
+		server.Close()
+
+		// The refresh is due 5 minutes before WeCom's expiry.
+		want := time.Duration(expiresIn-300) * time.Second
+		got := ch.nextTokenRefresh(time.Now())
+		if got > want || got < want-5*time.Second {
+			t.Errorf("expires_in=%d: next refresh in %v, want about %v", expiresIn, got, want)
+		}
+	}
+}
+
 func TestWeComAppMessageStructures(t *testing.T) {
 	t.Run("WeComTextMessage structure", func(t *testing.T) {
 		msg := WeComTextMessage{
@@ -1067,3 +1204,172 @@ func TestWeComAppXMLMessageEvent(t *testing.T) {
 		t.Errorf("EventKey = %q, want %q", msg.EventKey, "event_key_123")
 	}
 }
+
+// wecomAPIRecorder is a fake WeCom API server that records uploads and sends.
+type wecomAPIRecorder struct {
+	server       *httptest.Server
+	uploadTypes  []string
+	sent         []map[string]any
+	sendErrCodes []int // errcode returned per send call; missing entries mean success
+}
+
+func newWeComAPIRecorder(t *testing.T) *wecomAPIRecorder {
+	t.Helper()
+	rec := &wecomAPIRecorder{}
+	rec.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cgi-bin/media/upload":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("upload is not multipart: %v", err)
+			}
+			rec.uploadTypes = append(rec.uploadTypes, r.URL.Query().Get("type"))
+			fmt.Fprintf(w, `{"errcode":0,"errmsg":"ok","media_id":"MEDIA_%d"}`, len(rec.uploadTypes))
+		case "/cgi-bin/message/send":
+			var payload map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("send body is not JSON: %v", err)
+			}
+			idx := len(rec.sent)
+			rec.sent = append(rec.sent, payload)
+			code := 0
+			if idx < len(rec.sendErrCodes) {
+				code = rec.sendErrCodes[idx]
+			}
+			fmt.Fprintf(w, `{"errcode":%d,"errmsg":"test"}`, code)
+		default:
+			t.Errorf("unexpected API call: %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(rec.server.Close)
+	return rec
+}
+
+// newRunningWeComAppWithMedia returns a running channel pointed at rec with a
+// media store holding a single file of the given name.
+func newRunningWeComAppWithMedia(t *testing.T, rec *wecomAPIRecorder, filename string) (*WeComAppChannel, string) {
+	t.Helper()
+	ch, err := NewWeComAppChannel(config.WeComAppConfig{
+		CorpID:     "test_corp_id",
+		CorpSecret: "test_secret",
+		AgentID:    1000002,
+	}, bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("NewWeComAppChannel: %v", err)
+	}
+	ch.apiBase = rec.server.URL
+	ch.accessToken = "test_token"
+	ch.tokenExpiry = time.Now().Add(time.Hour)
+	ch.SetRunning(true)
+
+	store := media.NewFileMediaStore()
+	ch.SetMediaStore(store)
+	localPath := filepath.Join(t.TempDir(), filename)
+	if err := os.WriteFile(localPath, []byte("fake-content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := store.Store(localPath, media.MediaMeta{Filename: filename}, "scope-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch, ref
+}
+
+func TestWeComAppSendMedia_FileUploadAndSend(t *testing.T) {
+	rec := newWeComAPIRecorder(t)
+	ch, ref := newRunningWeComAppWithMedia(t, rec, "report.pdf")
+
+	err := ch.SendMedia(context.Background(), bus.OutboundMediaMessage{
+		ChatID: "user123",
+		Parts:  []bus.MediaPart{{Type: "file", Ref: ref, Caption: "monthly report"}},
+	})
+	if err != nil {
+		t.Fatalf("SendMedia() error = %v", err)
+	}
+
+	if len(rec.uploadTypes) != 1 || rec.uploadTypes[0] != "file" {
+		t.Fatalf("upload types = %v, want [file]", rec.uploadTypes)
+	}
+	if len(rec.sent) != 2 {
+		t.Fatalf("sent %d messages, want file + caption", len(rec.sent))
+	}
+	if rec.sent[0]["msgtype"] != "file" {
+		t.Errorf("first msgtype = %v, want file", rec.sent[0]["msgtype"])
+	}
+	file, _ := rec.sent[0]["file"].(map[string]any)
+	if file["media_id"] != "MEDIA_1" {
+		t.Errorf("file.media_id = %v, want MEDIA_1", file["media_id"])
+	}
+	if rec.sent[1]["msgtype"] != "text" {
+		t.Errorf("caption msgtype = %v, want text", rec.sent[1]["msgtype"])
+	}
+}
+
+func TestWeComAppSendMedia_ImageUsesMediaID(t *testing.T) {
+	rec := newWeComAPIRecorder(t)
+	ch, ref := newRunningWeComAppWithMedia(t, rec, "chart.png")
+
+	err := ch.SendMedia(context.Background(), bus.OutboundMediaMessage{
+		ChatID: "user123",
+		Parts:  []bus.MediaPart{{Type: "image", Ref: ref}},
+	})
+	if err != nil {
+		t.Fatalf("SendMedia() error = %v", err)
+	}
+
+	if len(rec.uploadTypes) != 1 || rec.uploadTypes[0] != "image" {
+		t.Fatalf("upload types = %v, want [image]", rec.uploadTypes)
+	}
+	if len(rec.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(rec.sent))
+	}
+	if rec.sent[0]["msgtype"] != "image" || rec.sent[0]["touser"] != "user123" {
+		t.Errorf("unexpected payload: %v", rec.sent[0])
+	}
+	image, _ := rec.sent[0]["image"].(map[string]any)
+	if image["media_id"] != "MEDIA_1" {
+		t.Errorf("image.media_id = %v, want MEDIA_1", image["media_id"])
+	}
+}
+
+func TestWeComAppSendMedia_ReuploadsExpiredMediaID(t *testing.T) {
+	rec := newWeComAPIRecorder(t)
+	rec.sendErrCodes = []int{wecomErrInvalidMediaID}
+	ch, ref := newRunningWeComAppWithMedia(t, rec, "chart.png")
+
+	err := ch.SendMedia(context.Background(), bus.OutboundMediaMessage{
+		ChatID: "user123",
+		Parts:  []bus.MediaPart{{Type: "image", Ref: ref}},
+	})
+	if err != nil {
+		t.Fatalf("SendMedia() error = %v", err)
+	}
+
+	if len(rec.uploadTypes) != 2 {
+		t.Fatalf("uploads = %d, want 2 (original + re-upload)", len(rec.uploadTypes))
+	}
+	if len(rec.sent) != 2 {
+		t.Fatalf("sends = %d, want 2", len(rec.sent))
+	}
+	image, _ := rec.sent[1]["image"].(map[string]any)
+	if image["media_id"] != "MEDIA_2" {
+		t.Errorf("retry media_id = %v, want MEDIA_2", image["media_id"])
+	}
+}
+
+func TestWeComAppSendMedia_APIErrorIsReturned(t *testing.T) {
+	rec := newWeComAPIRecorder(t)
+	rec.sendErrCodes = []int{60020}
+	ch, ref := newRunningWeComAppWithMedia(t, rec, "chart.png")
+
+	err := ch.SendMedia(context.Background(), bus.OutboundMediaMessage{
+		ChatID: "user123",
+		Parts:  []bus.MediaPart{{Type: "image", Ref: ref}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "code: 60020") {
+		t.Fatalf("SendMedia() error = %v, want API error with code 60020", err)
+	}
+	if len(rec.uploadTypes) != 1 {
+		t.Errorf("uploads = %d, want 1 (no re-upload for unrelated errors)", len(rec.uploadTypes))
+	}
+}
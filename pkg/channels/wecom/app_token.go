@@ -0,0 +1,65 @@
+package wecom
+
+import (
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/auth"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// appTokenCredential is the auth store entry ($PICOCLAW_HOME/auth.json)
+// holding the WeCom App access token. Its AccountID records the corp and
+// agent IDs so a token is not reused after the app changes.
+const appTokenCredential = "wecom_app"
+
+func (c *WeComAppChannel) tokenAccountID() string {
+	return fmt.Sprintf("%s/%d", c.config.CorpID, c.config.AgentID)
+}
+
+// loadPersistedToken restores the access token saved by a previous run. It
+// returns false when there is no cached token for this app or it expired.
+func (c *WeComAppChannel) loadPersistedToken() bool {
+	if !c.persistTokens {
+		return false
+	}
+	cred, err := auth.GetCredential(appTokenCredential)
+	if err != nil {
+		logger.WarnCF("wecom_app", "Failed to read cached access token", map[string]any{
+			"error": err.Error(),
+		})
+		return false
+	}
+	if cred == nil || cred.AccountID != c.tokenAccountID() ||
+		cred.AccessToken == "" || cred.ExpiresAt.IsZero() || cred.IsExpired() {
+		return false
+	}
+
+	c.tokenMu.Lock()
+	c.accessToken = cred.AccessToken
+	c.tokenExpiry = cred.ExpiresAt
+	c.tokenMu.Unlock()
+	return true
+}
+
+// persistToken saves the current access token so a restart can reuse it
+// instead of requesting a new one.
+func (c *WeComAppChannel) persistToken() error {
+	if !c.persistTokens {
+		return nil
+	}
+
+	c.tokenMu.RLock()
+	cred := &auth.AuthCredential{
+		AccessToken: c.accessToken,
+		AccountID:   c.tokenAccountID(),
+		ExpiresAt:   c.tokenExpiry,
+		Provider:    appTokenCredential,
+		AuthMethod:  "corp_secret",
+	}
+	c.tokenMu.RUnlock()
+
+	if err := auth.SetCredential(appTokenCredential, cred); err != nil {
+		return fmt.Errorf("failed to save access token: %w", err)
+	}
+	return nil
+}
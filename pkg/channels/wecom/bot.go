@@ -28,6 +28,7 @@ type WeComBotChannel struct {
 	ctx           context.Context
 	cancel        context.CancelFunc
 	processedMsgs *MessageDeduplicator
+	passive       *passiveReplies
 }
 
 // WeComBotMessage represents the JSON message structure from WeCom Bot (AIBOT)
@@ -107,6 +108,7 @@ func NewWeComBotChannel(cfg config.WeComConfig, messageBus *bus.MessageBus) (*We
 		ctx:           ctx,
 		cancel:        cancel,
 		processedMsgs: NewMessageDeduplicator(wecomMaxProcessedMessages),
+		passive:       newPassiveReplies(),
 	}, nil
 }
 
@@ -146,12 +148,21 @@ func (c *WeComBotChannel) Stop(ctx context.Context) error {
 
 // Send sends a message to WeCom user via webhook API
 // Note: WeCom Bot can only reply within the configured timeout (default 5 seconds) of receiving a message
-// For delayed responses, we use the webhook URL
+// With passive_reply enabled, a reply produced in that window is returned in the
+// callback response; delayed responses use the webhook URL
 func (c *WeComBotChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	if !c.IsRunning() {
 		return channels.ErrNotRunning
 	}
 
+	if c.passive.deliver(msg) {
+		logger.DebugCF("wecom", "Sending message as passive reply", map[string]any{
+			"chat_id": msg.ChatID,
+			"preview": utils.Truncate(msg.Content, 100),
+		})
+		return nil
+	}
+
 	logger.DebugCF("wecom", "Sending message via webhook", map[string]any{
 		"chat_id": msg.ChatID,
 		"preview": utils.Truncate(msg.Content, 100),
@@ -306,6 +317,11 @@ func (c *WeComBotChannel) handleMessageCallback(ctx context.Context, w http.Resp
 		return
 	}
 
+	if c.config.PassiveReply && c.config.EncodingAESKey != "" {
+		c.replyPassively(w, msg, timestamp, nonce)
+		return
+	}
+
 	// Process the message with the channel's long-lived context (not the HTTP
 	// request context, which is canceled as soon as we return the response).
 	go c.processMessage(c.ctx, msg)
@@ -315,15 +331,72 @@ func (c *WeComBotChannel) handleMessageCallback(ctx context.Context, w http.Resp
 	w.Write([]byte("success"))
 }
 
-// processMessage processes the received message
-func (c *WeComBotChannel) processMessage(ctx context.Context, msg WeComBotMessage) {
+// replyPassively hands msg to the agent and waits, within the reply timeout,
+// for the final response to this message. The response is returned
+// encrypted in the callback response; if none arrives in time WeCom gets
+// "success" and the response is sent later via the webhook URL.
+func (c *WeComBotChannel) replyPassively(w http.ResponseWriter, msg WeComBotMessage, timestamp, nonce string) {
+	traceID := bus.NewTraceID()
+	reply := c.passive.register(traceID)
+
+	var content string
+	var delivered bool
+	if c.processMessage(bus.WithTraceID(c.ctx, traceID), msg) {
+		timer := time.NewTimer(c.passiveReplyWait())
+		select {
+		case content = <-reply:
+			delivered = true
+		case <-timer.C:
+		case <-c.ctx.Done():
+		}
+		timer.Stop()
+	}
+
+	c.passive.release(traceID, reply)
+	if !delivered {
+		// Send may have delivered between the timeout and release.
+		select {
+		case content = <-reply:
+			delivered = true
+		default:
+		}
+	}
+	if !delivered {
+		w.Write([]byte("success"))
+		return
+	}
+
+	body, err := c.buildPassiveReply(content, timestamp, nonce)
+	if err != nil {
+		logger.ErrorCF("wecom", "Failed to build passive reply", map[string]any{
+			"error": err.Error(),
+		})
+		w.Write([]byte("success"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(body)
+}
+
+// botChatID returns the chat a message belongs to: the group ChatID for
+// group chats, otherwise the sender's user ID.
+func botChatID(msg WeComBotMessage) string {
+	if msg.ChatType == "group" {
+		return msg.ChatID
+	}
+	return msg.From.UserID
+}
+
+// processMessage processes the received message and reports whether it was
+// handed to the agent
+func (c *WeComBotChannel) processMessage(ctx context.Context, msg WeComBotMessage) bool {
 	// Skip unsupported message types
 	if msg.MsgType != "text" && msg.MsgType != "image" && msg.MsgType != "voice" && msg.MsgType != "file" &&
 		msg.MsgType != "mixed" {
 		logger.DebugCF("wecom", "Skipping non-supported message type", map[string]any{
 			"msg_type": msg.MsgType,
 		})
-		return
+		return false
 	}
 
 	// Message deduplication: Use msg_id to prevent duplicate processing
@@ -332,7 +405,7 @@ func (c *WeComBotChannel) processMessage(ctx context.Context, msg WeComBotMessag
 		logger.DebugCF("wecom", "Skipping duplicate message", map[string]any{
 			"msg_id": msgID,
 		})
-		return
+		return false
 	}
 
 	senderID := msg.From.UserID
@@ -380,7 +453,7 @@ func (c *WeComBotChannel) processMessage(ctx context.Context, msg WeComBotMessag
 	if isGroupChat {
 		respond, cleaned := c.ShouldRespondInGroup(false, content)
 		if !respond {
-			return
+			return false
 		}
 		content = cleaned
 	}
@@ -412,11 +485,12 @@ func (c *WeComBotChannel) processMessage(ctx context.Context, msg WeComBotMessag
 	}
 
 	if !c.IsAllowedSender(sender) {
-		return
+		return false
 	}
 
 	// Handle the message through the base channel
 	c.HandleMessage(ctx, peer, msg.MsgID, senderID, chatID, content, nil, metadata, sender)
+	return true
 }
 
 // sendWebhookReply sends a reply using the webhook URL
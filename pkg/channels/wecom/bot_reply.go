@@ -0,0 +1,120 @@
+package wecom
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// passiveReplyMargin is kept back from the reply timeout so the encrypted
+// reply is written before WeCom gives up on the callback.
+const passiveReplyMargin = 500 * time.Millisecond
+
+// cdataString marshals as an XML CDATA section, as WeCom expects in replies.
+type cdataString struct {
+	Value string `xml:",cdata"`
+}
+
+// WeComBotPassiveReply is the encrypted XML body returned in the HTTP
+// response to a message callback (passive reply mode).
+type WeComBotPassiveReply struct {
+	XMLName      xml.Name    `xml:"xml"`
+	Encrypt      cdataString `xml:"Encrypt"`
+	MsgSignature cdataString `xml:"MsgSignature"`
+	TimeStamp    string      `xml:"TimeStamp"`
+	Nonce        cdataString `xml:"Nonce"`
+}
+
+// passiveReplies hands a turn's final response to the callback request still
+// waiting to answer it. Slots are keyed by the trace ID of the inbound
+// message, so replies to other messages in the same chat, and progress or
+// tool output from the same turn, never take a callback's slot.
+type passiveReplies struct {
+	mu      sync.Mutex
+	waiting map[string]chan string
+}
+
+func newPassiveReplies() *passiveReplies {
+	return &passiveReplies{waiting: make(map[string]chan string)}
+}
+
+// register reserves the passive reply slot for the turn with traceID.
+func (p *passiveReplies) register(traceID string) chan string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	reply := make(chan string, 1)
+	p.waiting[traceID] = reply
+	return reply
+}
+
+// release frees the slot reserved by register, if it is still held.
+func (p *passiveReplies) release(traceID string, reply chan string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.waiting[traceID] == reply {
+		delete(p.waiting, traceID)
+	}
+}
+
+// deliver passes msg to the callback waiting for its turn and reports
+// whether one took it. Only final responses are delivered.
+func (p *passiveReplies) deliver(msg bus.OutboundMessage) bool {
+	if !msg.Final || msg.TraceID == "" {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	reply, ok := p.waiting[msg.TraceID]
+	if !ok {
+		return false
+	}
+	delete(p.waiting, msg.TraceID)
+	reply <- msg.Content
+	return true
+}
+
+// passiveReplyWait returns how long a callback waits for the agent's reply.
+func (c *WeComBotChannel) passiveReplyWait() time.Duration {
+	timeout := c.config.ReplyTimeout
+	if timeout <= 0 {
+		timeout = 5
+	}
+	wait := time.Duration(timeout)*time.Second - passiveReplyMargin
+	if wait < passiveReplyMargin {
+		wait = passiveReplyMargin
+	}
+	return wait
+}
+
+// buildPassiveReply encrypts content as a text WeComBotReplyMessage and wraps
+// it in the signed XML envelope for the callback response.
+func (c *WeComBotChannel) buildPassiveReply(content, timestamp, nonce string) ([]byte, error) {
+	reply := WeComBotReplyMessage{MsgType: "text"}
+	reply.Text.Content = content
+
+	jsonData, err := json.Marshal(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal reply: %w", err)
+	}
+
+	// For AIBOT (智能机器人), receiveid is the empty string
+	encrypted, err := encryptWeComMessage(string(jsonData), c.config.EncodingAESKey, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt reply: %w", err)
+	}
+
+	return xml.Marshal(WeComBotPassiveReply{
+		Encrypt:      cdataString{encrypted},
+		MsgSignature: cdataString{computeSignature(c.config.Token, timestamp, nonce, encrypted)},
+		TimeStamp:    timestamp,
+		Nonce:        cdataString{nonce},
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"sort"
@@ -748,3 +749,199 @@ func TestWeComBotMessageStructure(t *testing.T) {
 		t.Errorf("Text.Content = %q, want %q", msg.Text.Content, "Hello World")
 	}
 }
+
+func TestWeComBotBuildPassiveReply(t *testing.T) {
+	aesKey := generateTestAESKey()
+	ch, _ := NewWeComBotChannel(config.WeComConfig{
+		Token:          "test_token",
+		EncodingAESKey: aesKey,
+		WebhookURL:     "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=test",
+	}, bus.NewMessageBus())
+
+	body, err := ch.buildPassiveReply("Hello back", "1234567890", "test_nonce")
+	if err != nil {
+		t.Fatalf("buildPassiveReply() error = %v", err)
+	}
+	if !bytes.Contains(body, []byte("<Encrypt><![CDATA[")) {
+		t.Errorf("reply should wrap Encrypt in CDATA, got: %s", body)
+	}
+	assertPassiveReply(t, body, "test_token", aesKey, "1234567890", "test_nonce", "Hello back")
+}
+
+func TestWeComBotPassiveReply(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	aesKey := generateTestAESKey()
+	ch, _ := NewWeComBotChannel(config.WeComConfig{
+		Token:          "test_token",
+		EncodingAESKey: aesKey,
+		WebhookURL:     "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=test",
+		PassiveReply:   true,
+		ReplyTimeout:   5,
+	}, msgBus)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := ch.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer ch.Stop(ctx)
+
+	go func() {
+		inbound := <-msgBus.InboundChan()
+		ch.Send(ctx, bus.OutboundMessage{
+			ChatID:  inbound.ChatID,
+			Content: "echo: " + inbound.Content,
+			TraceID: inbound.TraceID,
+			Final:   true,
+		})
+	}()
+
+	encrypted, _ := encryptTestMessage(`{
+		"msgid": "passive_msg_1",
+		"chattype": "single",
+		"from": {"userid": "user123"},
+		"msgtype": "text",
+		"text": {"content": "Hello"}
+	}`, aesKey)
+	w := postEncryptedCallback(t, "test_token", encrypted, ch.handleMessageCallback)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", w.Code, http.StatusOK)
+	}
+	assertPassiveReply(t, w.Body.Bytes(), "test_token", aesKey, "1234567890", "test_nonce", "echo: Hello")
+}
+
+func TestWeComBotPassiveReplyTakesOnlyItsTurnsFinalResponse(t *testing.T) {
+	var webhookBodies []string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		webhookBodies = append(webhookBodies, string(body))
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer webhook.Close()
+
+	msgBus := bus.NewMessageBus()
+	aesKey := generateTestAESKey()
+	ch, _ := NewWeComBotChannel(config.WeComConfig{
+		Token:          "test_token",
+		EncodingAESKey: aesKey,
+		WebhookURL:     webhook.URL,
+		PassiveReply:   true,
+		ReplyTimeout:   5,
+	}, msgBus)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := ch.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer ch.Stop(ctx)
+
+	go func() {
+		inbound := <-msgBus.InboundChan()
+		// Progress from this turn and the final response of another turn in
+		// the same chat must not take the callback's slot.
+		ch.Send(ctx, bus.OutboundMessage{ChatID: inbound.ChatID, Content: "thinking", TraceID: inbound.TraceID})
+		ch.Send(ctx, bus.OutboundMessage{ChatID: inbound.ChatID, Content: "other turn", TraceID: "other", Final: true})
+		ch.Send(ctx, bus.OutboundMessage{
+			ChatID:  inbound.ChatID,
+			Content: "answer",
+			TraceID: inbound.TraceID,
+			Final:   true,
+		})
+	}()
+
+	encrypted, _ := encryptTestMessage(`{
+		"msgid": "passive_msg_3",
+		"chattype": "single",
+		"from": {"userid": "user123"},
+		"msgtype": "text",
+		"text": {"content": "Hello"}
+	}`, aesKey)
+	w := postEncryptedCallback(t, "test_token", encrypted, ch.handleMessageCallback)
+
+	assertPassiveReply(t, w.Body.Bytes(), "test_token", aesKey, "1234567890", "test_nonce", "answer")
+	if len(webhookBodies) != 2 {
+		t.Fatalf("webhook calls = %d, want 2", len(webhookBodies))
+	}
+	if !strings.Contains(webhookBodies[0], "thinking") || !strings.Contains(webhookBodies[1], "other turn") {
+		t.Errorf("webhook bodies = %q, want the progress and the other turn's reply", webhookBodies)
+	}
+}
+
+func TestWeComBotPassiveReplyTimeoutFallsBackToWebhook(t *testing.T) {
+	var webhookCalls int
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhookCalls++
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer webhook.Close()
+
+	msgBus := bus.NewMessageBus()
+	aesKey := generateTestAESKey()
+	ch, _ := NewWeComBotChannel(config.WeComConfig{
+		Token:          "test_token",
+		EncodingAESKey: aesKey,
+		WebhookURL:     webhook.URL,
+		PassiveReply:   true,
+		ReplyTimeout:   1,
+	}, msgBus)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := ch.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer ch.Stop(ctx)
+
+	encrypted, _ := encryptTestMessage(`{
+		"msgid": "passive_msg_2",
+		"chattype": "single",
+		"from": {"userid": "user123"},
+		"msgtype": "text",
+		"text": {"content": "Hello"}
+	}`, aesKey)
+	w := postEncryptedCallback(t, "test_token", encrypted, ch.handleMessageCallback)
+	if w.Body.String() != "success" {
+		t.Fatalf("response body = %q, want %q", w.Body.String(), "success")
+	}
+
+	<-msgBus.InboundChan()
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: "user123", Content: "late"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if webhookCalls != 1 {
+		t.Errorf("webhook calls = %d, want 1", webhookCalls)
+	}
+}
+
+// assertPassiveReply verifies the signature of an encrypted XML reply and
+// checks that it decrypts to a text reply with the wanted content.
+func assertPassiveReply(t *testing.T, body []byte, token, aesKey, timestamp, nonce, want string) {
+	t.Helper()
+
+	var reply struct {
+		Encrypt      string `xml:"Encrypt"`
+		MsgSignature string `xml:"MsgSignature"`
+		TimeStamp    string `xml:"TimeStamp"`
+		Nonce        string `xml:"Nonce"`
+	}
+	if err := xml.Unmarshal(body, &reply); err != nil {
+		t.Fatalf("reply is not XML: %v (body %q)", err, body)
+	}
+	if reply.TimeStamp != timestamp || reply.Nonce != nonce {
+		t.Errorf("TimeStamp/Nonce = %q/%q, want %q/%q", reply.TimeStamp, reply.Nonce, timestamp, nonce)
+	}
+	if !verifySignature(token, reply.MsgSignature, reply.TimeStamp, reply.Nonce, reply.Encrypt) {
+		t.Error("reply signature does not verify")
+	}
+
+	decrypted, err := decryptMessageWithVerify(reply.Encrypt, aesKey, "")
+	if err != nil {
+		t.Fatalf("failed to decrypt reply: %v", err)
+	}
+	var msg WeComBotReplyMessage
+	if err := json.Unmarshal([]byte(decrypted), &msg); err != nil {
+		t.Fatalf("decrypted reply is not JSON: %v", err)
+	}
+	if msg.MsgType != "text" || msg.Text.Content != want {
+		t.Errorf("decrypted reply = %+v, want text %q", msg, want)
+	}
+}
@@ -100,6 +100,27 @@ func encryptAESCBC(aesKey, plaintext []byte) ([]byte, error) {
 	return ciphertext, nil
 }
 
+// encryptWeComMessage packs msg into the WeCom frame, pads it to 32 bytes and
+// encrypts it, returning the base64 ciphertext used in Encrypt fields.
+func encryptWeComMessage(msg, encodingAESKey, receiveid string) (string, error) {
+	aesKey, err := decodeWeComAESKey(encodingAESKey)
+	if err != nil {
+		return "", err
+	}
+
+	frame, err := packWeComFrame(msg, receiveid)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := encryptAESCBC(aesKey, pkcs7Pad(frame, blockSize))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
 // packWeComFrame builds the WeCom wire format:
 //
 //	random(16 ASCII digits) + msg_len(4, big-endian) + msg + receiveid
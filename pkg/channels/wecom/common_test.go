@@ -0,0 +1,211 @@
+package wecom
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// encryptWeComFrame32 encrypts message the way the WeCom servers do, padding
+// the frame to 32-byte blocks. The returned padding length is reported so
+// tests can require more than aes.BlockSize bytes of padding.
+func encryptWeComFrame32(t *testing.T, message, encodingAESKey, receiveID string) (string, int) {
+	t.Helper()
+	key, err := decodeWeComAESKey(encodingAESKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := packWeComFrame(message, receiveID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padding := blockSize - len(frame)%blockSize
+	cipherText, err := encryptAESCBC(key, pkcs7Pad(frame, blockSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(cipherText), padding
+}
+
+// padTo32ByteBlockTail appends filler to content until a WeCom frame built
+// from wrap(content) needs more padding than a 16-byte block could hold.
+func padTo32ByteBlockTail(content string, receiveID string, wrap func(string) string) string {
+	for {
+		frameLen := 20 + len(wrap(content)) + len(receiveID)
+		if blockSize-frameLen%blockSize > aes.BlockSize {
+			return content
+		}
+		content += "."
+	}
+}
+
+// postEncryptedCallback signs encrypted and posts it to handler the way the
+// WeCom servers deliver message callbacks.
+func postEncryptedCallback(
+	t *testing.T,
+	token, encrypted string,
+	handler func(context.Context, http.ResponseWriter, *http.Request),
+) *httptest.ResponseRecorder {
+	t.Helper()
+	wrapper, _ := xml.Marshal(struct {
+		XMLName xml.Name `xml:"xml"`
+		Encrypt string   `xml:"Encrypt"`
+	}{Encrypt: encrypted})
+	timestamp, nonce := "1234567890", "test_nonce"
+	signature := computeSignature(token, timestamp, nonce, encrypted)
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/webhook?msg_signature="+signature+"&timestamp="+timestamp+"&nonce="+nonce,
+		bytes.NewReader(wrapper),
+	)
+	w := httptest.NewRecorder()
+	handler(context.Background(), w, req)
+	return w
+}
+
+func TestDecryptMessageWithVerify_32BytePadding(t *testing.T) {
+	aesKey := generateTestAESKeyApp()
+	message := padTo32ByteBlockTail("hello", "corp", func(s string) string { return s })
+
+	encrypted, padding := encryptWeComFrame32(t, message, aesKey, "corp")
+	if padding <= aes.BlockSize {
+		t.Fatalf("test frame padding = %d, want more than %d", padding, aes.BlockSize)
+	}
+
+	got, err := decryptMessageWithVerify(encrypted, aesKey, "corp")
+	if err != nil {
+		t.Fatalf("decryptMessageWithVerify() error = %v", err)
+	}
+	if got != message {
+		t.Errorf("decrypted = %q, want %q", got, message)
+	}
+
+	if _, err := decryptMessageWithVerify(encrypted, aesKey, "other_corp"); err == nil {
+		t.Error("expected receiveid mismatch error")
+	}
+}
+
+func TestWeComChannelsDecrypt32BytePaddedCallbacks(t *testing.T) {
+	aesKey := generateTestAESKeyApp()
+
+	t.Run("app", func(t *testing.T) {
+		ch, err := NewWeComAppChannel(config.WeComAppConfig{
+			CorpID:         "test_corp_id",
+			CorpSecret:     "test_secret",
+			AgentID:        1000002,
+			Token:          "test_token",
+			EncodingAESKey: aesKey,
+		}, bus.NewMessageBus())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wrap := func(content string) string {
+			data, _ := xml.Marshal(WeComXMLMessage{
+				FromUserName: "user123",
+				MsgType:      "text",
+				Content:      content,
+				MsgId:        1,
+				AgentID:      1000002,
+			})
+			return string(data)
+		}
+		content := padTo32ByteBlockTail("Hello", "test_corp_id", wrap)
+		encrypted, _ := encryptWeComFrame32(t, wrap(content), aesKey, "test_corp_id")
+
+		w := postEncryptedCallback(t, "test_token", encrypted, ch.handleMessageCallback)
+		if w.Code != http.StatusOK || w.Body.String() != "success" {
+			t.Errorf("callback = %d %q, want 200 \"success\"", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("bot", func(t *testing.T) {
+		ch, err := NewWeComBotChannel(config.WeComConfig{
+			Token:          "test_token",
+			EncodingAESKey: aesKey,
+			WebhookURL:     "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=test",
+		}, bus.NewMessageBus())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wrap := func(content string) string {
+			return `{"msgid":"m1","chattype":"single","from":{"userid":"user123"},` +
+				`"msgtype":"text","text":{"content":"` + content + `"}}`
+		}
+		content := padTo32ByteBlockTail("Hello", "", wrap)
+		encrypted, _ := encryptWeComFrame32(t, wrap(content), aesKey, "")
+
+		w := postEncryptedCallback(t, "test_token", encrypted, ch.handleMessageCallback)
+		if w.Code != http.StatusOK || w.Body.String() != "success" {
+			t.Errorf("callback = %d %q, want 200 \"success\"", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestDecryptMessageWithVerify_EveryPaddingLength(t *testing.T) {
+	aesKey := generateTestAESKeyApp()
+	key, err := decodeWeComAESKey(aesKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cover every frame length modulo 32, so each possible padding length
+	// is exercised for both 32-byte (WeCom) and 16-byte block padding.
+	for n := 0; n < 2*blockSize; n++ {
+		message := strings.Repeat("a", n)
+		frame, err := packWeComFrame(message, "corp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, padBlock := range []int{blockSize, aes.BlockSize} {
+			cipherText, err := encryptAESCBC(key, pkcs7Pad(append([]byte(nil), frame...), padBlock))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := decryptMessageWithVerify(base64.StdEncoding.EncodeToString(cipherText), aesKey, "corp")
+			if err != nil {
+				t.Fatalf("len %d, %d-byte padding: %v", n, padBlock, err)
+			}
+			if got != message {
+				t.Fatalf("len %d, %d-byte padding: decrypted %q", n, padBlock, got)
+			}
+		}
+	}
+}
+
+func TestPKCS7Unpad(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr bool
+	}{
+		{name: "full 32-byte block", data: bytes.Repeat([]byte{32}, 32), want: []byte{}},
+		{name: "17 bytes of padding", data: append([]byte("abc"), bytes.Repeat([]byte{17}, 17)...), want: []byte("abc")},
+		{name: "padding above block size", data: bytes.Repeat([]byte{33}, 64), wantErr: true},
+		{name: "zero padding", data: []byte{'a', 0}, wantErr: true},
+		{name: "inconsistent padding bytes", data: []byte{'a', 1, 3, 3}, wantErr: true},
+		{name: "padding longer than data", data: []byte{5, 5}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pkcs7Unpad(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pkcs7Unpad() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, tt.want) {
+				t.Errorf("pkcs7Unpad() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,7 +11,12 @@ func init() {
 		return NewWeComBotChannel(cfg.Channels.WeCom, b)
 	})
 	channels.RegisterFactory("wecom_app", func(cfg *config.Config, b *bus.MessageBus) (channels.Channel, error) {
-		return NewWeComAppChannel(cfg.Channels.WeComApp, b)
+		ch, err := NewWeComAppChannel(cfg.Channels.WeComApp, b)
+		if err != nil {
+			return nil, err
+		}
+		ch.persistTokens = true
+		return ch, nil
 	})
 	channels.RegisterFactory("wecom_aibot", func(cfg *config.Config, b *bus.MessageBus) (channels.Channel, error) {
 		return NewWeComAIBotChannel(cfg.Channels.WeComAIBot, b)
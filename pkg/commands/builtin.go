@@ -14,5 +14,7 @@ func BuiltinDefinitions() []Definition {
 		checkCommand(),
 		clearCommand(),
 		reloadCommand(),
+		cancelCommand(),
+		retryCommand(),
 	}
 }
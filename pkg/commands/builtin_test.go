@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -39,7 +40,7 @@ func TestBuiltinHelpHandler_ReturnsFormattedMessage(t *testing.T) {
 	if !strings.Contains(reply, "/show [model|channel|agents]") {
 		t.Fatalf("/help reply missing /show usage, got %q", reply)
 	}
-	if !strings.Contains(reply, "/list [models|channels|agents]") {
+	if !strings.Contains(reply, "/list [models|channels|agents|capabilities]") {
 		t.Fatalf("/help reply missing /list usage, got %q", reply)
 	}
 }
@@ -143,3 +144,123 @@ func TestBuiltinListAgents_RestoresOldBehavior(t *testing.T) {
 		t.Fatalf("/list agents reply=%q, want agent IDs", reply)
 	}
 }
+
+func TestBuiltinHelp_ListsEveryRegisteredCommand(t *testing.T) {
+	defs := BuiltinDefinitions()
+	help := formatHelpMessage(defs)
+	lines := strings.Split(help, "\n")
+	if len(lines) != len(defs) {
+		t.Fatalf("help has %d lines, want one per command (%d):\n%s", len(lines), len(defs), help)
+	}
+
+	for i, def := range defs {
+		if def.Description == "" {
+			t.Errorf("/%s has no description", def.Name)
+		}
+		usage := def.EffectiveUsage()
+		if !strings.HasPrefix(usage, "/"+def.Name) {
+			t.Errorf("/%s usage %q does not start with the command name", def.Name, usage)
+		}
+		want := usage + " - " + def.Description
+		if lines[i] != want {
+			t.Errorf("help line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestBuiltinHelp_OnlyMentionsRegisteredCommands(t *testing.T) {
+	defs := BuiltinDefinitions()
+	registry := NewRegistry(defs)
+
+	for _, line := range strings.Split(formatHelpMessage(defs), "\n") {
+		name, _, _ := strings.Cut(strings.TrimPrefix(line, "/"), " ")
+		if _, ok := registry.Lookup(name); !ok {
+			t.Errorf("help mentions unregistered command %q", name)
+		}
+	}
+}
+
+func TestBuiltinCancel_Replies(t *testing.T) {
+	reg := NewRegistry(BuiltinDefinitions())
+
+	run := func(text string, rt *Runtime) string {
+		t.Helper()
+		var reply string
+		res := NewExecutor(reg, rt).Execute(context.Background(), Request{
+			Text: text,
+			Reply: func(s string) error {
+				reply = s
+				return nil
+			},
+		})
+		if res.Outcome != OutcomeHandled || res.Command != "cancel" {
+			t.Fatalf("%s: outcome=%v command=%q, want handled cancel", text, res.Outcome, res.Command)
+		}
+		return reply
+	}
+
+	active := true
+	rt := &Runtime{CancelTurn: func() bool {
+		was := active
+		active = false
+		return was
+	}}
+	if got := run("/cancel", rt); got != "Cancelled." {
+		t.Fatalf("/cancel reply = %q", got)
+	}
+	if got := run("/stop", rt); got != "Nothing to cancel." {
+		t.Fatalf("/stop reply = %q", got)
+	}
+	if got := run("/cancel", nil); got != unavailableMsg {
+		t.Fatalf("/cancel without runtime = %q", got)
+	}
+}
+
+func TestBuiltinRetry_Replies(t *testing.T) {
+	reg := NewRegistry(BuiltinDefinitions())
+
+	run := func(rt *Runtime) ExecuteResult {
+		t.Helper()
+		res := NewExecutor(reg, rt).Execute(context.Background(), Request{
+			Text:  "/retry",
+			Reply: func(string) error { return nil },
+		})
+		if res.Outcome != OutcomeHandled || res.Command != "retry" {
+			t.Fatalf("outcome=%v command=%q, want handled retry", res.Outcome, res.Command)
+		}
+		return res
+	}
+	reply := func(rt *Runtime) string {
+		t.Helper()
+		var got string
+		NewExecutor(reg, rt).Execute(context.Background(), Request{
+			Text: "/retry",
+			Reply: func(s string) error {
+				got = s
+				return nil
+			},
+		})
+		return got
+	}
+
+	rt := &Runtime{RetryLast: func(context.Context) (string, bool, error) {
+		return "second attempt", true, nil
+	}}
+	if got := reply(rt); got != "second attempt" {
+		t.Fatalf("/retry reply = %q, want the agent's answer", got)
+	}
+
+	rt.RetryLast = func(context.Context) (string, bool, error) { return "", false, nil }
+	if got := reply(rt); !strings.Contains(got, "Nothing to retry") {
+		t.Fatalf("/retry with no stored message = %q", got)
+	}
+
+	rt.RetryLast = func(context.Context) (string, bool, error) { return "", true, errors.New("provider down") }
+	if res := run(rt); res.Err == nil {
+		t.Fatal("expected the retry error to be returned")
+	}
+
+	if got := reply(nil); got != unavailableMsg {
+		t.Fatalf("/retry without runtime = %q", got)
+	}
+}
@@ -0,0 +1,21 @@
+package commands
+
+import "context"
+
+func cancelCommand() Definition {
+	return Definition{
+		Name:        "cancel",
+		Description: "Stop the reply in progress",
+		Usage:       "/cancel",
+		Aliases:     []string{"stop"},
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.CancelTurn == nil {
+				return req.Reply(unavailableMsg)
+			}
+			if !rt.CancelTurn() {
+				return req.Reply("Nothing to cancel.")
+			}
+			return req.Reply("Cancelled.")
+		},
+	}
+}
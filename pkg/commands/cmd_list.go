@@ -47,6 +47,16 @@ func listCommand() Definition {
 				Description: "Registered agents",
 				Handler:     agentsHandler(),
 			},
+			{
+				Name:        "capabilities",
+				Description: "Available tools and skills",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ListCapabilities == nil {
+						return req.Reply(unavailableMsg)
+					}
+					return req.Reply(rt.ListCapabilities())
+				},
+			},
 		},
 	}
 }
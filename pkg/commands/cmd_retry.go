@@ -0,0 +1,24 @@
+package commands
+
+import "context"
+
+func retryCommand() Definition {
+	return Definition{
+		Name:        "retry",
+		Description: "Run your last message again",
+		Usage:       "/retry",
+		Handler: func(ctx context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.RetryLast == nil {
+				return req.Reply(unavailableMsg)
+			}
+			reply, found, err := rt.RetryLast(ctx)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return req.Reply("Nothing to retry yet. Send a message first.")
+			}
+			return req.Reply(reply)
+		},
+	}
+}
@@ -50,6 +50,12 @@ func trimCommandPrefix(token string) (string, bool) {
 	return "", false
 }
 
+// CommandName returns the normalized command name of input, e.g. "cancel"
+// for "/cancel@mybot", and false when input is not a command.
+func CommandName(input string) (string, bool) {
+	return parseCommandName(input)
+}
+
 // HasCommandPrefix returns true if the input starts with a recognized
 // command prefix (e.g. "/" or "!").
 func HasCommandPrefix(input string) bool {
@@ -83,3 +83,40 @@ func TestShowListHandlers_ListHandledOnAllChannels(t *testing.T) {
 		t.Fatalf("whatsapp /list reply=%q, expected enabled channels content", reply)
 	}
 }
+
+func TestShowListHandlers_ListCapabilities(t *testing.T) {
+	rt := &Runtime{
+		ListCapabilities: func() string {
+			return "Tools (1):\n- `read_file` - Read a file"
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+
+	var reply string
+	res := ex.Execute(context.Background(), Request{
+		Channel: "slack",
+		Text:    "/list capabilities",
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	})
+	if res.Outcome != OutcomeHandled {
+		t.Fatalf("/list capabilities outcome=%v, want=%v", res.Outcome, OutcomeHandled)
+	}
+	if !strings.Contains(reply, "read_file") {
+		t.Fatalf("/list capabilities reply=%q, want it to list read_file", reply)
+	}
+
+	ex = NewExecutor(NewRegistry(BuiltinDefinitions()), &Runtime{})
+	ex.Execute(context.Background(), Request{
+		Text: "/list capabilities",
+		Reply: func(text string) error {
+			reply = text
+			return nil
+		},
+	})
+	if reply != unavailableMsg {
+		t.Fatalf("reply without runtime support=%q, want=%q", reply, unavailableMsg)
+	}
+}
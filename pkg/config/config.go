@@ -12,6 +12,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/credential"
 	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
 // rrCounter is a global counter for round-robin load balancing across models.
@@ -88,6 +89,7 @@ type Config struct {
 	Heartbeat HeartbeatConfig `json:"heartbeat"`
 	Devices   DevicesConfig   `json:"devices"`
 	Voice     VoiceConfig     `json:"voice"`
+	Logging   LoggingConfig   `json:"logging,omitempty"`
 	// BuildInfo contains build-time version information
 	BuildInfo BuildInfo `json:"build_info,omitempty"`
 }
@@ -177,6 +179,19 @@ type AgentConfig struct {
 	Model     *AgentModelConfig `json:"model,omitempty"`
 	Skills    []string          `json:"skills,omitempty"`
 	Subagents *SubagentsConfig  `json:"subagents,omitempty"`
+	// MaxToolIterations overrides agents.defaults.max_tool_iterations for
+	// this agent when positive.
+	MaxToolIterations int `json:"max_tool_iterations,omitempty"`
+	// SystemPrompt and SystemPromptFile add agent-specific instructions to
+	// the shared system prompt. A relative file path is resolved against
+	// the agent's workspace.
+	SystemPrompt     string `json:"system_prompt,omitempty"`
+	SystemPromptFile string `json:"system_prompt_file,omitempty"`
+	// ToolsAllow, when set, limits the agent to the listed tools; ToolsDeny
+	// removes tools from whatever is allowed. Entries are tool names or
+	// glob patterns such as "mcp_*".
+	ToolsAllow []string `json:"tools_allow,omitempty"`
+	ToolsDeny  []string `json:"tools_deny,omitempty"`
 }
 
 type SubagentsConfig struct {
@@ -227,6 +242,15 @@ type ToolFeedbackConfig struct {
 	MaxArgsLength int  `json:"max_args_length" env:"PICOCLAW_AGENTS_DEFAULTS_TOOL_FEEDBACK_MAX_ARGS_LENGTH"`
 }
 
+// EvalLogConfig controls sampled logging of model prompts and responses to
+// <workspace>/logs/eval/<model>.jsonl for offline evaluation. Secrets, email
+// addresses and phone numbers are redacted before writing.
+type EvalLogConfig struct {
+	Enabled     bool    `json:"enabled"                 env:"PICOCLAW_AGENTS_DEFAULTS_EVAL_LOG_ENABLED"`
+	SampleRate  float64 `json:"sample_rate,omitempty"   env:"PICOCLAW_AGENTS_DEFAULTS_EVAL_LOG_SAMPLE_RATE"`   // 0..1, 0 = 0.1
+	MaxFileSize int64   `json:"max_file_size,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_EVAL_LOG_MAX_FILE_SIZE"` // bytes per file before rotation, 0 = 10 MB
+}
+
 type AgentDefaults struct {
 	Workspace                 string             `json:"workspace"                       env:"PICOCLAW_AGENTS_DEFAULTS_WORKSPACE"`
 	RestrictToWorkspace       bool               `json:"restrict_to_workspace"           env:"PICOCLAW_AGENTS_DEFAULTS_RESTRICT_TO_WORKSPACE"`
@@ -242,9 +266,17 @@ type AgentDefaults struct {
 	MaxToolIterations         int                `json:"max_tool_iterations"             env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
 	SummarizeMessageThreshold int                `json:"summarize_message_threshold"     env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARIZE_MESSAGE_THRESHOLD"`
 	SummarizeTokenPercent     int                `json:"summarize_token_percent"         env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARIZE_TOKEN_PERCENT"`
+	MaxHistoryTurns           int                `json:"max_history_turns,omitempty"     env:"PICOCLAW_AGENTS_DEFAULTS_MAX_HISTORY_TURNS"`
 	MaxMediaSize              int                `json:"max_media_size,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_MAX_MEDIA_SIZE"`
 	Routing                   *RoutingConfig     `json:"routing,omitempty"`
 	ToolFeedback              ToolFeedbackConfig `json:"tool_feedback,omitempty"`
+	EvalLog                   EvalLogConfig      `json:"eval_log,omitempty"`
+
+	// MaxQueuedTurns and MaxQueuedTurnsPerChannel bound how many messages
+	// may wait while a turn is in progress; excess messages get a busy
+	// reply. 0 means unlimited.
+	MaxQueuedTurns           int `json:"max_queued_turns,omitempty"             env:"PICOCLAW_AGENTS_DEFAULTS_MAX_QUEUED_TURNS"`
+	MaxQueuedTurnsPerChannel int `json:"max_queued_turns_per_channel,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_MAX_QUEUED_TURNS_PER_CHANNEL"`
 }
 
 const (
@@ -345,6 +377,19 @@ type TelegramConfig struct {
 	Streaming          StreamingConfig     `json:"streaming,omitempty"`
 	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_TELEGRAM_REASONING_CHANNEL_ID"`
 	UseMarkdownV2      bool                `json:"use_markdown_v2"         env:"PICOCLAW_CHANNELS_TELEGRAM_USE_MARKDOWN_V2"`
+	MaxDocumentSize    int64               `json:"max_document_size,omitempty" env:"PICOCLAW_CHANNELS_TELEGRAM_MAX_DOCUMENT_SIZE"` // bytes, 0 = 20 MB
+}
+
+// DefaultTelegramMaxDocumentSize matches the Bot API's getFile limit.
+const DefaultTelegramMaxDocumentSize = 20 * 1024 * 1024
+
+// GetMaxDocumentSize returns the largest document, in bytes, the channel
+// downloads.
+func (c *TelegramConfig) GetMaxDocumentSize() int64 {
+	if c.MaxDocumentSize > 0 {
+		return c.MaxDocumentSize
+	}
+	return DefaultTelegramMaxDocumentSize
 }
 
 type FeishuConfig struct {
@@ -464,6 +509,7 @@ type WeComConfig struct {
 	WebhookPath        string              `json:"webhook_path"            env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_PATH"`
 	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_WECOM_ALLOW_FROM"`
 	ReplyTimeout       int                 `json:"reply_timeout"           env:"PICOCLAW_CHANNELS_WECOM_REPLY_TIMEOUT"`
+	PassiveReply       bool                `json:"passive_reply"           env:"PICOCLAW_CHANNELS_WECOM_PASSIVE_REPLY"`
 	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
 	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_WECOM_REASONING_CHANNEL_ID"`
 }
@@ -555,6 +601,13 @@ type VoiceConfig struct {
 	EchoTranscription bool `json:"echo_transcription" env:"PICOCLAW_VOICE_ECHO_TRANSCRIPTION"`
 }
 
+// LoggingConfig controls console log verbosity and rendering.
+// Empty values keep the built-in defaults (INFO level, text format).
+type LoggingConfig struct {
+	Level  string `json:"level,omitempty"  env:"PICOCLAW_LOGGING_LEVEL"`  // debug, info, warn, error, fatal
+	Format string `json:"format,omitempty" env:"PICOCLAW_LOGGING_FORMAT"` // text or json
+}
+
 type ProvidersConfig struct {
 	Anthropic     ProviderConfig       `json:"anthropic"`
 	OpenAI        OpenAIProviderConfig `json:"openai"`
@@ -666,6 +719,17 @@ type ModelConfig struct {
 	MaxTokensField string `json:"max_tokens_field,omitempty"` // Field name for max tokens (e.g., "max_completion_tokens")
 	RequestTimeout int    `json:"request_timeout,omitempty"`
 	ThinkingLevel  string `json:"thinking_level,omitempty"` // Extended thinking: off|low|medium|high|xhigh|adaptive
+	APIVersion     string `json:"api_version,omitempty"`    // Azure OpenAI api-version query parameter
+
+	// Vision reports whether the model accepts image input. Unset means yes;
+	// set it to false for text-only models so image attachments are left out
+	// of the request instead of causing an API error.
+	Vision *bool `json:"vision,omitempty"`
+}
+
+// SupportsVision reports whether images may be sent to the model.
+func (c *ModelConfig) SupportsVision() bool {
+	return c.Vision == nil || *c.Vision
 }
 
 // Validate checks if the ModelConfig has all required fields.
@@ -676,6 +740,11 @@ func (c *ModelConfig) Validate() error {
 	if c.Model == "" {
 		return fmt.Errorf("model is required")
 	}
+	switch c.ConnectMode {
+	case "", "stdio", "grpc":
+	default:
+		return fmt.Errorf("connect_mode %q is invalid (want \"stdio\" or \"grpc\")", c.ConnectMode)
+	}
 	return nil
 }
 
@@ -683,6 +752,65 @@ type GatewayConfig struct {
 	Host      string `json:"host"       env:"PICOCLAW_GATEWAY_HOST"`
 	Port      int    `json:"port"       env:"PICOCLAW_GATEWAY_PORT"`
 	HotReload bool   `json:"hot_reload" env:"PICOCLAW_GATEWAY_HOT_RELOAD"`
+	Metrics   bool   `json:"metrics"    env:"PICOCLAW_GATEWAY_METRICS"` // serve Prometheus metrics on /metrics
+	// TLS serves the gateway, including channel webhooks, over HTTPS.
+	TLS GatewayTLSConfig `json:"tls,omitempty"`
+	// Delivery controls retries for outbound channel messages.
+	Delivery GatewayDeliveryConfig `json:"delivery,omitempty"`
+	// APITokens maps each bearer token accepted by the gateway's HTTP API
+	// to its rate limits.
+	APITokens map[string]GatewayTokenLimits `json:"api_tokens,omitempty"`
+	// Bus sizes the internal message queues and sets what happens when
+	// the inbound queue fills up.
+	Bus GatewayBusConfig `json:"bus,omitempty"`
+}
+
+// GatewayBusConfig configures the message bus between channels and agents.
+// Policy applies to the inbound queue and is "block" (default),
+// "drop_oldest" or "reject"; outbound queues always block. BufferSize
+// defaults to 64 messages per queue.
+type GatewayBusConfig struct {
+	BufferSize int    `json:"buffer_size,omitempty" env:"PICOCLAW_GATEWAY_BUS_BUFFER_SIZE"`
+	Policy     string `json:"policy,omitempty"      env:"PICOCLAW_GATEWAY_BUS_POLICY"`
+}
+
+// GatewayTokenLimits caps requests made with one API token. Zero means no
+// limit. The per-day window resets at midnight UTC.
+type GatewayTokenLimits struct {
+	PerMinute int `json:"per_minute,omitempty"`
+	PerDay    int `json:"per_day,omitempty"`
+}
+
+// GatewayDeliveryConfig controls how outbound channel messages are split and
+// how failed sends are retried. Messages that still fail are appended to
+// DeadLetterFile (default $PICOCLAW_HOME/channels/dead_letter.jsonl, outside
+// the agent's workspace) so they are not lost.
+type GatewayDeliveryConfig struct {
+	// MaxRetries is how often a failed send is retried; unset uses the
+	// default of 3 and 0 disables retries.
+	MaxRetries     *int   `json:"max_retries,omitempty"      env:"PICOCLAW_GATEWAY_DELIVERY_MAX_RETRIES"`
+	DeadLetterFile string `json:"dead_letter_file,omitempty" env:"PICOCLAW_GATEWAY_DELIVERY_DEAD_LETTER_FILE"`
+	// DeadLetterMaxSize is the size in bytes at which the dead-letter file
+	// is rotated to "<file>.1" (0 = 10 MB).
+	DeadLetterMaxSize int64 `json:"dead_letter_max_size,omitempty" env:"PICOCLAW_GATEWAY_DELIVERY_DEAD_LETTER_MAX_SIZE"`
+	// MaxMessageLength overrides, per channel name, the length in runes at
+	// which outbound messages are split.
+	MaxMessageLength map[string]int `json:"max_message_length,omitempty"`
+}
+
+// GatewayTLSConfig enables HTTPS on the gateway's shared HTTP server, either
+// from a certificate/key pair or with certificates obtained from Let's
+// Encrypt for AutocertDomains.
+type GatewayTLSConfig struct {
+	CertFile         string   `json:"cert_file,omitempty"          env:"PICOCLAW_GATEWAY_TLS_CERT_FILE"`
+	KeyFile          string   `json:"key_file,omitempty"           env:"PICOCLAW_GATEWAY_TLS_KEY_FILE"`
+	AutocertDomains  []string `json:"autocert_domains,omitempty"   env:"PICOCLAW_GATEWAY_TLS_AUTOCERT_DOMAINS"`
+	AutocertCacheDir string   `json:"autocert_cache_dir,omitempty" env:"PICOCLAW_GATEWAY_TLS_AUTOCERT_CACHE_DIR"`
+}
+
+// Enabled reports whether HTTPS is configured.
+func (c GatewayTLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || len(c.AutocertDomains) > 0
 }
 
 type ToolDiscoveryConfig struct {
@@ -774,7 +902,8 @@ type ExecConfig struct {
 	AllowRemote         bool     `                                 env:"PICOCLAW_TOOLS_EXEC_ALLOW_REMOTE"          json:"allow_remote"`
 	CustomDenyPatterns  []string `                                 env:"PICOCLAW_TOOLS_EXEC_CUSTOM_DENY_PATTERNS"  json:"custom_deny_patterns"`
 	CustomAllowPatterns []string `                                 env:"PICOCLAW_TOOLS_EXEC_CUSTOM_ALLOW_PATTERNS" json:"custom_allow_patterns"`
-	TimeoutSeconds      int      `                                 env:"PICOCLAW_TOOLS_EXEC_TIMEOUT_SECONDS"       json:"timeout_seconds"` // 0 means use default (60s)
+	TimeoutSeconds      int      `                                 env:"PICOCLAW_TOOLS_EXEC_TIMEOUT_SECONDS"       json:"timeout_seconds"`  // 0 means use default (60s)
+	AllowedBinaries     []string `                                 env:"PICOCLAW_TOOLS_EXEC_ALLOWED_BINARIES"      json:"allowed_binaries"` // empty means any binary
 }
 
 type SkillsToolsConfig struct {
@@ -796,6 +925,16 @@ type ReadFileToolConfig struct {
 	MaxReadFileSize int  `json:"max_read_file_size"`
 }
 
+// TailLogToolConfig configures the tail_log tool. It is meant for operators,
+// so besides internal channels only the chats in AllowChats may use it.
+type TailLogToolConfig struct {
+	ToolConfig `envPrefix:"PICOCLAW_TOOLS_TAIL_LOG_"`
+	// AllowChats lists "channel:chat_id" pairs allowed to call the tool.
+	AllowChats []string `json:"allow_chats,omitempty" env:"PICOCLAW_TOOLS_TAIL_LOG_ALLOW_CHATS"`
+	// MaxBytes caps how much of the end of the file is read (0 = 16 KB).
+	MaxBytes int `json:"max_bytes,omitempty" env:"PICOCLAW_TOOLS_TAIL_LOG_MAX_BYTES"`
+}
+
 type ToolsConfig struct {
 	AllowReadPaths  []string           `json:"allow_read_paths"  env:"PICOCLAW_TOOLS_ALLOW_READ_PATHS"`
 	AllowWritePaths []string           `json:"allow_write_paths" env:"PICOCLAW_TOOLS_ALLOW_WRITE_PATHS"`
@@ -805,7 +944,9 @@ type ToolsConfig struct {
 	Skills          SkillsToolsConfig  `json:"skills"`
 	MediaCleanup    MediaCleanupConfig `json:"media_cleanup"`
 	MCP             MCPConfig          `json:"mcp"`
+	AuditLog        bool               `json:"audit_log,omitempty" env:"PICOCLAW_TOOLS_AUDIT_LOG"`
 	AppendFile      ToolConfig         `json:"append_file"                                              envPrefix:"PICOCLAW_TOOLS_APPEND_FILE_"`
+	Capabilities    ToolConfig         `json:"capabilities"                                             envPrefix:"PICOCLAW_TOOLS_CAPABILITIES_"`
 	EditFile        ToolConfig         `json:"edit_file"                                                envPrefix:"PICOCLAW_TOOLS_EDIT_FILE_"`
 	FindSkills      ToolConfig         `json:"find_skills"                                              envPrefix:"PICOCLAW_TOOLS_FIND_SKILLS_"`
 	I2C             ToolConfig         `json:"i2c"                                                      envPrefix:"PICOCLAW_TOOLS_I2C_"`
@@ -818,6 +959,7 @@ type ToolsConfig struct {
 	SpawnStatus     ToolConfig         `json:"spawn_status"                                             envPrefix:"PICOCLAW_TOOLS_SPAWN_STATUS_"`
 	SPI             ToolConfig         `json:"spi"                                                      envPrefix:"PICOCLAW_TOOLS_SPI_"`
 	Subagent        ToolConfig         `json:"subagent"                                                 envPrefix:"PICOCLAW_TOOLS_SUBAGENT_"`
+	TailLog         TailLogToolConfig  `json:"tail_log"`
 	WebFetch        ToolConfig         `json:"web_fetch"                                                envPrefix:"PICOCLAW_TOOLS_WEB_FETCH_"`
 	WriteFile       ToolConfig         `json:"write_file"                                               envPrefix:"PICOCLAW_TOOLS_WRITE_FILE_"`
 }
@@ -834,12 +976,19 @@ type SkillsRegistriesConfig struct {
 type SkillsGithubConfig struct {
 	Token string `json:"token,omitempty" env:"PICOCLAW_TOOLS_SKILLS_GITHUB_AUTH_TOKEN"`
 	Proxy string `json:"proxy,omitempty" env:"PICOCLAW_TOOLS_SKILLS_GITHUB_PROXY"`
+	// AllowedDomains restricts skill file and registry archive downloads to
+	// these domains and their subdomains. Empty allows any public host.
+	AllowedDomains []string `json:"allowed_domains,omitempty" env:"PICOCLAW_TOOLS_SKILLS_GITHUB_ALLOWED_DOMAINS"`
+	// MaxDownloadSize caps each downloaded skill file in bytes (0 = 10 MB);
+	// registry archives are capped by it too when it is below max_zip_size.
+	MaxDownloadSize int64 `json:"max_download_size,omitempty" env:"PICOCLAW_TOOLS_SKILLS_GITHUB_MAX_DOWNLOAD_SIZE"`
 }
 
 type ClawHubRegistryConfig struct {
 	Enabled         bool   `json:"enabled"           env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_ENABLED"`
 	BaseURL         string `json:"base_url"          env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_BASE_URL"`
 	AuthToken       string `json:"auth_token"        env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_AUTH_TOKEN"`
+	AuthHeader      string `json:"auth_header"       env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_AUTH_HEADER"`
 	SearchPath      string `json:"search_path"       env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_SEARCH_PATH"`
 	SkillsPath      string `json:"skills_path"       env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_SKILLS_PATH"`
 	DownloadPath    string `json:"download_path"     env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_DOWNLOAD_PATH"`
@@ -886,7 +1035,7 @@ func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return cfg, nil
+			return loadConfigFromEnv(cfg, filepath.Dir(path))
 		}
 		return nil, err
 	}
@@ -901,7 +1050,8 @@ func LoadConfig(path string) (*Config, error) {
 	if err := json.Unmarshal(data, &tmp); err != nil {
 		return nil, err
 	}
-	if len(tmp.ModelList) > 0 {
+	explicitModelList := len(tmp.ModelList) > 0
+	if explicitModelList {
 		cfg.ModelList = nil
 	}
 
@@ -923,6 +1073,12 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
+	fromEnv, err := applyModelListEnv(cfg, explicitModelList)
+	if err != nil {
+		return nil, err
+	}
+	explicitModelList = explicitModelList || fromEnv
+
 	if err := resolveAPIKeys(cfg.ModelList, filepath.Dir(path)); err != nil {
 		return nil, err
 	}
@@ -933,9 +1089,15 @@ func LoadConfig(path string) (*Config, error) {
 	// Migrate legacy channel config fields to new unified structures
 	cfg.migrateChannelConfigs()
 
-	// Auto-migrate: if only legacy providers config exists, convert to model_list
-	if len(cfg.ModelList) == 0 && cfg.HasProvidersConfig() {
-		cfg.ModelList = ConvertProvidersToModelList(cfg)
+	// Auto-migrate: if only legacy providers config exists, convert to model_list.
+	// The built-in template list from DefaultConfig does not count as user
+	// configuration, so it is replaced rather than shadowing the providers.
+	if !explicitModelList && cfg.HasProvidersConfig() {
+		if converted := ConvertProvidersToModelList(cfg); len(converted) > 0 {
+			cfg.ModelList = converted
+			logger.InfoCF("config", "Converted legacy providers config to model_list",
+				map[string]any{"models": len(converted)})
+		}
 	}
 
 	// Inherit credentials from providers to model_list entries (#1635).
@@ -955,6 +1117,83 @@ func LoadConfig(path string) (*Config, error) {
 	return cfg, nil
 }
 
+// loadConfigFromEnv completes cfg (a DefaultConfig) when there is no config
+// file, so environment overrides and PICOCLAW_MODEL_LIST still apply.
+func loadConfigFromEnv(cfg *Config, configDir string) (*Config, error) {
+	if err := env.Parse(cfg); err != nil {
+		return nil, err
+	}
+	if _, err := applyModelListEnv(cfg, false); err != nil {
+		return nil, err
+	}
+	if err := resolveAPIKeys(cfg.ModelList, configDir); err != nil {
+		return nil, err
+	}
+	cfg.ModelList = ExpandMultiKeyModels(cfg.ModelList)
+	if err := cfg.ValidateModelList(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// modelListEnvVar holds a JSON array of model_list entries. When the config
+// file sets model_list, entries replace every file entry with the same
+// model_name and the rest are appended; otherwise they replace the built-in
+// template list entirely.
+const modelListEnvVar = "PICOCLAW_MODEL_LIST"
+
+// applyModelListEnv merges model_list entries from PICOCLAW_MODEL_LIST into
+// cfg. ModelConfig has no per-field env tags, so this is the only way to set
+// model_list without a config file (e.g. in containers). explicit reports
+// whether cfg.ModelList came from the user; if not, it is discarded. It
+// returns whether any entries were read from the environment.
+func applyModelListEnv(cfg *Config, explicit bool) (bool, error) {
+	raw, ok := os.LookupEnv(modelListEnvVar)
+	if !ok || strings.TrimSpace(raw) == "" {
+		return false, nil
+	}
+
+	var models []ModelConfig
+	if err := json.Unmarshal([]byte(raw), &models); err != nil {
+		return false, fmt.Errorf("invalid %s: %w", modelListEnvVar, err)
+	}
+	if !explicit {
+		cfg.ModelList = nil
+	}
+
+	// Entries sharing a model_name are load-balanced, so an env name
+	// replaces all of them, at the position of the first.
+	byName := make(map[string][]ModelConfig, len(models))
+	var order []string
+	for _, m := range models {
+		if _, seen := byName[m.ModelName]; !seen {
+			order = append(order, m.ModelName)
+		}
+		byName[m.ModelName] = append(byName[m.ModelName], m)
+	}
+
+	merged := make([]ModelConfig, 0, len(cfg.ModelList)+len(models))
+	placed := make(map[string]bool, len(byName))
+	for _, m := range cfg.ModelList {
+		replacement, ok := byName[m.ModelName]
+		if !ok {
+			merged = append(merged, m)
+			continue
+		}
+		if !placed[m.ModelName] {
+			merged = append(merged, replacement...)
+			placed[m.ModelName] = true
+		}
+	}
+	for _, name := range order {
+		if !placed[name] {
+			merged = append(merged, byName[name]...)
+		}
+	}
+	cfg.ModelList = merged
+	return true, nil
+}
+
 // encryptPlaintextAPIKeys returns a copy of models with plaintext api_key values
 // encrypted. Returns (nil, nil) when nothing changed (all keys already sealed or
 // empty). Returns (nil, error) if any key fails to encrypt — callers must treat
@@ -1141,16 +1380,43 @@ func (c *Config) HasProvidersConfig() bool {
 
 // ValidateModelList validates all ModelConfig entries in the model_list.
 // It checks that each model config is valid.
-// Note: Multiple entries with the same model_name are allowed for load balancing.
+// Note: Multiple entries with the same model_name are allowed for load balancing,
+// but they must share a protocol so any of them can serve a request.
 func (c *Config) ValidateModelList() error {
+	firstByName := make(map[string]int, len(c.ModelList))
 	for i := range c.ModelList {
-		if err := c.ModelList[i].Validate(); err != nil {
+		m := &c.ModelList[i]
+		if err := m.Validate(); err != nil {
 			return fmt.Errorf("model_list[%d]: %w", i, err)
 		}
+
+		first, seen := firstByName[m.ModelName]
+		if !seen {
+			firstByName[m.ModelName] = i
+			continue
+		}
+		want, got := modelProtocol(c.ModelList[first].Model), modelProtocol(m.Model)
+		if got != want {
+			return fmt.Errorf(
+				"model_list[%d]: model_name %q uses protocol %q but model_list[%d] uses %q; "+
+					"entries sharing a model_name are load-balanced and must use the same protocol",
+				i, m.ModelName, got, first, want,
+			)
+		}
 	}
 	return nil
 }
 
+// modelProtocol returns the protocol prefix of a model string, defaulting to
+// "openai" like providers.ExtractProtocol.
+func modelProtocol(model string) string {
+	protocol, _, found := strings.Cut(strings.TrimSpace(model), "/")
+	if !found {
+		return "openai"
+	}
+	return strings.ToLower(protocol)
+}
+
 func MergeAPIKeys(apiKey string, apiKeys []string) []string {
 	seen := make(map[string]struct{})
 	var all []string
@@ -1271,6 +1537,8 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.MediaCleanup.Enabled
 	case "append_file":
 		return t.AppendFile.Enabled
+	case "capabilities":
+		return t.Capabilities.Enabled
 	case "edit_file":
 		return t.EditFile.Enabled
 	case "find_skills":
@@ -1293,6 +1561,8 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.SPI.Enabled
 	case "subagent":
 		return t.Subagent.Enabled
+	case "tail_log":
+		return t.TailLog.Enabled
 	case "web_fetch":
 		return t.WebFetch.Enabled
 	case "send_file":
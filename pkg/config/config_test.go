@@ -1057,3 +1057,184 @@ func TestLoadConfig_UsesPassphraseProvider(t *testing.T) {
 		t.Errorf("api_key = %q, want %q", cfg.ModelList[0].APIKey, plainKey)
 	}
 }
+
+func TestLoadConfig_ModelListFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	data := `{"model_list":[
+		{"model_name":"fast","model":"openai/gpt-4o-mini","api_key":"file-key"},
+		{"model_name":"smart","model":"anthropic/claude-sonnet-4.6","api_key":"anthropic-key"}
+	]}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	t.Setenv("PICOCLAW_MODEL_LIST", `[
+		{"model_name":"fast","model":"openai/gpt-4.1-mini","api_key":"env-key"},
+		{"model_name":"local","model":"ollama/llama3","api_base":"http://ollama:11434/v1"}
+	]`)
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+
+	byName := make(map[string]ModelConfig)
+	for _, m := range cfg.ModelList {
+		byName[m.ModelName] = m
+	}
+	if len(byName) != 3 {
+		t.Fatalf("model_list has %d distinct entries, want 3: %+v", len(byName), cfg.ModelList)
+	}
+	if byName["fast"].Model != "openai/gpt-4.1-mini" || byName["fast"].APIKey != "env-key" {
+		t.Errorf("fast should be overridden from env, got %+v", byName["fast"])
+	}
+	if byName["smart"].APIKey != "anthropic-key" {
+		t.Errorf("smart should be kept from file, got %+v", byName["smart"])
+	}
+	if byName["local"].APIBase != "http://ollama:11434/v1" {
+		t.Errorf("local should be appended from env, got %+v", byName["local"])
+	}
+}
+
+func TestLoadConfig_ModelListFromEnvWithoutConfigFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("PICOCLAW_MODEL_LIST", `[{"model_name":"env","model":"openai/gpt-4o","api_key":"env-key"}]`)
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if len(cfg.ModelList) != 1 || cfg.ModelList[0].ModelName != "env" || cfg.ModelList[0].APIKey != "env-key" {
+		t.Fatalf("model_list = %+v, want only the env entry", cfg.ModelList)
+	}
+}
+
+func TestLoadConfig_ModelListFromEnvReplacesTemplateList(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"agents":{"defaults":{"model_name":"env"}}}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	if len(DefaultConfig().ModelList) == 0 {
+		t.Fatal("DefaultConfig() has no template model_list")
+	}
+	t.Setenv("PICOCLAW_MODEL_LIST", `[{"model_name":"env","model":"openai/gpt-4o","api_key":"env-key"}]`)
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if len(cfg.ModelList) != 1 || cfg.ModelList[0].ModelName != "env" {
+		t.Fatalf("model_list = %+v, want only the env entry", cfg.ModelList)
+	}
+}
+
+func TestLoadConfig_ModelListFromEnvReplacesAllEntriesWithName(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	data := `{"model_list":[
+		{"model_name":"fast","model":"openai/gpt-4o-mini","api_key":"key-1"},
+		{"model_name":"smart","model":"anthropic/claude-sonnet-4.6","api_key":"anthropic-key"},
+		{"model_name":"fast","model":"openai/gpt-4o-mini","api_key":"key-2"}
+	]}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	t.Setenv("PICOCLAW_MODEL_LIST", `[{"model_name":"fast","model":"openai/gpt-4.1-mini","api_key":"env-key"}]`)
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	var got []string
+	for _, m := range cfg.ModelList {
+		got = append(got, m.ModelName+"="+m.APIKey)
+	}
+	want := []string{"fast=env-key", "smart=anthropic-key"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("model_list = %v, want %v", got, want)
+	}
+}
+
+func TestLoadConfig_ModelListFromEnvInvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{}`), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	t.Setenv("PICOCLAW_MODEL_LIST", `{not json`)
+
+	if _, err := LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "PICOCLAW_MODEL_LIST") {
+		t.Fatalf("expected PICOCLAW_MODEL_LIST error, got %v", err)
+	}
+}
+
+func TestLoadConfig_ConvertsLegacyProvidersWhenModelListUnset(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	data := `{
+		"agents": {"defaults": {"provider": "deepseek", "model": "deepseek-chat"}},
+		"providers": {"deepseek": {"api_key": "ds-key"}}
+	}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+
+	if len(cfg.ModelList) != 1 {
+		t.Fatalf("model_list has %d entries, want 1 converted entry: %+v", len(cfg.ModelList), cfg.ModelList)
+	}
+	if cfg.ModelList[0].APIKey != "ds-key" || !strings.HasPrefix(cfg.ModelList[0].Model, "deepseek/") {
+		t.Errorf("converted entry = %+v, want deepseek model with ds-key", cfg.ModelList[0])
+	}
+}
+
+func TestLoadConfig_LegacyProvidersDoNotClobberModelList(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	data := `{
+		"providers": {"deepseek": {"api_key": "ds-key"}},
+		"model_list": [{"model_name":"mine","model":"openai/gpt-4o","api_key":"sk-mine"}]
+	}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+
+	if len(cfg.ModelList) != 1 || cfg.ModelList[0].ModelName != "mine" {
+		t.Fatalf("explicit model_list must be kept as-is, got %+v", cfg.ModelList)
+	}
+}
+
+func TestLoadConfig_ParsesConnectMode(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	data := `{"model_list":[{"model_name":"copilot","model":"github-copilot/gpt-4.1","connect_mode":"stdio"}]}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if got := cfg.ModelList[0].ConnectMode; got != "stdio" {
+		t.Errorf("connect_mode = %q, want stdio", got)
+	}
+
+	data = `{"model_list":[{"model_name":"copilot","model":"github-copilot/gpt-4.1","connect_mode":"pipe"}]}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	if _, err := LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "connect_mode") {
+		t.Errorf("LoadConfig() error = %v, want connect_mode error", err)
+	}
+}
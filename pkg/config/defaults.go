@@ -10,18 +10,20 @@ import (
 	"path/filepath"
 )
 
-// DefaultConfig returns the default configuration for PicoClaw.
-func DefaultConfig() *Config {
-	// Determine the base path for the workspace.
-	// Priority: $PICOCLAW_HOME > ~/.picoclaw
-	var homePath string
+// HomeDir returns the base directory for picoclaw data: $PICOCLAW_HOME, or
+// ~/.picoclaw when it is unset. Config, auth state and certificates live
+// here, outside the agent's workspace.
+func HomeDir() string {
 	if picoclawHome := os.Getenv(EnvHome); picoclawHome != "" {
-		homePath = picoclawHome
-	} else {
-		userHome, _ := os.UserHomeDir()
-		homePath = filepath.Join(userHome, ".picoclaw")
+		return picoclawHome
 	}
-	workspacePath := filepath.Join(homePath, "workspace")
+	userHome, _ := os.UserHomeDir()
+	return filepath.Join(userHome, ".picoclaw")
+}
+
+// DefaultConfig returns the default configuration for PicoClaw.
+func DefaultConfig() *Config {
+	workspacePath := filepath.Join(HomeDir(), "workspace")
 
 	return &Config{
 		Agents: AgentsConfig{
@@ -508,6 +510,9 @@ func DefaultConfig() *Config {
 			AppendFile: ToolConfig{
 				Enabled: true,
 			},
+			Capabilities: ToolConfig{
+				Enabled: true,
+			},
 			EditFile: ToolConfig{
 				Enabled: true,
 			},
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Config value sources reported by Explain, in increasing precedence.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+)
+
+// FieldSource describes where the effective value of one config field came from.
+type FieldSource struct {
+	Path   string // JSON path, e.g. "agents.defaults.max_tokens"
+	Value  string // rendered value, secrets redacted
+	Source string // SourceDefault, SourceFile, or SourceEnv
+	EnvVar string // environment variable that can set this field, if any
+}
+
+// Explain loads the config at path the same way LoadConfig does and reports,
+// for every leaf field, its effective value and whether it came from the
+// built-in defaults, the JSON file, or a PICOCLAW_* environment variable.
+// Secret values are redacted.
+func Explain(path string) ([]FieldSource, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// LoadConfig only applies environment overrides when the file exists.
+	var raw map[string]any
+	envApplied := false
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		envApplied = true
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	e := explainer{envApplied: envApplied}
+	e.walk(reflect.ValueOf(cfg).Elem(), "", "", raw)
+	return e.out, nil
+}
+
+type explainer struct {
+	envApplied bool
+	out        []FieldSource
+}
+
+func (e *explainer) walk(v reflect.Value, path, envPrefix string, raw map[string]any) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		name, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+		prefix := envPrefix + field.Tag.Get("envPrefix")
+
+		// Embedded structs without a JSON name are flattened into the parent.
+		if field.Anonymous && name == "" && fv.Kind() == reflect.Struct {
+			e.walk(fv, path, prefix, raw)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldPath := joinPath(path, name)
+		rawChild, inFile := raw[name]
+
+		if fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.Struct {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			childRaw, _ := rawChild.(map[string]any)
+			e.walk(fv, fieldPath, prefix, childRaw)
+			continue
+		}
+
+		envVar := ""
+		if tag := field.Tag.Get("env"); tag != "" {
+			envVar = envPrefix + strings.Split(tag, ",")[0]
+		}
+		source := SourceDefault
+		if inFile {
+			source = SourceFile
+		}
+		if envVar != "" && e.envApplied {
+			if _, ok := os.LookupEnv(envVar); ok {
+				source = SourceEnv
+			}
+		}
+
+		e.out = append(e.out, FieldSource{
+			Path:   fieldPath,
+			Value:  renderExplainValue(name, fv),
+			Source: source,
+			EnvVar: envVar,
+		})
+	}
+}
+
+func jsonFieldName(field reflect.StructField) (name string, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	return strings.Split(tag, ",")[0], false
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+const explainRedacted = "[REDACTED]"
+
+// secretConfigNames and secretConfigSuffixes extend the logger's redaction
+// rules for Explain. Every "*_key(s)" field in the config is a credential
+// (unlike log fields such as session_key), webhook URLs embed their key, and
+// the keys of api_tokens are the tokens themselves.
+var (
+	secretConfigNames    = map[string]struct{}{"webhook_url": {}, "api_tokens": {}}
+	secretConfigSuffixes = []string{"_key", "_keys"}
+)
+
+func isSecretConfigField(name string) bool {
+	if _, ok := secretConfigNames[name]; ok {
+		return true
+	}
+	for _, suffix := range secretConfigSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func renderExplainValue(name string, v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		return renderExplainValue(name, v.Elem())
+	}
+	if isSecretConfigField(name) && !v.IsZero() {
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+			return fmt.Sprintf("%s (%d entries)", explainRedacted, v.Len())
+		}
+		return explainRedacted
+	}
+
+	var rendered any
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		elem := v.Type().Elem().Kind()
+		if elem == reflect.Struct || elem == reflect.Map || elem == reflect.Pointer || elem == reflect.Interface {
+			return fmt.Sprintf("(%d entries)", v.Len())
+		}
+		rendered = fmt.Sprintf("%v", v.Interface())
+	default:
+		rendered = fmt.Sprintf("%v", v.Interface())
+	}
+	redacted := logger.RedactFields(map[string]any{name: rendered})
+	return fmt.Sprint(redacted[name])
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func explainByPath(t *testing.T, fields []FieldSource) map[string]FieldSource {
+	t.Helper()
+	byPath := make(map[string]FieldSource, len(fields))
+	for _, f := range fields {
+		byPath[f.Path] = f
+	}
+	return byPath
+}
+
+func TestExplain_AttributesSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "agents": {"defaults": {"max_tokens": 1000, "max_tool_iterations": 7}},
+  "channels": {"telegram": {"token": "123456:secret-telegram-token"}}
+}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PICOCLAW_AGENTS_DEFAULTS_MAX_TOKENS", "2000")
+
+	fields, err := Explain(path)
+	if err != nil {
+		t.Fatalf("Explain() error: %v", err)
+	}
+	byPath := explainByPath(t, fields)
+
+	maxTokens := byPath["agents.defaults.max_tokens"]
+	if maxTokens.Source != SourceEnv || maxTokens.Value != "2000" {
+		t.Errorf("max_tokens = %+v, want value 2000 from env", maxTokens)
+	}
+	if maxTokens.EnvVar != "PICOCLAW_AGENTS_DEFAULTS_MAX_TOKENS" {
+		t.Errorf("max_tokens env var = %q", maxTokens.EnvVar)
+	}
+
+	iterations := byPath["agents.defaults.max_tool_iterations"]
+	if iterations.Source != SourceFile || iterations.Value != "7" {
+		t.Errorf("max_tool_iterations = %+v, want value 7 from file", iterations)
+	}
+
+	port := byPath["gateway.port"]
+	if port.Source != SourceDefault {
+		t.Errorf("gateway.port source = %q, want default", port.Source)
+	}
+
+	token := byPath["channels.telegram.token"]
+	if token.Source != SourceFile || token.Value != "[REDACTED]" {
+		t.Errorf("telegram token = %+v, want redacted value from file", token)
+	}
+}
+
+func TestExplain_EnvPrefixFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PICOCLAW_TOOLS_WEB_FETCH_ENABLED", "false")
+
+	fields, err := Explain(path)
+	if err != nil {
+		t.Fatalf("Explain() error: %v", err)
+	}
+	webFetch := explainByPath(t, fields)["tools.web_fetch.enabled"]
+	if webFetch.EnvVar != "PICOCLAW_TOOLS_WEB_FETCH_ENABLED" || webFetch.Source != SourceEnv {
+		t.Errorf("web_fetch.enabled = %+v, want env-sourced via prefix", webFetch)
+	}
+	if webFetch.Value != "false" {
+		t.Errorf("web_fetch.enabled value = %q, want false", webFetch.Value)
+	}
+}
+
+func TestExplain_RedactsSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "channels": {
+    "feishu": {"encrypt_key": "feishu-encrypt-key"},
+    "wecom": {"webhook_url": "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=wecom-hook-key"}
+  },
+  "tools": {"web": {"brave": {"api_keys": ["brave-key-1", "brave-key-2"]}}},
+  "gateway": {"api_tokens": {"sk-gateway-token": {"per_minute": 5}}}
+}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fields, err := Explain(path)
+	if err != nil {
+		t.Fatalf("Explain() error: %v", err)
+	}
+	byPath := explainByPath(t, fields)
+
+	tests := []struct {
+		path   string
+		want   string
+		secret string
+	}{
+		{"channels.feishu.encrypt_key", "[REDACTED]", "feishu-encrypt-key"},
+		{"channels.wecom.webhook_url", "[REDACTED]", "wecom-hook-key"},
+		{"tools.web.brave.api_keys", "[REDACTED] (2 entries)", "brave-key"},
+		{"gateway.api_tokens", "[REDACTED] (1 entries)", "sk-gateway-token"},
+	}
+	for _, tt := range tests {
+		got := byPath[tt.path]
+		if got.Value != tt.want || strings.Contains(got.Value, tt.secret) {
+			t.Errorf("%s = %q, want %q", tt.path, got.Value, tt.want)
+		}
+	}
+
+	// Unset secrets and plain counts stay readable.
+	if v := byPath["channels.dingtalk.client_secret"].Value; v != "" {
+		t.Errorf("unset client_secret = %q, want empty", v)
+	}
+	if v := byPath["agents.defaults.max_tokens"].Value; v == "[REDACTED]" {
+		t.Error("max_tokens should not be redacted")
+	}
+}
@@ -21,6 +21,50 @@ func buildModelWithProtocol(protocol, model string) string {
 	return protocol + "/" + model
 }
 
+// defaultAPIBases are the endpoints used for each protocol when a model
+// entry does not set api_base. The provider factory and legacy provider
+// conversion both read them through DefaultAPIBase.
+var defaultAPIBases = map[string]string{
+	"openai":                   "https://api.openai.com/v1",
+	"openrouter":               "https://openrouter.ai/api/v1",
+	"litellm":                  "http://localhost:4000/v1",
+	"novita":                   "https://api.novita.ai/openai",
+	"groq":                     "https://api.groq.com/openai/v1",
+	"zhipu":                    "https://open.bigmodel.cn/api/paas/v4",
+	"gemini":                   "https://generativelanguage.googleapis.com/v1beta",
+	"nvidia":                   "https://integrate.api.nvidia.com/v1",
+	"ollama":                   "http://localhost:11434/v1",
+	"moonshot":                 "https://api.moonshot.cn/v1",
+	"shengsuanyun":             "https://router.shengsuanyun.com/api/v1",
+	"deepseek":                 "https://api.deepseek.com/v1",
+	"cerebras":                 "https://api.cerebras.ai/v1",
+	"vivgrid":                  "https://api.vivgrid.com/v1",
+	"volcengine":               "https://ark.cn-beijing.volces.com/api/v3",
+	"qwen":                     "https://dashscope.aliyuncs.com/compatible-mode/v1",
+	"qwen-intl":                "https://dashscope-intl.aliyuncs.com/compatible-mode/v1",
+	"qwen-international":       "https://dashscope-intl.aliyuncs.com/compatible-mode/v1",
+	"dashscope-intl":           "https://dashscope-intl.aliyuncs.com/compatible-mode/v1",
+	"qwen-us":                  "https://dashscope-us.aliyuncs.com/compatible-mode/v1",
+	"dashscope-us":             "https://dashscope-us.aliyuncs.com/compatible-mode/v1",
+	"coding-plan":              "https://coding-intl.dashscope.aliyuncs.com/v1",
+	"alibaba-coding":           "https://coding-intl.dashscope.aliyuncs.com/v1",
+	"qwen-coding":              "https://coding-intl.dashscope.aliyuncs.com/v1",
+	"coding-plan-anthropic":    "https://coding-intl.dashscope.aliyuncs.com/apps/anthropic",
+	"alibaba-coding-anthropic": "https://coding-intl.dashscope.aliyuncs.com/apps/anthropic",
+	"vllm":                     "http://localhost:8000/v1",
+	"mistral":                  "https://api.mistral.ai/v1",
+	"avian":                    "https://api.avian.io/v1",
+	"minimax":                  "https://api.minimaxi.com/v1",
+	"longcat":                  "https://api.longcat.chat/openai",
+	"modelscope":               "https://api-inference.modelscope.cn/v1",
+}
+
+// DefaultAPIBase returns the default API base URL for protocol, or "" when
+// the protocol has none (e.g. Anthropic, whose SDK supplies its own).
+func DefaultAPIBase(protocol string) string {
+	return defaultAPIBases[protocol]
+}
+
 // providerMigrationConfig defines how to migrate a provider from old config to new format.
 type providerMigrationConfig struct {
 	// providerNames are the possible names used in agents.defaults.provider
@@ -463,6 +507,12 @@ func ConvertProvidersToModelList(cfg *Config) []ModelConfig {
 			legacyModelNameApplied = true
 		}
 
+		// Local endpoints (ollama, vllm, litellm) are left to the provider
+		// factory so the saved entry does not pin localhost.
+		if base := DefaultAPIBase(m.protocol); mc.APIBase == "" && !strings.HasPrefix(base, "http://localhost") {
+			mc.APIBase = base
+		}
+
 		result = append(result, mc)
 	}
 
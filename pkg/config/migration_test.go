@@ -175,6 +175,40 @@ func TestConvertProvidersToModelList_AllProviders(t *testing.T) {
 	}
 }
 
+func TestConvertProvidersToModelList_DefaultAPIBase(t *testing.T) {
+	cfg := &Config{
+		Providers: ProvidersConfig{
+			Zhipu:      ProviderConfig{APIKey: "key-zhipu"},
+			DeepSeek:   ProviderConfig{APIKey: "key-deepseek"},
+			Moonshot:   ProviderConfig{APIKey: "key-moonshot"},
+			Qwen:       ProviderConfig{APIKey: "key-qwen"},
+			VolcEngine: ProviderConfig{APIKey: "key-volcengine"},
+			Groq:       ProviderConfig{APIKey: "key-groq", APIBase: "https://groq.example.com/v1"},
+			Ollama:     ProviderConfig{APIKey: "key-ollama"},
+		},
+	}
+
+	want := map[string]string{
+		"zhipu":      "https://open.bigmodel.cn/api/paas/v4",
+		"deepseek":   "https://api.deepseek.com/v1",
+		"moonshot":   "https://api.moonshot.cn/v1",
+		"qwen":       "https://dashscope.aliyuncs.com/compatible-mode/v1",
+		"volcengine": "https://ark.cn-beijing.volces.com/api/v3",
+		"groq":       "https://groq.example.com/v1", // user value wins
+		"ollama":     "",                            // local endpoint left to the provider factory
+	}
+
+	result := ConvertProvidersToModelList(cfg)
+	if len(result) != len(want) {
+		t.Fatalf("len(result) = %d, want %d", len(result), len(want))
+	}
+	for _, mc := range result {
+		if got := mc.APIBase; got != want[mc.ModelName] {
+			t.Errorf("%s APIBase = %q, want %q", mc.ModelName, got, want[mc.ModelName])
+		}
+	}
+}
+
 func TestConvertProvidersToModelList_Proxy(t *testing.T) {
 	cfg := &Config{
 		Providers: ProvidersConfig{
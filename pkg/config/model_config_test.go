@@ -307,6 +307,24 @@ func TestModelConfig_Validate(t *testing.T) {
 			config:  ModelConfig{},
 			wantErr: true,
 		},
+		{
+			name: "copilot stdio connect_mode",
+			config: ModelConfig{
+				ModelName:   "copilot",
+				Model:       "github-copilot/gpt-4.1",
+				ConnectMode: "stdio",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown connect_mode",
+			config: ModelConfig{
+				ModelName:   "copilot",
+				Model:       "github-copilot/gpt-4.1",
+				ConnectMode: "websocket",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -377,6 +395,29 @@ func TestConfig_ValidateModelList(t *testing.T) {
 			},
 			wantErr: false, // Changed: duplicates are allowed for load balancing
 		},
+		{
+			// A bare model ID defaults to the openai protocol.
+			name: "duplicate model_name with implicit and explicit openai protocol",
+			config: &Config{
+				ModelList: []ModelConfig{
+					{ModelName: "gpt-4", Model: "gpt-4o"},
+					{ModelName: "gpt-4", Model: "openai/gpt-4o"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "duplicate model_name with conflicting protocols",
+			config: &Config{
+				ModelList: []ModelConfig{
+					{ModelName: "smart", Model: "openai/gpt-4o"},
+					{ModelName: "other", Model: "gemini/gemini-2.5-pro"},
+					{ModelName: "smart", Model: "anthropic/claude-sonnet-4.6"},
+				},
+			},
+			wantErr: true,
+			errMsg:  `model_list[2]: model_name "smart" uses protocol "anthropic" but model_list[0] uses "openai"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,337 @@
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	chatCompletionsPath = "/v1/chat/completions"
+	chatAPIChannel      = "api"
+	chatAPIDefaultUser  = "default"
+	maxChatRequestBytes = 20 << 20 // room for inline base64 images
+)
+
+// inboundProcessor runs one message through the agent and returns its reply.
+// It is implemented by *agent.AgentLoop.
+type inboundProcessor interface {
+	ProcessInbound(ctx context.Context, msg bus.InboundMessage) (string, error)
+}
+
+// chatCompletionRequest is the subset of the OpenAI chat completions request
+// that the gateway understands. Tools and other sampling fields are ignored:
+// the agent brings its own tools.
+type chatCompletionRequest struct {
+	Model               string        `json:"model"`
+	Messages            []chatMessage `json:"messages"`
+	Stream              bool          `json:"stream"`
+	Temperature         *float64      `json:"temperature"`
+	MaxTokens           *int          `json:"max_tokens"`
+	MaxCompletionTokens *int          `json:"max_completion_tokens"`
+	User                string        `json:"user"`
+}
+
+// chatMessage holds a request message. Content is either a string or an
+// array of parts ({"type": "text"} or {"type": "image_url"}).
+type chatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type chatContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL struct {
+		URL string `json:"url"`
+	} `json:"image_url"`
+}
+
+type chatCompletionMessage struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+type chatCompletionChoice struct {
+	Index        int                    `json:"index"`
+	Message      *chatCompletionMessage `json:"message,omitempty"`
+	Delta        *chatCompletionMessage `json:"delta,omitempty"`
+	FinishReason *string                `json:"finish_reason"`
+}
+
+type chatCompletionResponse struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []chatCompletionChoice `json:"choices"`
+}
+
+// configureChatAPI serves the OpenAI-compatible chat completions endpoint
+// behind the API token quota. Tokens, limits and models are read from
+// currentConfig on every request, so a config reload adds, revokes or
+// re-limits tokens without a restart; while gateway.api_tokens is empty the
+// endpoint answers 404. It reports whether tokens are configured now.
+func configureChatAPI(
+	cm *channels.Manager,
+	currentConfig func() *config.Config,
+	processor inboundProcessor,
+) bool {
+	quota := newTokenQuota(currentConfig)
+	cm.HandleHTTP(chatCompletionsPath, quota.middleware(newChatCompletionsHandler(processor, currentConfig)))
+	return len(currentConfig().Gateway.APITokens) > 0
+}
+
+// newChatCompletionsHandler answers OpenAI chat completion requests with the
+// agent. Only the last user message is sent: the agent keeps its own session
+// history (per API token and "user" field), so earlier messages in the
+// request are not replayed. With "stream": true the reply is sent as
+// server-sent events once the turn completes. It must run behind the token
+// quota middleware.
+func newChatCompletionsHandler(processor inboundProcessor, currentConfig func() *config.Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed, use POST")
+			return
+		}
+
+		token := apiTokenFromContext(r.Context())
+		if token == "" {
+			writeAPIError(w, http.StatusUnauthorized, "invalid or missing API token")
+			return
+		}
+
+		cfg := currentConfig()
+		var req chatCompletionRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxChatRequestBytes)).Decode(&req); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+		msg, err := inboundFromChatRequest(req, cfg, tokenNamespace(token))
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		model := req.Model
+		if model == "" {
+			model = cfg.Agents.Defaults.GetModelName()
+		}
+		resp := chatCompletionResponse{
+			ID:      newChatCompletionID(),
+			Created: time.Now().Unix(),
+			Model:   model,
+		}
+
+		// A turn with tool calls can outlast the server's write timeout.
+		http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		if req.Stream {
+			streamChatCompletion(r.Context(), w, processor, msg, resp)
+			return
+		}
+
+		reply, err := processor.ProcessInbound(r.Context(), msg)
+		if err != nil {
+			logger.WarnCF("gateway", "Chat API request failed", map[string]any{"error": err.Error()})
+			writeAPIError(w, chatErrorStatus(err), err.Error())
+			return
+		}
+		stop := "stop"
+		resp.Object = "chat.completion"
+		resp.Choices = []chatCompletionChoice{{
+			Message:      &chatCompletionMessage{Role: "assistant", Content: reply},
+			FinishReason: &stop,
+		}}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// streamChatCompletion sends the reply as OpenAI-style chunks: the content
+// with the assistant role, an empty delta with finish_reason "stop", then
+// [DONE]. Headers are flushed before the turn starts so clients see the
+// stream open immediately.
+func streamChatCompletion(
+	ctx context.Context,
+	w http.ResponseWriter,
+	processor inboundProcessor,
+	msg bus.InboundMessage,
+	resp chatCompletionResponse,
+) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	rc.Flush()
+
+	reply, err := processor.ProcessInbound(ctx, msg)
+	if err != nil {
+		logger.WarnCF("gateway", "Chat API stream failed", map[string]any{"error": err.Error()})
+		writeSSE(w, map[string]any{
+			"error": map[string]any{"message": err.Error(), "code": chatErrorStatus(err)},
+		})
+		rc.Flush()
+		return
+	}
+
+	stop := "stop"
+	resp.Object = "chat.completion.chunk"
+	resp.Choices = []chatCompletionChoice{{
+		Delta: &chatCompletionMessage{Role: "assistant", Content: reply},
+	}}
+	writeSSE(w, resp)
+	resp.Choices = []chatCompletionChoice{{
+		Delta:        &chatCompletionMessage{},
+		FinishReason: &stop,
+	}}
+	writeSSE(w, resp)
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	rc.Flush()
+}
+
+// chatErrorStatus maps an agent error to an HTTP status: 503 when the turn
+// was shed because the agent is busy, 500 otherwise.
+func chatErrorStatus(err error) int {
+	if errors.Is(err, agent.ErrTurnQueueFull) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+func writeSSE(w http.ResponseWriter, v any) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(w, "data: %s\n\n", data)
+}
+
+// inboundFromChatRequest turns the last user message of req into an inbound
+// message on the "api" channel. The chat is keyed by namespace (derived from
+// the API token) and the "user" field, so clients holding different tokens
+// never share a session. Inline base64 images become media; a model name
+// from model_list selects that model for the turn.
+func inboundFromChatRequest(
+	req chatCompletionRequest,
+	cfg *config.Config,
+	namespace string,
+) (bus.InboundMessage, error) {
+	var last *chatMessage
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			last = &req.Messages[i]
+			break
+		}
+	}
+	if last == nil {
+		return bus.InboundMessage{}, fmt.Errorf("messages must include a user message")
+	}
+	content, media, err := parseChatContent(last.Content)
+	if err != nil {
+		return bus.InboundMessage{}, err
+	}
+	if strings.TrimSpace(content) == "" && len(media) == 0 {
+		return bus.InboundMessage{}, fmt.Errorf("the last user message is empty")
+	}
+
+	metadata := map[string]string{}
+	if t := req.Temperature; t != nil {
+		if *t < agent.MinTemperatureOverride || *t > agent.MaxTemperatureOverride {
+			return bus.InboundMessage{}, fmt.Errorf("temperature must be between %g and %g",
+				agent.MinTemperatureOverride, agent.MaxTemperatureOverride)
+		}
+		metadata["temperature"] = strconv.FormatFloat(*t, 'f', -1, 64)
+	}
+	maxTokens := req.MaxCompletionTokens
+	if maxTokens == nil {
+		maxTokens = req.MaxTokens
+	}
+	if maxTokens != nil {
+		if *maxTokens < 1 || *maxTokens > agent.MaxTokensOverride {
+			return bus.InboundMessage{}, fmt.Errorf("max_tokens must be between 1 and %d", agent.MaxTokensOverride)
+		}
+		metadata["max_tokens"] = strconv.Itoa(*maxTokens)
+	}
+	if req.Model != "" {
+		if _, err := cfg.GetModelConfig(req.Model); err == nil {
+			metadata["model"] = req.Model
+		}
+	}
+
+	user := req.User
+	if user == "" {
+		user = chatAPIDefaultUser
+	}
+	id := namespace + ":" + user
+	return bus.InboundMessage{
+		Channel:  chatAPIChannel,
+		SenderID: chatAPIChannel + ":" + id,
+		Sender: bus.SenderInfo{
+			Platform:    chatAPIChannel,
+			PlatformID:  id,
+			CanonicalID: chatAPIChannel + ":" + id,
+		},
+		ChatID:   id,
+		Content:  content,
+		Media:    media,
+		Peer:     bus.Peer{Kind: "direct", ID: id},
+		Metadata: metadata,
+	}, nil
+}
+
+// parseChatContent extracts the text and inline base64 images from a
+// message's content, which is either a string or an array of parts. Image
+// URLs that are not data URLs are skipped.
+func parseChatContent(raw json.RawMessage) (string, []string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil, nil
+	}
+
+	var parts []chatContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", nil, fmt.Errorf("message content must be a string or an array of parts")
+	}
+	var texts, media []string
+	for _, p := range parts {
+		switch p.Type {
+		case "text":
+			texts = append(texts, p.Text)
+		case "image_url":
+			if strings.HasPrefix(p.ImageURL.URL, "data:image/") {
+				media = append(media, p.ImageURL.URL)
+			}
+		}
+	}
+	return strings.Join(texts, "\n"), media, nil
+}
+
+// tokenNamespace derives a stable, non-secret identifier for an API token,
+// used to keep each token's sessions apart.
+func tokenNamespace(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+func newChatCompletionID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "chatcmpl-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,297 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeProcessor records the inbound message and answers with reply or err.
+type fakeProcessor struct {
+	got   bus.InboundMessage
+	calls int
+	reply string
+	err   error
+}
+
+func (p *fakeProcessor) ProcessInbound(_ context.Context, msg bus.InboundMessage) (string, error) {
+	p.got = msg
+	p.calls++
+	return p.reply, p.err
+}
+
+func newChatAPITest(reply string) (*fakeProcessor, http.Handler) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{Defaults: config.AgentDefaults{ModelName: "default-model"}},
+		ModelList: []config.ModelConfig{
+			{ModelName: "fast", Model: "openai/gpt-5.4-mini", APIKey: "test"},
+		},
+	}
+	p := &fakeProcessor{reply: reply}
+	return p, newChatCompletionsHandler(p, staticConfig(cfg))
+}
+
+func staticConfig(cfg *config.Config) func() *config.Config {
+	return func() *config.Config { return cfg }
+}
+
+const testAPIToken = "sk-test"
+
+// postChat posts body to h as if the quota middleware had accepted
+// testAPIToken.
+func postChat(h http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, chatCompletionsPath, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), apiTokenKey{}, testAPIToken))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestChatCompletions_ReturnsOpenAIResponse(t *testing.T) {
+	p, h := newChatAPITest("Paris.")
+
+	w := postChat(h, `{
+		"model": "fast",
+		"messages": [
+			{"role": "system", "content": "Be brief."},
+			{"role": "user", "content": "Hi"},
+			{"role": "assistant", "content": "Hello!"},
+			{"role": "user", "content": "Capital of France?"}
+		],
+		"temperature": 0.3,
+		"max_tokens": 100,
+		"user": "alice"
+	}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+
+	var resp struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		Model   string `json:"model"`
+		Choices []struct {
+			Index   int `json:"index"`
+			Message struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if !strings.HasPrefix(resp.ID, "chatcmpl-") || resp.Object != "chat.completion" || resp.Model != "fast" {
+		t.Errorf("unexpected response header fields: %+v", resp)
+	}
+	if len(resp.Choices) != 1 {
+		t.Fatalf("got %d choices, want 1", len(resp.Choices))
+	}
+	c := resp.Choices[0]
+	if c.Message.Role != "assistant" || c.Message.Content != "Paris." || c.FinishReason != "stop" {
+		t.Errorf("unexpected choice: %+v", c)
+	}
+
+	msg := p.got
+	if msg.Content != "Capital of France?" {
+		t.Errorf("agent got %q, want only the last user message", msg.Content)
+	}
+	chatID := tokenNamespace(testAPIToken) + ":alice"
+	if msg.Channel != "api" || msg.ChatID != chatID || msg.SenderID != "api:"+chatID {
+		t.Errorf("unexpected routing fields: channel=%q chat=%q sender=%q", msg.Channel, msg.ChatID, msg.SenderID)
+	}
+	want := map[string]string{"model": "fast", "temperature": "0.3", "max_tokens": "100"}
+	for k, v := range want {
+		if msg.Metadata[k] != v {
+			t.Errorf("metadata[%q] = %q, want %q", k, msg.Metadata[k], v)
+		}
+	}
+}
+
+func TestChatCompletions_StreamsServerSentEvents(t *testing.T) {
+	_, h := newChatAPITest("streamed answer")
+
+	w := postChat(h, `{"messages": [{"role": "user", "content": "hello"}], "stream": true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	var events []string
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			events = append(events, data)
+		}
+	}
+	if len(events) != 3 || events[2] != "[DONE]" {
+		t.Fatalf("events = %q, want two chunks and [DONE]", events)
+	}
+
+	var chunk struct {
+		Object  string `json:"object"`
+		Model   string `json:"model"`
+		Choices []struct {
+			Delta struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"delta"`
+			FinishReason *string `json:"finish_reason"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal([]byte(events[0]), &chunk); err != nil {
+		t.Fatal(err)
+	}
+	if chunk.Object != "chat.completion.chunk" || chunk.Model != "default-model" {
+		t.Errorf("unexpected chunk: %s", events[0])
+	}
+	if d := chunk.Choices[0].Delta; d.Role != "assistant" || d.Content != "streamed answer" {
+		t.Errorf("first delta = %+v, want the full reply", d)
+	}
+	if err := json.Unmarshal([]byte(events[1]), &chunk); err != nil {
+		t.Fatal(err)
+	}
+	if fr := chunk.Choices[0].FinishReason; fr == nil || *fr != "stop" {
+		t.Errorf("last chunk finish_reason = %v, want stop", fr)
+	}
+}
+
+func TestChatCompletions_ContentPartsAndImages(t *testing.T) {
+	p, h := newChatAPITest("a cat")
+
+	w := postChat(h, `{"messages": [{"role": "user", "content": [
+		{"type": "text", "text": "What is this?"},
+		{"type": "image_url", "image_url": {"url": "data:image/png;base64,iVBORw0KGgo="}},
+		{"type": "image_url", "image_url": {"url": "https://example.com/cat.png"}}
+	]}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+	if p.got.Content != "What is this?" {
+		t.Errorf("content = %q", p.got.Content)
+	}
+	if len(p.got.Media) != 1 || p.got.Media[0] != "data:image/png;base64,iVBORw0KGgo=" {
+		t.Errorf("media = %v, want the inline image only", p.got.Media)
+	}
+	if p.got.ChatID != tokenNamespace(testAPIToken)+":"+chatAPIDefaultUser || p.got.Metadata["model"] != "" {
+		t.Errorf("chat=%q model=%q, want default user and no model override", p.got.ChatID, p.got.Metadata["model"])
+	}
+}
+
+func TestChatCompletions_RejectsBadRequests(t *testing.T) {
+	p, h := newChatAPITest("unused")
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"malformed JSON", `{"messages": [`},
+		{"no user message", `{"messages": [{"role": "system", "content": "hi"}]}`},
+		{"empty user message", `{"messages": [{"role": "user", "content": "  "}]}`},
+		{"temperature out of range", `{"messages": [{"role": "user", "content": "hi"}], "temperature": 3}`},
+		{"zero max tokens", `{"messages": [{"role": "user", "content": "hi"}], "max_tokens": 0}`},
+		{"max tokens over limit", `{"messages": [{"role": "user", "content": "hi"}], "max_tokens": 200001}`},
+		{"max completion tokens over limit", `{"messages": [{"role": "user", "content": "hi"}], "max_completion_tokens": 500000}`},
+		{"bad content type", `{"messages": [{"role": "user", "content": 42}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postChat(h, tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", w.Code)
+			}
+			var body struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error.Message == "" {
+				t.Errorf("want an OpenAI-style error body, got %s", w.Body)
+			}
+		})
+	}
+	if p.calls != 0 {
+		t.Errorf("agent called %d times for invalid requests", p.calls)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, chatCompletionsPath, nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", w.Code)
+	}
+}
+
+func TestChatCompletions_AgentErrorIsReported(t *testing.T) {
+	p, h := newChatAPITest("")
+	p.err = errors.New("provider unavailable")
+
+	w := postChat(h, `{"messages": [{"role": "user", "content": "hi"}]}`)
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "provider unavailable") {
+		t.Errorf("status = %d body = %s, want 500 with the error", w.Code, w.Body)
+	}
+}
+
+func TestChatCompletions_BusyAgentReturns503(t *testing.T) {
+	p, h := newChatAPITest("")
+	p.err = agent.ErrTurnQueueFull
+
+	w := postChat(h, `{"messages": [{"role": "user", "content": "hi"}]}`)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+}
+
+func TestChatCompletions_SessionsAreScopedToToken(t *testing.T) {
+	cfg := &config.Config{Gateway: config.GatewayConfig{
+		APITokens: map[string]config.GatewayTokenLimits{"sk-one": {}, "sk-two": {}},
+	}}
+	p := &fakeProcessor{reply: "ok"}
+	quota := newTokenQuota(staticConfig(cfg))
+	h := quota.middleware(newChatCompletionsHandler(p, staticConfig(cfg)))
+
+	chatFor := func(token string) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, chatCompletionsPath,
+			strings.NewReader(`{"messages": [{"role": "user", "content": "hi"}], "user": "alice"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+		}
+		return p.got.ChatID
+	}
+	one, two := chatFor("sk-one"), chatFor("sk-two")
+	if one == two {
+		t.Errorf("both tokens got chat %q, want separate sessions", one)
+	}
+	if strings.Contains(one, "sk-one") || strings.Contains(p.got.SenderID, "sk-two") {
+		t.Errorf("chat %q / sender %q expose the API token", one, p.got.SenderID)
+	}
+	if again := chatFor("sk-one"); again != one {
+		t.Errorf("same token and user gave chat %q, then %q", one, again)
+	}
+
+	// Without the middleware there is no token to scope the session by.
+	req := httptest.NewRequest(http.MethodPost, chatCompletionsPath,
+		strings.NewReader(`{"messages": [{"role": "user", "content": "hi"}]}`))
+	w := httptest.NewRecorder()
+	newChatCompletionsHandler(p, staticConfig(cfg)).ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want 401", w.Code)
+	}
+}
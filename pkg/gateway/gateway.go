@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	_ "github.com/sipeed/picoclaw/pkg/channels/dingtalk"
@@ -36,6 +37,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/heartbeat"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/metrics"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/state"
 	"github.com/sipeed/picoclaw/pkg/tools"
@@ -55,10 +57,38 @@ type services struct {
 	ChannelManager   *channels.Manager
 	DeviceService    *devices.Service
 	HealthServer     *health.Server
+	OAuthRefreshers  oauthRefreshers
 	manualReloadChan chan struct{}
 	reloading        atomic.Bool
 }
 
+// startRefresher is swapped out in tests.
+var startRefresher = auth.StartRefresher
+
+// oauthRefreshers runs the background OAuth refreshers for the providers a
+// config uses, so they can be replaced when the config is reloaded.
+type oauthRefreshers struct {
+	cancel context.CancelFunc
+}
+
+// restart stops the running refreshers and starts one for every OAuth
+// provider in cfg, bound to ctx.
+func (r *oauthRefreshers) restart(ctx context.Context, cfg *config.Config) {
+	r.stop()
+	refreshCtx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	for _, name := range providers.OAuthCredentialProviders(cfg) {
+		startRefresher(refreshCtx, name)
+	}
+}
+
+func (r *oauthRefreshers) stop() {
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
 type startupBlockedProvider struct {
 	reason string
 }
@@ -89,6 +119,10 @@ func Run(debug bool, configPath string, allowEmptyStartup bool) error {
 		return fmt.Errorf("error loading config: %w", err)
 	}
 
+	if err = logger.ConfigureWithDebug(cfg.Logging.Level, cfg.Logging.Format, debug); err != nil {
+		return fmt.Errorf("error applying logging config: %w", err)
+	}
+
 	provider, modelID, err := createStartupProvider(cfg, allowEmptyStartup)
 	if err != nil {
 		return fmt.Errorf("error creating provider: %w", err)
@@ -98,7 +132,10 @@ func Run(debug bool, configPath string, allowEmptyStartup bool) error {
 		cfg.Agents.Defaults.ModelName = modelID
 	}
 
-	msgBus := bus.NewMessageBus()
+	msgBus := bus.NewMessageBusWithOptions(bus.Options{
+		BufferSize: cfg.Gateway.Bus.BufferSize,
+		Policy:     bus.BackpressurePolicy(cfg.Gateway.Bus.Policy),
+	})
 	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
 
 	fmt.Println("\n📦 Agent Status:")
@@ -145,6 +182,8 @@ func Run(debug bool, configPath string, allowEmptyStartup bool) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	runningServices.OAuthRefreshers.restart(ctx, cfg)
+
 	go agentLoop.Run(ctx)
 
 	var configReloadChan <-chan *config.Config
@@ -297,17 +336,31 @@ func setupAndStartServices(
 
 	addr := fmt.Sprintf("%s:%d", cfg.Gateway.Host, cfg.Gateway.Port)
 	runningServices.HealthServer = health.NewServer(cfg.Gateway.Host, cfg.Gateway.Port)
+	configureMetrics(runningServices.HealthServer, cfg)
 	runningServices.ChannelManager.SetupHTTPServer(addr, runningServices.HealthServer)
+	chatAPIEnabled := configureChatAPI(runningServices.ChannelManager, agentLoop.GetConfig, agentLoop)
 
 	if err = runningServices.ChannelManager.StartAll(context.Background()); err != nil {
 		return nil, fmt.Errorf("error starting channels: %w", err)
 	}
 
+	scheme := "http"
+	if cfg.Gateway.TLS.Enabled() {
+		scheme = "https"
+	}
 	fmt.Printf(
-		"✓ Health endpoints available at http://%s:%d/health, /ready and /reload (POST)\n",
+		"✓ Health endpoints available at %s://%s:%d/health, /ready and /reload (POST)\n",
+		scheme,
 		cfg.Gateway.Host,
 		cfg.Gateway.Port,
 	)
+	if cfg.Gateway.Metrics {
+		fmt.Printf("✓ Metrics available at %s://%s:%d/metrics\n", scheme, cfg.Gateway.Host, cfg.Gateway.Port)
+	}
+	if chatAPIEnabled {
+		fmt.Printf("✓ OpenAI-compatible API available at %s://%s:%d%s\n",
+			scheme, cfg.Gateway.Host, cfg.Gateway.Port, chatCompletionsPath)
+	}
 
 	stateManager := state.NewManager(cfg.WorkspacePath())
 	runningServices.DeviceService = devices.NewService(devices.Config{
@@ -324,6 +377,16 @@ func setupAndStartServices(
 	return runningServices, nil
 }
 
+// configureMetrics exposes /metrics on the gateway HTTP server when
+// gateway.metrics is enabled, and hides it again after a reload disables it.
+func configureMetrics(healthServer *health.Server, cfg *config.Config) {
+	if cfg.Gateway.Metrics {
+		healthServer.SetMetricsHandler(metrics.Handler())
+	} else {
+		healthServer.SetMetricsHandler(nil)
+	}
+}
+
 func stopAndCleanupServices(runningServices *services, shutdownTimeout time.Duration, isReload bool) {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer shutdownCancel()
@@ -423,6 +486,7 @@ func handleConfigReload(
 		logger.Errorf("  ⚠ Error restarting services: %v", err)
 		return fmt.Errorf("error restarting services: %w", err)
 	}
+	runningServices.OAuthRefreshers.restart(ctx, newCfg)
 
 	logger.Info("  ✓ Provider, configuration, and services reloaded successfully (thread-safe)")
 	return nil
@@ -493,7 +557,9 @@ func restartServices(
 	if runningServices.HealthServer == nil {
 		runningServices.HealthServer = health.NewServer(cfg.Gateway.Host, cfg.Gateway.Port)
 	}
+	configureMetrics(runningServices.HealthServer, cfg)
 	runningServices.ChannelManager.SetupHTTPServer(addr, runningServices.HealthServer)
+	configureChatAPI(runningServices.ChannelManager, al.GetConfig, al)
 
 	if err = runningServices.ChannelManager.Reload(context.Background(), cfg); err != nil {
 		return fmt.Errorf("error reload channels: %w", err)
@@ -0,0 +1,39 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestOAuthRefreshers_RestartFollowsConfig(t *testing.T) {
+	started := map[string]context.Context{}
+	prev := startRefresher
+	startRefresher = func(ctx context.Context, provider string) { started[provider] = ctx }
+	t.Cleanup(func() { startRefresher = prev })
+
+	oauthModel := func(model string) *config.Config {
+		return &config.Config{ModelList: []config.ModelConfig{{ModelName: "m", Model: model, AuthMethod: "oauth"}}}
+	}
+
+	var r oauthRefreshers
+	r.restart(context.Background(), oauthModel("openai/gpt-5.4"))
+	openaiCtx := started["openai"]
+	if openaiCtx == nil {
+		t.Fatalf("started = %v, want an openai refresher", started)
+	}
+
+	r.restart(context.Background(), oauthModel("anthropic/claude-sonnet-4.6"))
+	if openaiCtx.Err() == nil {
+		t.Error("openai refresher should be stopped after a reload that drops it")
+	}
+	if ctx := started["anthropic"]; ctx == nil || ctx.Err() != nil {
+		t.Errorf("started = %v, want a running anthropic refresher", started)
+	}
+
+	r.stop()
+	if started["anthropic"].Err() == nil {
+		t.Error("stop should cancel the running refreshers")
+	}
+}
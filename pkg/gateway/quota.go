@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// tokenUsage counts one token's requests in the current minute and day.
+type tokenUsage struct {
+	minute      time.Time // start of the current minute window
+	minuteCount int
+	day         time.Time // start of the current UTC day
+	dayCount    int
+}
+
+// tokenQuota authenticates API requests by bearer token and enforces each
+// token's per-minute and per-day request limits with fixed windows. Tokens
+// and limits come from gateway.api_tokens of the current config, so reloads
+// take effect on the next request; usage counts survive a reload.
+type tokenQuota struct {
+	mu            sync.Mutex
+	currentConfig func() *config.Config
+	usage         map[string]*tokenUsage
+	now           func() time.Time
+}
+
+func newTokenQuota(currentConfig func() *config.Config) *tokenQuota {
+	return &tokenQuota{
+		currentConfig: currentConfig,
+		usage:         make(map[string]*tokenUsage),
+		now:           time.Now,
+	}
+}
+
+// limits returns the configured tokens and their limits.
+func (q *tokenQuota) limits() map[string]config.GatewayTokenLimits {
+	return q.currentConfig().Gateway.APITokens
+}
+
+// allow records a request for token. It returns false and how long to wait
+// when a limit is exhausted; rejected requests do not count.
+func (q *tokenQuota) allow(token string) (bool, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	limits := q.limits()[token]
+	now := q.now().UTC()
+	minute := now.Truncate(time.Minute)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	u := q.usage[token]
+	if u == nil {
+		u = &tokenUsage{}
+		q.usage[token] = u
+	}
+	if !u.minute.Equal(minute) {
+		u.minute, u.minuteCount = minute, 0
+	}
+	if !u.day.Equal(day) {
+		u.day, u.dayCount = day, 0
+	}
+
+	if limits.PerDay > 0 && u.dayCount >= limits.PerDay {
+		return false, day.AddDate(0, 0, 1).Sub(now)
+	}
+	if limits.PerMinute > 0 && u.minuteCount >= limits.PerMinute {
+		return false, minute.Add(time.Minute).Sub(now)
+	}
+	u.minuteCount++
+	u.dayCount++
+	return true, 0
+}
+
+// middleware answers 404 while no tokens are configured, rejects requests
+// without a configured bearer token with 401 and requests over the token's
+// quota with 429 and a Retry-After header. The token of an accepted request
+// is available from apiTokenFromContext.
+func (q *tokenQuota) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits := q.limits()
+		if len(limits) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if _, known := limits[token]; !ok || token == "" || !known {
+			writeAPIError(w, http.StatusUnauthorized, "invalid or missing API token")
+			return
+		}
+
+		if ok, retryAfter := q.allow(token); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeAPIError(w, http.StatusTooManyRequests, "rate limit exceeded for API token")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiTokenKey{}, token)))
+	})
+}
+
+type apiTokenKey struct{}
+
+// apiTokenFromContext returns the API token the middleware authenticated
+// the request with, or "" when it did not run.
+func apiTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(apiTokenKey{}).(string)
+	return token
+}
+
+// writeAPIError writes an error in the OpenAI-style {"error": {...}} shape.
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{"message": message, "code": status},
+	})
+}
@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newTestQuota(now *time.Time) (*tokenQuota, http.Handler) {
+	cfg := &config.Config{Gateway: config.GatewayConfig{APITokens: map[string]config.GatewayTokenLimits{
+		"alice":     {PerMinute: 2, PerDay: 3},
+		"unlimited": {},
+	}}}
+	q := newTokenQuota(func() *config.Config { return cfg })
+	q.now = func() time.Time { return *now }
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return q, q.middleware(ok)
+}
+
+func doChat(h http.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestTokenQuota_PerMinuteLimit(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 15, 30, 0, time.UTC)
+	_, h := newTestQuota(&now)
+
+	for i := range 2 {
+		if w := doChat(h, "alice"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i+1, w.Code)
+		}
+	}
+
+	w := doChat(h, "alice")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request in a minute: status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want %q", got, "30")
+	}
+
+	now = now.Add(30 * time.Second)
+	if w := doChat(h, "alice"); w.Code != http.StatusOK {
+		t.Fatalf("next minute: status = %d, want 200", w.Code)
+	}
+}
+
+func TestTokenQuota_PerDayLimit(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	_, h := newTestQuota(&now)
+
+	for i := range 3 {
+		if w := doChat(h, "alice"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i+1, w.Code)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	w := doChat(h, "alice")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("fourth request in a day: status = %d, want 429", w.Code)
+	}
+	// 23:03 → midnight UTC
+	if got := w.Header().Get("Retry-After"); got != "3420" {
+		t.Errorf("Retry-After = %q, want %q", got, "3420")
+	}
+
+	now = time.Date(2026, 3, 2, 0, 0, 1, 0, time.UTC)
+	if w := doChat(h, "alice"); w.Code != http.StatusOK {
+		t.Fatalf("next day: status = %d, want 200", w.Code)
+	}
+}
+
+func TestTokenQuota_TokensAreIndependent(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	_, h := newTestQuota(&now)
+
+	doChat(h, "alice")
+	doChat(h, "alice")
+	if w := doChat(h, "alice"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("alice: status = %d, want 429", w.Code)
+	}
+	for i := range 10 {
+		if w := doChat(h, "unlimited"); w.Code != http.StatusOK {
+			t.Fatalf("unlimited request %d: status = %d, want 200", i+1, w.Code)
+		}
+	}
+}
+
+func TestTokenQuota_RejectsUnknownToken(t *testing.T) {
+	now := time.Now()
+	_, h := newTestQuota(&now)
+
+	for _, token := range []string{"", "mallory"} {
+		if w := doChat(h, token); w.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, w.Code)
+		}
+	}
+}
+
+func TestTokenQuota_FollowsConfigReload(t *testing.T) {
+	cfg := &config.Config{Gateway: config.GatewayConfig{APITokens: map[string]config.GatewayTokenLimits{
+		"old": {},
+	}}}
+	q := newTokenQuota(func() *config.Config { return cfg })
+	h := q.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	if w := doChat(h, "old"); w.Code != http.StatusOK {
+		t.Fatalf("before reload: status = %d, want 200", w.Code)
+	}
+
+	cfg = &config.Config{Gateway: config.GatewayConfig{APITokens: map[string]config.GatewayTokenLimits{
+		"new": {PerMinute: 1},
+	}}}
+	if w := doChat(h, "old"); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked token: status = %d, want 401", w.Code)
+	}
+	if w := doChat(h, "new"); w.Code != http.StatusOK {
+		t.Errorf("added token: status = %d, want 200", w.Code)
+	}
+	if w := doChat(h, "new"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("added token over its new limit: status = %d, want 429", w.Code)
+	}
+
+	cfg = &config.Config{}
+	if w := doChat(h, "new"); w.Code != http.StatusNotFound {
+		t.Errorf("no tokens configured: status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,34 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/metrics"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	s := NewServer("127.0.0.1", 0)
+	mux := http.NewServeMux()
+	s.RegisterOnMux(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("disabled /metrics status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	s.SetMetricsHandler(metrics.Handler())
+	metrics.MessagesReceived.Inc("pico")
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enabled /metrics status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `picoclaw_messages_received_total{channel="pico"}`) {
+		t.Fatalf("/metrics body missing messages counter:\n%s", body)
+	}
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -31,31 +32,51 @@ var (
 		FATAL: "FATAL",
 	}
 
-	currentLevel = INFO
-	logger       zerolog.Logger
-	fileLogger   zerolog.Logger
-	logFile      *os.File
-	once         sync.Once
-	mu           sync.RWMutex
+	currentLevel  = INFO
+	currentFormat = FormatText
+	output        = io.Writer(os.Stdout)
+	logger        zerolog.Logger
+	fileLogger    zerolog.Logger
+	logFile       *os.File
+	once          sync.Once
+	mu            sync.RWMutex
+)
+
+// Format selects how console log lines are rendered.
+type Format string
+
+const (
+	// FormatText renders human-readable, colorized lines (default).
+	FormatText Format = "text"
+	// FormatJSON renders one JSON object per line for log ingestion.
+	FormatJSON Format = "json"
 )
 
 func init() {
 	once.Do(func() {
 		zerolog.SetGlobalLevel(zerolog.InfoLevel)
 
-		consoleWriter := zerolog.ConsoleWriter{
-			Out:        os.Stdout,
-			TimeFormat: "15:04:05", // TODO: make it configurable???
-
-			// Custom formatter to handle multiline strings and JSON objects
-			FormatFieldValue: formatFieldValue,
-		}
-
-		logger = zerolog.New(consoleWriter).With().Timestamp().Caller().Logger()
+		logger = newConsoleLogger(output, currentFormat)
 		fileLogger = zerolog.Logger{}
 	})
 }
 
+func newConsoleLogger(w io.Writer, format Format) zerolog.Logger {
+	if format == FormatJSON {
+		return zerolog.New(w).With().Timestamp().Caller().Logger()
+	}
+
+	consoleWriter := zerolog.ConsoleWriter{
+		Out:        w,
+		TimeFormat: "15:04:05", // TODO: make it configurable???
+
+		// Custom formatter to handle multiline strings and JSON objects
+		FormatFieldValue: formatFieldValue,
+	}
+
+	return zerolog.New(consoleWriter).With().Timestamp().Caller().Logger()
+}
+
 func formatFieldValue(i any) string {
 	var s string
 
@@ -106,6 +127,94 @@ func GetLevel() LogLevel {
 	return currentLevel
 }
 
+// ParseLevel converts a level name such as "debug" or "WARN" into a LogLevel.
+// "warning" is accepted as an alias for WARN.
+func ParseLevel(name string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARN", "WARNING":
+		return WARN, nil
+	case "ERROR":
+		return ERROR, nil
+	case "FATAL":
+		return FATAL, nil
+	default:
+		return INFO, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// ParseFormat converts a format name ("text" or "json") into a Format.
+// An empty name selects FormatText.
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(name))) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return FormatText, fmt.Errorf("unknown log format %q", name)
+	}
+}
+
+// SetFormat switches console output between text and JSON rendering.
+// The console level set via SetConsoleLevel is preserved.
+func SetFormat(format Format) {
+	mu.Lock()
+	defer mu.Unlock()
+	currentFormat = format
+	logger = newConsoleLogger(output, format).Level(logger.GetLevel())
+}
+
+// GetFormat returns the current console output format.
+func GetFormat() Format {
+	mu.RLock()
+	defer mu.RUnlock()
+	return currentFormat
+}
+
+// Configure applies a textual level and format such as those read from the
+// config file. Empty values leave the corresponding setting unchanged.
+func Configure(level, format string) error {
+	if level != "" {
+		lvl, err := ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		SetLevel(lvl)
+	}
+	if format != "" {
+		f, err := ParseFormat(format)
+		if err != nil {
+			return err
+		}
+		SetFormat(f)
+	}
+	return nil
+}
+
+// ConfigureWithDebug is Configure for commands with a --debug flag: when
+// debug is set, the level is DEBUG whatever the config file says.
+func ConfigureWithDebug(level, format string, debug bool) error {
+	if debug {
+		level = "debug"
+	}
+	return Configure(level, format)
+}
+
+// SetOutput redirects console output to w. A nil writer restores os.Stdout.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	if w == nil {
+		w = os.Stdout
+	}
+	output = w
+	logger = newConsoleLogger(w, currentFormat).Level(logger.GetLevel())
+}
+
 func EnableFileLogging(filePath string) error {
 	mu.Lock()
 	defer mu.Unlock()
@@ -193,6 +302,9 @@ func logMessage(level LogLevel, component string, message string, fields map[str
 		return
 	}
 
+	message = redactString(message)
+	fields = redactFields(fields)
+
 	skip := getCallerSkip()
 
 	event := getEvent(logger, level)
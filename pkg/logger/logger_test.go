@@ -1,6 +1,9 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -252,3 +255,106 @@ func TestFormatFieldValue(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LogLevel
+		wantErr bool
+	}{
+		{"debug", DEBUG, false},
+		{"INFO", INFO, false},
+		{" warn ", WARN, false},
+		{"warning", WARN, false},
+		{"Error", ERROR, false},
+		{"fatal", FATAL, false},
+		{"verbose", INFO, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLevel(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigureWithDebug(t *testing.T) {
+	initialLevel := GetLevel()
+	defer SetLevel(initialLevel)
+
+	if err := ConfigureWithDebug("error", "", false); err != nil {
+		t.Fatalf("ConfigureWithDebug() error = %v", err)
+	}
+	if got := GetLevel(); got != ERROR {
+		t.Errorf("level = %v, want ERROR from the config", got)
+	}
+
+	// --debug wins over the configured level, even an invalid one.
+	if err := ConfigureWithDebug("verbose", "", true); err != nil {
+		t.Fatalf("ConfigureWithDebug() with debug error = %v", err)
+	}
+	if got := GetLevel(); got != DEBUG {
+		t.Errorf("level = %v, want DEBUG with --debug", got)
+	}
+}
+
+func TestDebugSuppressedAtInfoLevel(t *testing.T) {
+	initialLevel := GetLevel()
+	defer SetLevel(initialLevel)
+
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(nil)
+
+	SetLevel(INFO)
+	DebugCF("wecom", "decrypt details", map[string]any{"len": 32})
+	if buf.Len() != 0 {
+		t.Fatalf("DEBUG output at INFO level: %q", buf.String())
+	}
+
+	InfoCF("wecom", "message received", nil)
+	if !strings.Contains(buf.String(), "message received") {
+		t.Fatalf("INFO output missing: %q", buf.String())
+	}
+}
+
+func TestJSONFormatEmitsParseableLines(t *testing.T) {
+	initialLevel := GetLevel()
+	defer SetLevel(initialLevel)
+
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetFormat(FormatJSON)
+	defer func() {
+		SetFormat(FormatText)
+		SetOutput(nil)
+	}()
+
+	SetLevel(DEBUG)
+	InfoCF("agent", "first line", map[string]any{"count": 2})
+	WarnC("agent", "second line")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line is not valid JSON: %q: %v", line, err)
+		}
+		if entry["component"] != "agent" {
+			t.Errorf("component = %v, want agent", entry["component"])
+		}
+		if _, ok := entry["level"]; !ok {
+			t.Errorf("missing level field in %q", line)
+		}
+	}
+}
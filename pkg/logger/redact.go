@@ -0,0 +1,187 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+// secretFieldNames are field keys whose values are always masked, regardless
+// of content. Names are compared after lowercasing and mapping "-" to "_".
+var secretFieldNames = map[string]struct{}{
+	"key":              {},
+	"token":            {},
+	"secret":           {},
+	"password":         {},
+	"passphrase":       {},
+	"authorization":    {},
+	"apikey":           {},
+	"api_key":          {},
+	"api_keys":         {},
+	"encrypt_key":      {},
+	"aes_key":          {},
+	"encoding_aes_key": {},
+	"private_key":      {},
+	"pin":              {},
+	"mnemonic":         {},
+}
+
+// secretFieldSuffixes catch the many "<something>_token"/"<something>_secret"
+// variants (access_token, app_secret, corp_secret, ...). Plural forms such as
+// "max_tokens" deliberately do not match.
+var secretFieldSuffixes = []string{
+	"_token",
+	"_secret",
+	"_password",
+	"_api_key",
+	"_aes_key",
+	"_pin",
+}
+
+var (
+	// apiKeyRe matches OpenAI/Anthropic style keys ("sk-...", "sk-ant-...").
+	apiKeyRe = regexp.MustCompile(`\b(sk-(?:ant-)?)[A-Za-z0-9_-]{16,}`)
+	// bearerRe matches bearer credentials in Authorization header dumps.
+	bearerRe = regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9._~+/=-]{8,}`)
+)
+
+func isSecretField(name string) bool {
+	name = strings.ReplaceAll(strings.ToLower(name), "-", "_")
+	if _, ok := secretFieldNames[name]; ok {
+		return true
+	}
+	for _, suffix := range secretFieldSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString masks credentials embedded in free-form text such as log
+// messages or error strings.
+func redactString(s string) string {
+	s = maskSecrets(s)
+	s = apiKeyRe.ReplaceAllString(s, "${1}****")
+	return bearerRe.ReplaceAllString(s, "${1}****")
+}
+
+// redactFields returns fields with secret values masked, including values in
+// nested maps and slices. The input map is never modified; a copy is made
+// only when something needs redacting.
+func redactFields(fields map[string]any) map[string]any {
+	out, _ := redactMap(fields)
+	return out
+}
+
+// redactMap is redactFields, also reporting whether anything was masked.
+func redactMap(fields map[string]any) (map[string]any, bool) {
+	var out map[string]any
+	for k, v := range fields {
+		redacted, changed := redactField(k, v)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]any, len(fields))
+			for k2, v2 := range fields {
+				out[k2] = v2
+			}
+		}
+		out[k] = redacted
+	}
+	if out == nil {
+		return fields, false
+	}
+	return out, true
+}
+
+// redactSlice masks secrets in the elements of items, copying it only when
+// something changes. Elements inherit no field name, so only their content
+// is checked.
+func redactSlice(items []any) ([]any, bool) {
+	var out []any
+	for i, v := range items {
+		redacted, changed := redactField("", v)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = make([]any, len(items))
+			copy(out, items)
+		}
+		out[i] = redacted
+	}
+	if out == nil {
+		return items, false
+	}
+	return out, true
+}
+
+// RedactFields returns a copy-on-write version of fields with secret values
+// masked at any depth, using the same rules applied to every log entry. Callers that persist
+// structured data outside the logger (e.g. audit trails) should use it.
+func RedactFields(fields map[string]any) map[string]any {
+	return redactFields(fields)
+}
+
+// RedactString masks credentials embedded in free-form text (API keys,
+// bearer tokens, registered secrets) with the rules used for log messages.
+func RedactString(s string) string {
+	return redactString(s)
+}
+
+func redactField(name string, v any) (any, bool) {
+	switch val := v.(type) {
+	case nil, bool, int, int64, float64:
+		return v, false
+	case string:
+		if val == "" {
+			return v, false
+		}
+		if isSecretField(name) {
+			return redactedValue, true
+		}
+		if masked := redactString(val); masked != val {
+			return masked, true
+		}
+		return v, false
+	case error:
+		if isSecretField(name) {
+			return redactedValue, true
+		}
+		msg := val.Error()
+		if masked := redactString(msg); masked != msg {
+			return masked, true
+		}
+		return v, false
+	case map[string]any:
+		if isSecretField(name) {
+			return redactedValue, true
+		}
+		return redactMap(val)
+	case []any:
+		if isSecretField(name) {
+			return redactedValue, true
+		}
+		return redactSlice(val)
+	case []string:
+		if isSecretField(name) {
+			return redactedValue, true
+		}
+		items := make([]any, len(val))
+		for i, item := range val {
+			items[i] = item
+		}
+		if redacted, changed := redactSlice(items); changed {
+			return redacted, true
+		}
+		return v, false
+	default:
+		if isSecretField(name) {
+			return redactedValue, true
+		}
+		return v, false
+	}
+}
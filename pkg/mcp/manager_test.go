@@ -6,10 +6,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
 func TestLoadEnvFile(t *testing.T) {
@@ -306,3 +308,78 @@ func TestClose_IdempotentOnEmptyManager(t *testing.T) {
 		t.Fatalf("second close should be idempotent, got: %v", err)
 	}
 }
+
+// fakeServerEnv makes the test binary act as a stdio MCP server when it is
+// re-executed by TestConnectServer_StdioToolServer.
+const fakeServerEnv = "PICOCLAW_TEST_FAKE_MCP_SERVER"
+
+type reverseArgs struct {
+	Text string `json:"text" jsonschema:"text to reverse"`
+}
+
+// TestFakeStdioServer is not a real test: it serves one "reverse" tool over
+// stdin/stdout when run as the fake server's subprocess.
+func TestFakeStdioServer(t *testing.T) {
+	if os.Getenv(fakeServerEnv) != "1" {
+		t.Skip("only runs as a subprocess of TestConnectServer_StdioToolServer")
+	}
+
+	server := sdkmcp.NewServer(&sdkmcp.Implementation{Name: "fake-tools", Version: "0.1.0"}, nil)
+	sdkmcp.AddTool(server, &sdkmcp.Tool{Name: "reverse", Description: "Reverse a string"},
+		func(_ context.Context, _ *sdkmcp.CallToolRequest, args reverseArgs) (*sdkmcp.CallToolResult, any, error) {
+			runes := []rune(args.Text)
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return &sdkmcp.CallToolResult{
+				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: string(runes)}},
+			}, nil, nil
+		})
+	if err := server.Run(context.Background(), &sdkmcp.StdioTransport{}); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestConnectServer_StdioToolServer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mgr := NewManager()
+	defer mgr.Close()
+
+	err := mgr.ConnectServer(ctx, "fake", config.MCPServerConfig{
+		Enabled: true,
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestFakeStdioServer$"},
+		Env:     map[string]string{fakeServerEnv: "1"},
+	})
+	if err != nil {
+		t.Fatalf("ConnectServer() error = %v", err)
+	}
+
+	advertised := mgr.GetAllTools()["fake"]
+	if len(advertised) != 1 || advertised[0].Name != "reverse" {
+		t.Fatalf("advertised tools = %v, want [reverse]", advertised)
+	}
+
+	tool := tools.NewMCPTool(mgr, "fake", advertised[0])
+	if tool.Name() != "mcp_fake_reverse" {
+		t.Errorf("Name() = %q, want %q", tool.Name(), "mcp_fake_reverse")
+	}
+	if !strings.HasSuffix(tool.Description(), "Reverse a string") {
+		t.Errorf("Description() = %q", tool.Description())
+	}
+	props, _ := tool.Parameters()["properties"].(map[string]any)
+	if _, ok := props["text"]; !ok {
+		t.Errorf("Parameters() = %v, want a text property", tool.Parameters())
+	}
+
+	result := tool.Execute(ctx, map[string]any{"text": "picoclaw"})
+	if result.IsError {
+		t.Fatalf("Execute() error: %s", result.ForLLM)
+	}
+	if result.ForLLM != "walcocip" {
+		t.Errorf("Execute() = %q, want %q", result.ForLLM, "walcocip")
+	}
+}
@@ -0,0 +1,245 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package metrics keeps process-wide counters and gauges and renders them in
+// the Prometheus text exposition format. It has no dependencies beyond the
+// standard library so instrumented packages can import it freely.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metrics exported by the gateway on /metrics.
+var (
+	MessagesReceived = NewCounter(
+		"picoclaw_messages_received_total",
+		"Inbound messages accepted from channels.",
+		"channel",
+	)
+	AgentTurns = NewCounter(
+		"picoclaw_agent_turns_total",
+		"Agent turns processed.",
+		"agent",
+	)
+	AgentTurnsInFlight = NewGauge(
+		"picoclaw_agent_turns_in_flight",
+		"Agent turns currently being processed.",
+	)
+	ToolInvocations = NewCounter(
+		"picoclaw_tool_invocations_total",
+		"Tool executions by outcome (ok, error, async).",
+		"tool", "result",
+	)
+	ProviderErrors = NewCounter(
+		"picoclaw_provider_errors_total",
+		"LLM calls that failed after retries and fallbacks.",
+		"model",
+	)
+	TokensUsed = NewCounter(
+		"picoclaw_tokens_total",
+		"Tokens reported by providers, by type (prompt, completion).",
+		"model", "type",
+	)
+	BusQueueDepth = NewGauge(
+		"picoclaw_bus_queue_depth",
+		"Messages buffered in a message bus queue, sampled at scrape time.",
+		"queue",
+	)
+	BusMessagesShed = NewCounter(
+		"picoclaw_bus_messages_shed_total",
+		"Messages a full bus queue dropped or rejected, by backpressure policy.",
+		"queue", "policy",
+	)
+)
+
+var (
+	registryMu sync.Mutex
+	registry   []*metric
+)
+
+type metric struct {
+	name       string
+	help       string
+	kind       string // "counter" or "gauge"
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]float64         // rendered label set → value
+	funcs  map[string]*func() float64 // series sampled at scrape time
+}
+
+func newMetric(kind, name, help string, labelNames []string) *metric {
+	m := &metric{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		values:     make(map[string]float64),
+		funcs:      make(map[string]*func() float64),
+	}
+	registryMu.Lock()
+	registry = append(registry, m)
+	registryMu.Unlock()
+	return m
+}
+
+func (m *metric) add(v float64, labelValues []string) {
+	key := m.labelKey(labelValues)
+	m.mu.Lock()
+	m.values[key] += v
+	m.mu.Unlock()
+}
+
+func (m *metric) set(v float64, labelValues []string) {
+	key := m.labelKey(labelValues)
+	m.mu.Lock()
+	m.values[key] = v
+	m.mu.Unlock()
+}
+
+// labelKey renders label values as `{a="x",b="y"}`. Missing values are
+// rendered empty so a miscounted call never panics at runtime.
+func (m *metric) labelKey(labelValues []string) string {
+	if len(m.labelNames) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range m.labelNames {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		value := ""
+		if i < len(labelValues) {
+			value = labelValues[i]
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(value))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func (m *metric) write(w io.Writer) {
+	m.mu.Lock()
+	values := make(map[string]float64, len(m.values)+len(m.funcs))
+	for k, v := range m.values {
+		values[k] = v
+	}
+	funcs := make(map[string]*func() float64, len(m.funcs))
+	for k, fn := range m.funcs {
+		funcs[k] = fn
+	}
+	m.mu.Unlock()
+
+	// Sampled outside the lock so a callback may touch other metrics.
+	for k, fn := range funcs {
+		values[k] = (*fn)()
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, m.name+k+" "+strconv.FormatFloat(values[k], 'g', -1, 64))
+	}
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// Counter is a monotonically increasing value, optionally split by labels.
+type Counter struct{ m *metric }
+
+// NewCounter registers a counter with the given label names.
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{m: newMetric("counter", name, help, labelNames)}
+}
+
+// Inc adds one to the series identified by labelValues.
+func (c *Counter) Inc(labelValues ...string) { c.m.add(1, labelValues) }
+
+// Add adds v (which must not be negative) to the series identified by labelValues.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.m.add(v, labelValues)
+}
+
+// Gauge is a value that can go up and down, optionally split by labels.
+type Gauge struct{ m *metric }
+
+// NewGauge registers a gauge with the given label names.
+func NewGauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{m: newMetric("gauge", name, help, labelNames)}
+}
+
+// Set replaces the value of the series identified by labelValues.
+func (g *Gauge) Set(v float64, labelValues ...string) { g.m.set(v, labelValues) }
+
+// SetFunc makes the series identified by labelValues report fn() on every
+// scrape, replacing any value or function set before. The returned function
+// removes the series, unless another SetFunc has replaced it since.
+func (g *Gauge) SetFunc(fn func() float64, labelValues ...string) (remove func()) {
+	key := g.m.labelKey(labelValues)
+	ref := &fn
+	g.m.mu.Lock()
+	delete(g.m.values, key)
+	g.m.funcs[key] = ref
+	g.m.mu.Unlock()
+	return func() {
+		g.m.mu.Lock()
+		if g.m.funcs[key] == ref {
+			delete(g.m.funcs, key)
+		}
+		g.m.mu.Unlock()
+	}
+}
+
+// Add adds v (which may be negative) to the series identified by labelValues.
+func (g *Gauge) Add(v float64, labelValues ...string) { g.m.add(v, labelValues) }
+
+// Inc adds one to the series identified by labelValues.
+func (g *Gauge) Inc(labelValues ...string) { g.m.add(1, labelValues) }
+
+// Dec subtracts one from the series identified by labelValues.
+func (g *Gauge) Dec(labelValues ...string) { g.m.add(-1, labelValues) }
+
+// WriteText writes every registered metric in the Prometheus text format.
+func WriteText(w io.Writer) {
+	registryMu.Lock()
+	metrics := append([]*metric(nil), registry...)
+	registryMu.Unlock()
+
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// Handler serves the registered metrics for Prometheus scrapes.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w)
+	})
+}
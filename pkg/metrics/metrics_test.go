@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("Content-Type = %q, want text/plain", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestHandler_ExposesRecordedActivity(t *testing.T) {
+	MessagesReceived.Inc("telegram")
+	AgentTurns.Inc("main")
+	AgentTurnsInFlight.Inc()
+	AgentTurnsInFlight.Dec()
+	ToolInvocations.Inc("exec", "ok")
+	ProviderErrors.Inc("gpt-4o")
+	TokensUsed.Add(12, "gpt-4o", "prompt")
+
+	body := scrape(t)
+	for _, want := range []string{
+		"# TYPE picoclaw_messages_received_total counter",
+		`picoclaw_messages_received_total{channel="telegram"}`,
+		`picoclaw_agent_turns_total{agent="main"}`,
+		"# TYPE picoclaw_agent_turns_in_flight gauge",
+		`picoclaw_tool_invocations_total{tool="exec",result="ok"}`,
+		`picoclaw_provider_errors_total{model="gpt-4o"}`,
+		`picoclaw_tokens_total{model="gpt-4o",type="prompt"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestCounter_AccumulatesAndEscapesLabels(t *testing.T) {
+	c := NewCounter("picoclaw_test_escape_total", "Test counter.", "name")
+	c.Inc(`a"b\c`)
+	c.Add(2, `a"b\c`)
+	c.Add(-5, `a"b\c`) // ignored: counters never decrease
+
+	body := scrape(t)
+	if want := `picoclaw_test_escape_total{name="a\"b\\c"} 3`; !strings.Contains(body, want) {
+		t.Fatalf("metrics output missing %q:\n%s", want, body)
+	}
+}
+
+func TestGauge_UnlabeledSet(t *testing.T) {
+	g := NewGauge("picoclaw_test_gauge", "Test gauge.")
+	g.Set(7)
+	g.Add(-2)
+
+	if body := scrape(t); !strings.Contains(body, "picoclaw_test_gauge 5\n") {
+		t.Fatalf("metrics output missing gauge value:\n%s", body)
+	}
+}
+
+func TestGauge_SetFuncSampledAtScrape(t *testing.T) {
+	g := NewGauge("picoclaw_test_func_gauge", "Test gauge.", "queue")
+	n := 1.0
+	remove := g.SetFunc(func() float64 { return n }, "a")
+
+	if body := scrape(t); !strings.Contains(body, `picoclaw_test_func_gauge{queue="a"} 1`+"\n") {
+		t.Fatalf("metrics output missing sampled value:\n%s", body)
+	}
+	n = 4
+	if body := scrape(t); !strings.Contains(body, `picoclaw_test_func_gauge{queue="a"} 4`+"\n") {
+		t.Fatalf("metrics output missing resampled value:\n%s", body)
+	}
+
+	// A stale remover must not drop a newer function for the same series.
+	g.SetFunc(func() float64 { return 9 }, "a")
+	remove()
+	if body := scrape(t); !strings.Contains(body, `picoclaw_test_func_gauge{queue="a"} 9`+"\n") {
+		t.Fatalf("metrics output missing replacement value:\n%s", body)
+	}
+}
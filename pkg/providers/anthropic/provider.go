@@ -10,6 +10,7 @@ import (
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 
+	"github.com/sipeed/picoclaw/pkg/providers/common"
 	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
@@ -169,9 +170,7 @@ func buildParams(
 					anthropic.NewUserMessage(anthropic.NewToolResultBlock(msg.ToolCallID, msg.Content, false)),
 				)
 			} else {
-				anthropicMessages = append(anthropicMessages,
-					anthropic.NewUserMessage(anthropic.NewTextBlock(msg.Content)),
-				)
+				anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(userBlocks(msg)...))
 			}
 		case "assistant":
 			if len(msg.ToolCalls) > 0 {
@@ -246,6 +245,24 @@ func buildParams(
 	return params, nil
 }
 
+// userBlocks converts a user message to content blocks. Base64 image data
+// URLs in msg.Media become image blocks, placed before the text as
+// Anthropic recommends; formats the API does not accept are skipped.
+func userBlocks(msg Message) []anthropic.ContentBlockParamUnion {
+	var blocks []anthropic.ContentBlockParamUnion
+	for _, m := range msg.Media {
+		mediaType, data, ok := common.ParseImageDataURL(m)
+		if !ok || !common.IsAnthropicImageType(mediaType) {
+			continue
+		}
+		blocks = append(blocks, anthropic.NewImageBlockBase64(mediaType, data))
+	}
+	if msg.Content != "" || len(blocks) == 0 {
+		blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
+	}
+	return blocks
+}
+
 // applyThinkingConfig sets thinking parameters based on the level value.
 // "adaptive" uses the adaptive thinking API (Claude 4.6+).
 // All other levels use budget_tokens which is universally supported.
@@ -32,6 +32,37 @@ func TestBuildParams_BasicMessage(t *testing.T) {
 	}
 }
 
+func TestBuildParams_UserMessageWithImage(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "What is in this picture?", Media: []string{
+			"data:image/png;base64,iVBORw0KGgo=",
+			"data:application/pdf;base64,JVBERi0=",
+			"data:image/svg+xml;base64,PHN2Zz4=",
+		}},
+	}
+	params, err := buildParams(messages, nil, "claude-sonnet-4.6", map[string]any{})
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+	if len(params.Messages) != 1 {
+		t.Fatalf("len(Messages) = %d, want 1", len(params.Messages))
+	}
+	content := params.Messages[0].Content
+	if len(content) != 2 {
+		t.Fatalf("len(Content) = %d, want image + text", len(content))
+	}
+	img := content[0].OfImage
+	if img == nil || img.Source.OfBase64 == nil {
+		t.Fatalf("Content[0] = %+v, want a base64 image block", content[0])
+	}
+	if img.Source.OfBase64.MediaType != "image/png" || img.Source.OfBase64.Data != "iVBORw0KGgo=" {
+		t.Errorf("image source = %+v, want image/png iVBORw0KGgo=", img.Source.OfBase64)
+	}
+	if content[1].OfText == nil || content[1].OfText.Text != "What is in this picture?" {
+		t.Errorf("Content[1] = %+v, want the question text", content[1])
+	}
+}
+
 func TestBuildParams_SystemMessage(t *testing.T) {
 	messages := []Message{
 		{Role: "system", Content: "You are helpful"},
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/providers/common"
 	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
@@ -204,7 +205,7 @@ func buildRequestBody(
 				// Regular user message
 				apiMessages = append(apiMessages, map[string]any{
 					"role":    "user",
-					"content": msg.Content,
+					"content": userContent(msg),
 				})
 			}
 
@@ -276,6 +277,34 @@ func buildRequestBody(
 	return result, nil
 }
 
+// userContent returns the content of a user message: plain text, or, when
+// msg.Media holds base64 image data URLs, image blocks followed by the text.
+// Image formats the API does not accept are skipped.
+func userContent(msg Message) any {
+	var blocks []any
+	for _, m := range msg.Media {
+		mediaType, data, ok := common.ParseImageDataURL(m)
+		if !ok || !common.IsAnthropicImageType(mediaType) {
+			continue
+		}
+		blocks = append(blocks, map[string]any{
+			"type": "image",
+			"source": map[string]any{
+				"type":       "base64",
+				"media_type": mediaType,
+				"data":       data,
+			},
+		})
+	}
+	if len(blocks) == 0 {
+		return msg.Content
+	}
+	if msg.Content != "" {
+		blocks = append(blocks, map[string]any{"type": "text", "text": msg.Content})
+	}
+	return blocks
+}
+
 // buildTools converts tool definitions to Anthropic format.
 func buildTools(tools []ToolDefinition) []any {
 	result := make([]any, len(tools))
@@ -43,6 +43,53 @@ func TestBuildRequestBody(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "user message with image",
+			messages: []Message{
+				{Role: "user", Content: "What is this?", Media: []string{"data:image/jpeg;base64,/9j/4AAQ"}},
+			},
+			model: "test-model",
+			options: map[string]any{
+				"max_tokens": 8192,
+			},
+			want: map[string]any{
+				"model":      "test-model",
+				"max_tokens": int64(8192),
+				"messages": []any{
+					map[string]any{
+						"role": "user",
+						"content": []any{
+							map[string]any{
+								"type": "image",
+								"source": map[string]any{
+									"type":       "base64",
+									"media_type": "image/jpeg",
+									"data":       "/9j/4AAQ",
+								},
+							},
+							map[string]any{"type": "text", "text": "What is this?"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unsupported image type is skipped",
+			messages: []Message{
+				{Role: "user", Content: "What is this?", Media: []string{"data:image/bmp;base64,Qk0="}},
+			},
+			model: "test-model",
+			options: map[string]any{
+				"max_tokens": 8192,
+			},
+			want: map[string]any{
+				"model":      "test-model",
+				"max_tokens": int64(8192),
+				"messages": []any{
+					map[string]any{"role": "user", "content": "What is this?"},
+				},
+			},
+		},
 		{
 			name: "user and assistant messages",
 			messages: []Message{
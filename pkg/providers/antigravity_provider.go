@@ -71,7 +71,7 @@ func (p *AntigravityProvider) Chat(
 	})
 
 	// Build the inner Gemini-format request
-	innerRequest := p.buildRequest(messages, tools, model, options)
+	innerRequest := buildGeminiRequest(messages, tools, options)
 
 	// Wrap in v1internal envelope (matches pi-ai SDK format)
 	envelope := map[string]any{
@@ -154,6 +154,8 @@ func (p *AntigravityProvider) GetDefaultModel() string {
 
 // --- Request building ---
 
+// The antigravity* request and response types are the standard Gemini
+// generateContent schema; Antigravity wraps them in its own envelope.
 type antigravityRequest struct {
 	Contents     []antigravityContent     `json:"contents"`
 	Tools        []antigravityTool        `json:"tools,omitempty"`
@@ -203,10 +205,11 @@ type antigravityGenConfig struct {
 	Temperature     float64 `json:"temperature,omitempty"`
 }
 
-func (p *AntigravityProvider) buildRequest(
+// buildGeminiRequest converts messages and tools to a Gemini generateContent
+// request. It is shared by the Antigravity and native Gemini providers.
+func buildGeminiRequest(
 	messages []Message,
 	tools []ToolDefinition,
-	model string,
 	options map[string]any,
 ) antigravityRequest {
 	req := antigravityRequest{}
@@ -561,7 +564,7 @@ func sanitizeSchemaForGemini(schema map[string]any) map[string]any {
 
 func createAntigravityTokenSource() func() (string, string, error) {
 	return func() (string, string, error) {
-		cred, err := auth.GetCredential("google-antigravity")
+		cred, err := auth.FreshCredential("google-antigravity")
 		if err != nil {
 			return "", "", fmt.Errorf("loading auth credentials: %w", err)
 		}
@@ -571,23 +574,6 @@ func createAntigravityTokenSource() func() (string, string, error) {
 			)
 		}
 
-		// Refresh if needed
-		if cred.NeedsRefresh() && cred.RefreshToken != "" {
-			oauthCfg := auth.GoogleAntigravityOAuthConfig()
-			refreshed, err := auth.RefreshAccessToken(cred, oauthCfg)
-			if err != nil {
-				return "", "", fmt.Errorf("refreshing token: %w", err)
-			}
-			refreshed.Email = cred.Email
-			if refreshed.ProjectID == "" {
-				refreshed.ProjectID = cred.ProjectID
-			}
-			if err := auth.SetCredential("google-antigravity", refreshed); err != nil {
-				return "", "", fmt.Errorf("saving refreshed token: %w", err)
-			}
-			cred = refreshed
-		}
-
 		if cred.IsExpired() {
 			return "", "", fmt.Errorf(
 				"antigravity credentials expired. Run: picoclaw auth login --provider google-antigravity",
@@ -3,8 +3,6 @@ package providers
 import "testing"
 
 func TestBuildRequestUsesFunctionFieldsWhenToolCallNameMissing(t *testing.T) {
-	p := &AntigravityProvider{}
-
 	messages := []Message{
 		{
 			Role: "assistant",
@@ -23,7 +21,7 @@ func TestBuildRequestUsesFunctionFieldsWhenToolCallNameMissing(t *testing.T) {
 		},
 	}
 
-	req := p.buildRequest(messages, nil, "", nil)
+	req := buildGeminiRequest(messages, nil, nil)
 	if len(req.Contents) != 2 {
 		t.Fatalf("expected 2 contents, got %d", len(req.Contents))
 	}
@@ -21,7 +21,7 @@ type (
 )
 
 const (
-	// azureAPIVersion is the Azure OpenAI API version used for all requests.
+	// azureAPIVersion is the Azure OpenAI API version used when none is configured.
 	azureAPIVersion       = "2024-10-21"
 	defaultRequestTimeout = common.DefaultRequestTimeout
 )
@@ -32,6 +32,7 @@ const (
 type Provider struct {
 	apiKey     string
 	apiBase    string
+	apiVersion string
 	httpClient *http.Client
 }
 
@@ -47,11 +48,21 @@ func WithRequestTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithAPIVersion sets the api-version query parameter sent with each request.
+func WithAPIVersion(version string) Option {
+	return func(p *Provider) {
+		if version = strings.TrimSpace(version); version != "" {
+			p.apiVersion = version
+		}
+	}
+}
+
 // NewProvider creates a new Azure OpenAI provider.
 func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
 	p := &Provider{
 		apiKey:     apiKey,
 		apiBase:    strings.TrimRight(apiBase, "/"),
+		apiVersion: azureAPIVersion,
 		httpClient: common.NewHTTPClient(proxy),
 	}
 
@@ -65,10 +76,11 @@ func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
 }
 
 // NewProviderWithTimeout creates a new Azure OpenAI provider with a custom request timeout in seconds.
-func NewProviderWithTimeout(apiKey, apiBase, proxy string, requestTimeoutSeconds int) *Provider {
+// Additional options are applied after the timeout.
+func NewProviderWithTimeout(apiKey, apiBase, proxy string, requestTimeoutSeconds int, opts ...Option) *Provider {
 	return NewProvider(
 		apiKey, apiBase, proxy,
-		WithRequestTimeout(time.Duration(requestTimeoutSeconds)*time.Second),
+		append([]Option{WithRequestTimeout(time.Duration(requestTimeoutSeconds) * time.Second)}, opts...)...,
 	)
 }
 
@@ -94,7 +106,7 @@ func (p *Provider) Chat(
 	if err != nil {
 		return nil, fmt.Errorf("failed to build Azure request URL: %w", err)
 	}
-	requestURL := base + "?api-version=" + azureAPIVersion
+	requestURL := base + "?api-version=" + url.QueryEscape(p.apiVersion)
 
 	// Build request body — no "model" field (Azure infers from deployment URL)
 	requestBody := map[string]any{
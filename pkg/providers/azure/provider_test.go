@@ -48,6 +48,29 @@ func TestProviderChat_AzureURLConstruction(t *testing.T) {
 	}
 }
 
+func TestProviderChat_AzureConfiguredAPIVersion(t *testing.T) {
+	var capturedAPIVersion string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedAPIVersion = r.URL.Query().Get("api-version")
+		writeValidResponse(w)
+	}))
+	defer server.Close()
+
+	p := NewProviderWithTimeout("test-key", server.URL, "", 0, WithAPIVersion("2025-04-01-preview"))
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "deployment", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if capturedAPIVersion != "2025-04-01-preview" {
+		t.Errorf("api-version = %q, want %q", capturedAPIVersion, "2025-04-01-preview")
+	}
+
+	if got := NewProvider("test-key", server.URL, "", WithAPIVersion("  ")).apiVersion; got != azureAPIVersion {
+		t.Errorf("blank api version = %q, want default %q", got, azureAPIVersion)
+	}
+}
+
 func TestProviderChat_AzureAuthHeader(t *testing.T) {
 	var capturedAPIKey string
 	var capturedAuth string
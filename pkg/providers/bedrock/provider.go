@@ -0,0 +1,377 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package bedrock implements the AWS Bedrock Runtime Converse API, which
+// serves Anthropic Claude and other models hosted on Bedrock.
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/common"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	ToolCall               = protocoltypes.ToolCall
+	FunctionCall           = protocoltypes.FunctionCall
+	LLMResponse            = protocoltypes.LLMResponse
+	UsageInfo              = protocoltypes.UsageInfo
+	Message                = protocoltypes.Message
+	ToolDefinition         = protocoltypes.ToolDefinition
+	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
+)
+
+const defaultRegion = "us-east-1"
+
+// emptyToolResult stands in for a tool result with no text.
+const emptyToolResult = "(no output)"
+
+// Provider sends chat requests to the Bedrock Converse API. Requests are
+// authorized with a Bedrock API key (bearer token) when one is configured,
+// otherwise they are signed with SigV4 using AWS credentials.
+type Provider struct {
+	apiKey      string
+	apiBase     string
+	region      string
+	credentials Credentials
+	httpClient  *http.Client
+	now         func() time.Time
+}
+
+// Option configures the Bedrock Provider.
+type Option func(*Provider)
+
+// WithRequestTimeout sets the HTTP request timeout.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		if timeout > 0 {
+			p.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithCredentials sets the AWS credentials used for SigV4 signing.
+func WithCredentials(creds Credentials) Option {
+	return func(p *Provider) {
+		p.credentials = creds
+	}
+}
+
+// NewProvider creates a Bedrock provider. apiBase may be empty, in which
+// case the regional Bedrock Runtime endpoint is used. The region comes from
+// the apiBase host when it is a Bedrock endpoint, else AWS_REGION or
+// AWS_DEFAULT_REGION, else us-east-1. AWS credentials default to the
+// standard AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// environment variables.
+func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
+	apiBase = strings.TrimRight(strings.TrimSpace(apiBase), "/")
+	region := regionFromEndpoint(apiBase)
+	if region == "" {
+		region = envRegion()
+	}
+	if apiBase == "" {
+		apiBase = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region)
+	}
+
+	p := &Provider{
+		apiKey:  apiKey,
+		apiBase: apiBase,
+		region:  region,
+		credentials: Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+		httpClient: common.NewHTTPClient(proxy),
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+
+	return p
+}
+
+// NewProviderWithTimeout creates a Bedrock provider with a custom request timeout in seconds.
+func NewProviderWithTimeout(apiKey, apiBase, proxy string, requestTimeoutSeconds int, opts ...Option) *Provider {
+	return NewProvider(
+		apiKey, apiBase, proxy,
+		append([]Option{WithRequestTimeout(time.Duration(requestTimeoutSeconds) * time.Second)}, opts...)...,
+	)
+}
+
+// Chat sends a Converse request for model, which is a Bedrock model ID or
+// inference profile such as "anthropic.claude-3-5-sonnet-20241022-v2:0".
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	if p.apiKey == "" && (p.credentials.AccessKeyID == "" || p.credentials.SecretAccessKey == "") {
+		return nil, fmt.Errorf(
+			"bedrock credentials not configured: set api_key or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	body, err := json.Marshal(buildConverseRequest(messages, tools, options))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Model IDs contain ':' and inference profile ARNs contain '/', so the
+	// ID is escaped as a single path segment.
+	endpoint, err := url.Parse(p.apiBase + "/model/" + uriEncode(model) + "/converse")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Bedrock request URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	} else {
+		signRequest(req, body, p.credentials, p.region, sigV4Service, p.now())
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, common.HandleErrorResponse(resp, p.apiBase)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return parseConverseResponse(respBody)
+}
+
+// GetDefaultModel returns an empty string as Bedrock model IDs are user-configured.
+func (p *Provider) GetDefaultModel() string {
+	return ""
+}
+
+// buildConverseRequest translates messages and tools to the Converse API.
+// Consecutive messages with the same role are merged because Converse
+// requires user and assistant turns to alternate; tool results are user
+// content blocks.
+func buildConverseRequest(messages []Message, tools []ToolDefinition, options map[string]any) map[string]any {
+	var system []map[string]any
+	var turns []map[string]any
+
+	appendBlocks := func(role string, blocks ...map[string]any) {
+		if len(blocks) == 0 {
+			return
+		}
+		if n := len(turns); n > 0 && turns[n-1]["role"] == role {
+			turns[n-1]["content"] = append(turns[n-1]["content"].([]map[string]any), blocks...)
+			return
+		}
+		turns = append(turns, map[string]any{"role": role, "content": blocks})
+	}
+
+	for _, msg := range messages {
+		switch {
+		case msg.Role == "system":
+			if msg.Content != "" {
+				system = append(system, map[string]any{"text": msg.Content})
+			}
+		case msg.Role == "tool" || (msg.Role == "user" && msg.ToolCallID != ""):
+			// Converse rejects blank text blocks, and a tool may return nothing.
+			result := msg.Content
+			if strings.TrimSpace(result) == "" {
+				result = emptyToolResult
+			}
+			appendBlocks("user", map[string]any{
+				"toolResult": map[string]any{
+					"toolUseId": msg.ToolCallID,
+					"content":   []map[string]any{{"text": result}},
+				},
+			})
+		case msg.Role == "assistant":
+			var blocks []map[string]any
+			if msg.Content != "" {
+				blocks = append(blocks, map[string]any{"text": msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				name, args := common.ToolCallNameAndArguments(tc)
+				if strings.TrimSpace(name) == "" {
+					continue
+				}
+				if args == nil {
+					args = map[string]any{}
+				}
+				blocks = append(blocks, map[string]any{
+					"toolUse": map[string]any{"toolUseId": tc.ID, "name": name, "input": args},
+				})
+			}
+			appendBlocks("assistant", blocks...)
+		default:
+			if msg.Content != "" {
+				appendBlocks("user", map[string]any{"text": msg.Content})
+			}
+		}
+	}
+
+	request := map[string]any{"messages": turns}
+	if len(system) > 0 {
+		request["system"] = system
+	}
+
+	inference := map[string]any{}
+	if maxTokens, ok := common.AsInt(options["max_tokens"]); ok {
+		inference["maxTokens"] = maxTokens
+	}
+	if temperature, ok := common.AsFloat(options["temperature"]); ok {
+		inference["temperature"] = temperature
+	}
+	if len(inference) > 0 {
+		request["inferenceConfig"] = inference
+	}
+
+	if len(tools) > 0 {
+		specs := make([]map[string]any, 0, len(tools))
+		for _, tool := range tools {
+			schema := tool.Function.Parameters
+			if schema == nil {
+				schema = map[string]any{"type": "object", "properties": map[string]any{}}
+			}
+			specs = append(specs, map[string]any{
+				"toolSpec": map[string]any{
+					"name":        tool.Function.Name,
+					"description": tool.Function.Description,
+					"inputSchema": map[string]any{"json": schema},
+				},
+			})
+		}
+		request["toolConfig"] = map[string]any{"tools": specs}
+	}
+
+	return request
+}
+
+type converseResponse struct {
+	Output struct {
+		Message struct {
+			Content []struct {
+				Text    string `json:"text"`
+				ToolUse *struct {
+					ToolUseID string         `json:"toolUseId"`
+					Name      string         `json:"name"`
+					Input     map[string]any `json:"input"`
+				} `json:"toolUse"`
+			} `json:"content"`
+		} `json:"message"`
+	} `json:"output"`
+	StopReason string `json:"stopReason"`
+	Usage      struct {
+		InputTokens  int `json:"inputTokens"`
+		OutputTokens int `json:"outputTokens"`
+		TotalTokens  int `json:"totalTokens"`
+	} `json:"usage"`
+}
+
+// parseConverseResponse maps a Converse response onto LLMResponse, using the
+// same finish reasons as the other providers.
+func parseConverseResponse(body []byte) (*LLMResponse, error) {
+	var resp converseResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var content strings.Builder
+	toolCalls := make([]ToolCall, 0)
+	for _, block := range resp.Output.Message.Content {
+		if block.ToolUse != nil {
+			argsJSON, _ := json.Marshal(block.ToolUse.Input)
+			toolCalls = append(toolCalls, ToolCall{
+				ID:        block.ToolUse.ToolUseID,
+				Name:      block.ToolUse.Name,
+				Arguments: block.ToolUse.Input,
+				Function: &FunctionCall{
+					Name:      block.ToolUse.Name,
+					Arguments: string(argsJSON),
+				},
+			})
+			continue
+		}
+		content.WriteString(block.Text)
+	}
+
+	finishReason := "stop"
+	switch resp.StopReason {
+	case "tool_use":
+		finishReason = "tool_calls"
+	case "max_tokens":
+		finishReason = "length"
+	case "content_filtered", "guardrail_intervened":
+		finishReason = "content_filter"
+	}
+
+	total := resp.Usage.TotalTokens
+	if total == 0 {
+		total = resp.Usage.InputTokens + resp.Usage.OutputTokens
+	}
+
+	return &LLMResponse{
+		Content:      content.String(),
+		ToolCalls:    toolCalls,
+		FinishReason: finishReason,
+		Usage: &UsageInfo{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      total,
+		},
+	}, nil
+}
+
+// regionFromEndpoint extracts the region from a Bedrock Runtime host such as
+// bedrock-runtime.eu-west-1.amazonaws.com.
+func regionFromEndpoint(apiBase string) string {
+	if apiBase == "" {
+		return ""
+	}
+	u, err := url.Parse(apiBase)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(u.Hostname(), ".")
+	for i := 0; i+2 < len(parts); i++ {
+		if strings.HasPrefix(parts[i], "bedrock-runtime") && parts[i+2] == "amazonaws" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+func envRegion() string {
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := strings.TrimSpace(os.Getenv(name)); region != "" {
+			return region
+		}
+	}
+	return defaultRegion
+}
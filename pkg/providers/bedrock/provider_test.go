@@ -0,0 +1,260 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordedConverseResponse is a Converse API response for a Claude model
+// that answered with text and a tool call.
+const recordedConverseResponse = `{
+  "output": {
+    "message": {
+      "role": "assistant",
+      "content": [
+        {"text": "Let me check the weather."},
+        {"toolUse": {"toolUseId": "tooluse_kZJMlvQmRJ6eAyJE5GIl7Q", "name": "get_weather", "input": {"city": "Seattle"}}}
+      ]
+    }
+  },
+  "stopReason": "tool_use",
+  "usage": {"inputTokens": 412, "outputTokens": 56, "totalTokens": 468},
+  "metrics": {"latencyMs": 1210}
+}`
+
+func TestBuildConverseRequest(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Content: "Weather in Seattle?"},
+		{Role: "assistant", Content: "Checking.", ToolCalls: []ToolCall{{
+			ID:        "call_1",
+			Name:      "get_weather",
+			Arguments: map[string]any{"city": "Seattle"},
+		}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "Rainy, 12C"},
+		{Role: "user", Content: "Thanks!"},
+	}
+	tools := []ToolDefinition{{
+		Type: "function",
+		Function: ToolFunctionDefinition{
+			Name:        "get_weather",
+			Description: "Get the weather",
+			Parameters:  map[string]any{"type": "object"},
+		},
+	}}
+
+	got := buildConverseRequest(messages, tools, map[string]any{"max_tokens": 1024, "temperature": 0.2})
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `{
+		"inferenceConfig": {"maxTokens": 1024, "temperature": 0.2},
+		"messages": [
+			{"role": "user", "content": [{"text": "Weather in Seattle?"}]},
+			{"role": "assistant", "content": [
+				{"text": "Checking."},
+				{"toolUse": {"toolUseId": "call_1", "name": "get_weather", "input": {"city": "Seattle"}}}
+			]},
+			{"role": "user", "content": [
+				{"toolResult": {"toolUseId": "call_1", "content": [{"text": "Rainy, 12C"}]}},
+				{"text": "Thanks!"}
+			]}
+		],
+		"system": [{"text": "You are helpful."}],
+		"toolConfig": {"tools": [{"toolSpec": {
+			"name": "get_weather",
+			"description": "Get the weather",
+			"inputSchema": {"json": {"type": "object"}}
+		}}]}
+	}`
+	assertJSONEqual(t, string(gotJSON), want)
+}
+
+func TestBuildConverseRequest_EmptyToolResult(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "Clean up."},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Name: "cleanup"}}},
+		{Role: "tool", ToolCallID: "call_1", Content: ""},
+	}
+
+	got := buildConverseRequest(messages, nil, nil)
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `{
+		"messages": [
+			{"role": "user", "content": [{"text": "Clean up."}]},
+			{"role": "assistant", "content": [
+				{"toolUse": {"toolUseId": "call_1", "name": "cleanup", "input": {}}}
+			]},
+			{"role": "user", "content": [
+				{"toolResult": {"toolUseId": "call_1", "content": [{"text": "(no output)"}]}}
+			]}
+		]
+	}`
+	assertJSONEqual(t, string(gotJSON), want)
+}
+
+func TestParseConverseResponse(t *testing.T) {
+	resp, err := parseConverseResponse([]byte(recordedConverseResponse))
+	if err != nil {
+		t.Fatalf("parseConverseResponse() error = %v", err)
+	}
+
+	if resp.Content != "Let me check the weather." {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want %q", resp.FinishReason, "tool_calls")
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("len(ToolCalls) = %d, want 1", len(resp.ToolCalls))
+	}
+	tc := resp.ToolCalls[0]
+	if tc.ID != "tooluse_kZJMlvQmRJ6eAyJE5GIl7Q" || tc.Name != "get_weather" {
+		t.Errorf("tool call = %+v", tc)
+	}
+	if !reflect.DeepEqual(tc.Arguments, map[string]any{"city": "Seattle"}) {
+		t.Errorf("Arguments = %v", tc.Arguments)
+	}
+	if tc.Function == nil || tc.Function.Arguments != `{"city":"Seattle"}` {
+		t.Errorf("Function = %+v", tc.Function)
+	}
+	if resp.Usage.PromptTokens != 412 || resp.Usage.CompletionTokens != 56 || resp.Usage.TotalTokens != 468 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+}
+
+func TestProviderChat_ConverseRequest(t *testing.T) {
+	var gotPath, gotAuth, gotDate string
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotAuth = r.Header.Get("Authorization")
+		gotDate = r.Header.Get("X-Amz-Date")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(recordedConverseResponse))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "", WithCredentials(Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+	}))
+	p.region = "us-west-2"
+	p.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil,
+		"anthropic.claude-3-5-sonnet-20241022-v2:0", map[string]any{"max_tokens": 256})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q", resp.FinishReason)
+	}
+
+	if gotPath != "/model/anthropic.claude-3-5-sonnet-20241022-v2%3A0/converse" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotDate != "20260102T030405Z" {
+		t.Errorf("X-Amz-Date = %q", gotDate)
+	}
+	wantPrefix := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260102/us-west-2/bedrock/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, Signature="
+	if !strings.HasPrefix(gotAuth, wantPrefix) {
+		t.Errorf("Authorization = %q, want prefix %q", gotAuth, wantPrefix)
+	}
+	if gotBody["inferenceConfig"].(map[string]any)["maxTokens"] != float64(256) {
+		t.Errorf("body = %v", gotBody)
+	}
+}
+
+func TestProviderChat_APIKeyUsesBearerAuth(t *testing.T) {
+	var gotAuth, gotDate string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotDate = r.Header.Get("X-Amz-Date")
+		w.Write([]byte(recordedConverseResponse))
+	}))
+	defer server.Close()
+
+	p := NewProvider("bedrock-api-key", server.URL, "")
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "model", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if gotAuth != "Bearer bedrock-api-key" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotDate != "" {
+		t.Errorf("X-Amz-Date = %q, want unsigned request", gotDate)
+	}
+}
+
+func TestProviderChat_MissingCredentials(t *testing.T) {
+	p := NewProvider("", "https://bedrock-runtime.us-east-1.amazonaws.com", "",
+		WithCredentials(Credentials{}))
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "model", nil)
+	if err == nil || !strings.Contains(err.Error(), "credentials not configured") {
+		t.Fatalf("Chat() error = %v, want missing credentials", err)
+	}
+}
+
+func TestNewProvider_Region(t *testing.T) {
+	t.Setenv("AWS_REGION", "ap-southeast-1")
+
+	p := NewProvider("k", "https://bedrock-runtime.eu-west-1.amazonaws.com/", "")
+	if p.region != "eu-west-1" || p.apiBase != "https://bedrock-runtime.eu-west-1.amazonaws.com" {
+		t.Errorf("region, apiBase = %q, %q", p.region, p.apiBase)
+	}
+
+	p = NewProvider("k", "", "")
+	if p.region != "ap-southeast-1" || p.apiBase != "https://bedrock-runtime.ap-southeast-1.amazonaws.com" {
+		t.Errorf("region, apiBase = %q, %q", p.region, p.apiBase)
+	}
+}
+
+// TestSignRequest_AWSTestSuite checks the signer against the "get-vanilla"
+// case from the AWS SigV4 test suite.
+func TestSignRequest_AWSTestSuite(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signRequest(req, nil, Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func assertJSONEqual(t *testing.T, got, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal([]byte(got), &g); err != nil {
+		t.Fatalf("invalid got JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("invalid want JSON: %v", err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("JSON mismatch\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/sipeed/picoclaw/pkg/auth"
 	anthropicprovider "github.com/sipeed/picoclaw/pkg/providers/anthropic"
 )
 
@@ -57,7 +58,7 @@ func (p *ClaudeProvider) GetDefaultModel() string {
 
 func createClaudeTokenSource() func() (string, error) {
 	return func() (string, error) {
-		cred, err := getCredential("anthropic")
+		cred, err := auth.FreshCredential("anthropic")
 		if err != nil {
 			return "", fmt.Errorf("loading auth credentials: %w", err)
 		}
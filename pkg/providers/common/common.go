@@ -253,6 +253,25 @@ func DecodeToolCallArguments(raw json.RawMessage, name string) map[string]any {
 	}
 }
 
+// ToolCallNameAndArguments returns the function name and decoded arguments of
+// a tool call from conversation history. Calls restored from a session only
+// carry the JSON-encoded Function.Arguments, so that is used when the decoded
+// Arguments map is absent.
+func ToolCallNameAndArguments(tc ToolCall) (string, map[string]any) {
+	name := tc.Name
+	if name == "" && tc.Function != nil {
+		name = tc.Function.Name
+	}
+	args := tc.Arguments
+	if args == nil && tc.Function != nil {
+		args = DecodeToolCallArguments(json.RawMessage(tc.Function.Arguments), name)
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	return name, args
+}
+
 // --- HTTP response helpers ---
 
 // HandleErrorResponse reads a non-200 response body and returns an appropriate error.
@@ -345,6 +364,33 @@ func leadingTrimmedPrefix(body []byte, maxLen int) []byte {
 	return nil
 }
 
+// --- Media helpers ---
+
+// ParseImageDataURL splits a base64 image data URL such as
+// "data:image/png;base64,iVBOR..." into its media type and payload. It
+// returns ok=false for anything else, including non-image data URLs.
+func ParseImageDataURL(s string) (mediaType, data string, ok bool) {
+	header, data, found := strings.Cut(s, ",")
+	if !found || !strings.HasPrefix(header, "data:image/") {
+		return "", "", false
+	}
+	mediaType, found = strings.CutSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+	if !found || data == "" {
+		return "", "", false
+	}
+	return mediaType, data, true
+}
+
+// IsAnthropicImageType reports whether mediaType is an image format the
+// Anthropic Messages API accepts: JPEG, PNG, GIF or WebP.
+func IsAnthropicImageType(mediaType string) bool {
+	switch mediaType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
 // --- Numeric helpers ---
 
 // AsInt converts various numeric types to int.
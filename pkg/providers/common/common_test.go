@@ -252,6 +252,32 @@ func TestDecodeToolCallArguments_EmptyStringJSON(t *testing.T) {
 	}
 }
 
+func TestToolCallNameAndArguments_FromFunction(t *testing.T) {
+	tc := ToolCall{
+		ID:       "call_1",
+		Function: &FunctionCall{Name: "get_weather", Arguments: `{"city":"Oslo"}`},
+	}
+	name, args := ToolCallNameAndArguments(tc)
+	if name != "get_weather" {
+		t.Errorf("name = %q, want get_weather", name)
+	}
+	if args["city"] != "Oslo" {
+		t.Errorf("city = %v, want Oslo", args["city"])
+	}
+}
+
+func TestToolCallNameAndArguments_PrefersDecodedFields(t *testing.T) {
+	tc := ToolCall{
+		Name:      "read_file",
+		Arguments: map[string]any{"path": "a.txt"},
+		Function:  &FunctionCall{Name: "stale", Arguments: `{"path":"b.txt"}`},
+	}
+	name, args := ToolCallNameAndArguments(tc)
+	if name != "read_file" || args["path"] != "a.txt" {
+		t.Errorf("got (%q, %v), want (read_file, path=a.txt)", name, args)
+	}
+}
+
 // --- HandleErrorResponse tests ---
 
 func TestHandleErrorResponse_JSONError(t *testing.T) {
@@ -556,3 +582,39 @@ func TestParseResponse_WithThoughtSignature(t *testing.T) {
 			out.ToolCalls[0].ExtraContent.Google.ThoughtSignature, "sig123")
 	}
 }
+
+func TestParseImageDataURL(t *testing.T) {
+	tests := []struct {
+		in        string
+		mediaType string
+		data      string
+		ok        bool
+	}{
+		{"data:image/png;base64,iVBORw0KGgo=", "image/png", "iVBORw0KGgo=", true},
+		{"data:image/jpeg;base64,/9j/4AAQ", "image/jpeg", "/9j/4AAQ", true},
+		{"data:application/pdf;base64,JVBERi0=", "", "", false},
+		{"data:image/png,rawdata", "", "", false},
+		{"data:image/png;base64,", "", "", false},
+		{"https://example.com/cat.png", "", "", false},
+	}
+	for _, tt := range tests {
+		mediaType, data, ok := ParseImageDataURL(tt.in)
+		if mediaType != tt.mediaType || data != tt.data || ok != tt.ok {
+			t.Errorf("ParseImageDataURL(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.in, mediaType, data, ok, tt.mediaType, tt.data, tt.ok)
+		}
+	}
+}
+
+func TestIsAnthropicImageType(t *testing.T) {
+	for _, mediaType := range []string{"image/jpeg", "image/png", "image/gif", "image/webp"} {
+		if !IsAnthropicImageType(mediaType) {
+			t.Errorf("IsAnthropicImageType(%q) = false, want true", mediaType)
+		}
+	}
+	for _, mediaType := range []string{"image/bmp", "image/svg+xml", "image/heic", "application/pdf"} {
+		if IsAnthropicImageType(mediaType) {
+			t.Errorf("IsAnthropicImageType(%q) = true, want false", mediaType)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"regexp"
 	"strings"
 )
@@ -113,8 +114,10 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 		return nil
 	}
 
-	// Context deadline exceeded: treat as timeout, always fallback.
-	if err == context.DeadlineExceeded {
+	// Context deadline exceeded or the configured request timeout: treat as
+	// timeout, always fallback.
+	var timeoutErr *RequestTimeoutError
+	if err == context.DeadlineExceeded || errors.As(err, &timeoutErr) {
 		return &FailoverError{
 			Reason:   FailoverTimeout,
 			Provider: provider,
@@ -8,7 +8,10 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 )
 
-const defaultAnthropicAPIBase = "https://api.anthropic.com/v1"
+const (
+	defaultAnthropicAPIBase = "https://api.anthropic.com/v1"
+	defaultCopilotAPIBase   = "localhost:4321"
+)
 
 var getCredential = auth.GetCredential
 
@@ -235,7 +238,7 @@ func resolveProviderSelection(cfg *config.Config) (providerSelection, error) {
 			if cfg.Providers.GitHubCopilot.APIBase != "" {
 				sel.apiBase = cfg.Providers.GitHubCopilot.APIBase
 			} else {
-				sel.apiBase = "localhost:4321"
+				sel.apiBase = defaultCopilotAPIBase
 			}
 			sel.connectMode = cfg.Providers.GitHubCopilot.ConnectMode
 			return sel, nil
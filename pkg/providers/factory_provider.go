@@ -12,6 +12,8 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 	anthropicmessages "github.com/sipeed/picoclaw/pkg/providers/anthropic_messages"
 	"github.com/sipeed/picoclaw/pkg/providers/azure"
+	"github.com/sipeed/picoclaw/pkg/providers/bedrock"
+	"github.com/sipeed/picoclaw/pkg/providers/ollama"
 )
 
 // createClaudeAuthProvider creates a Claude provider using OAuth credentials from auth store.
@@ -55,8 +57,8 @@ func ExtractProtocol(model string) (protocol, modelID string) {
 
 // CreateProviderFromConfig creates a provider based on the ModelConfig.
 // It uses the protocol prefix in the Model field to determine which provider to create.
-// Supported protocols: openai, litellm, novita, anthropic, anthropic-messages,
-// antigravity, claude-cli, codex-cli, github-copilot
+// Supported protocols: openai, azure, bedrock, litellm, novita, ollama, gemini, anthropic,
+// anthropic-messages, antigravity, claude-cli, codex-cli, github-copilot
 // Returns the provider, the model ID (without protocol prefix), and any error.
 func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, error) {
 	if cfg == nil {
@@ -111,10 +113,21 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 			cfg.APIBase,
 			cfg.Proxy,
 			cfg.RequestTimeout,
+			azure.WithAPIVersion(cfg.APIVersion),
 		), modelID, nil
 
-	case "litellm", "openrouter", "groq", "zhipu", "gemini", "nvidia",
-		"ollama", "moonshot", "shengsuanyun", "deepseek", "cerebras",
+	case "bedrock":
+		// AWS Bedrock Converse API. api_key is a Bedrock API key; without one,
+		// requests are SigV4-signed with the standard AWS credential env vars.
+		return bedrock.NewProviderWithTimeout(
+			cfg.APIKey,
+			cfg.APIBase,
+			cfg.Proxy,
+			cfg.RequestTimeout,
+		), modelID, nil
+
+	case "litellm", "openrouter", "groq", "zhipu", "nvidia",
+		"moonshot", "shengsuanyun", "deepseek", "cerebras",
 		"vivgrid", "volcengine", "vllm", "qwen", "qwen-intl", "qwen-international", "dashscope-intl",
 		"qwen-us", "dashscope-us", "mistral", "avian", "minimax", "longcat", "modelscope", "novita",
 		"coding-plan", "alibaba-coding", "qwen-coding":
@@ -134,6 +147,46 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 			cfg.RequestTimeout,
 		), modelID, nil
 
+	case "gemini":
+		// Other api_base hosts are OpenAI-compatible gateways that expose
+		// Gemini models, as before the native provider existed.
+		if !isGoogleGeminiAPIBase(cfg.APIBase) {
+			return NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
+				cfg.APIKey,
+				cfg.APIBase,
+				cfg.Proxy,
+				cfg.MaxTokensField,
+				cfg.RequestTimeout,
+			), modelID, nil
+		}
+		// Native Gemini generateContent API on Google's endpoint.
+		if cfg.APIKey == "" {
+			return nil, "", fmt.Errorf("api_key is required for gemini protocol (model: %s)", cfg.Model)
+		}
+		return NewGeminiProvider(
+			cfg.APIKey,
+			cfg.APIBase,
+			cfg.Proxy,
+			cfg.RequestTimeout,
+		), modelID, nil
+
+	case "ollama":
+		// Native /api/chat, falling back to Ollama's OpenAI-compatible API
+		if cfg.APIKey == "" && cfg.APIBase == "" {
+			return nil, "", fmt.Errorf("api_key or api_base is required for HTTP-based protocol %q", protocol)
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		return ollama.NewProviderWithMaxTokensFieldAndTimeout(
+			cfg.APIKey,
+			apiBase,
+			cfg.Proxy,
+			cfg.MaxTokensField,
+			cfg.RequestTimeout,
+		), modelID, nil
+
 	case "anthropic":
 		if cfg.AuthMethod == "oauth" || cfg.AuthMethod == "token" {
 			// Use OAuth credentials from auth store
@@ -146,7 +199,7 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		// Use API key with HTTP API
 		apiBase := cfg.APIBase
 		if apiBase == "" {
-			apiBase = "https://api.anthropic.com/v1"
+			apiBase = defaultModelAPIBase(cfg, protocol)
 		}
 		if cfg.APIKey == "" {
 			return nil, "", fmt.Errorf("api_key is required for anthropic protocol (model: %s)", cfg.Model)
@@ -163,7 +216,7 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		// Anthropic Messages API with native format (HTTP-based, no SDK)
 		apiBase := cfg.APIBase
 		if apiBase == "" {
-			apiBase = "https://api.anthropic.com/v1"
+			apiBase = defaultModelAPIBase(cfg, protocol)
 		}
 		if cfg.APIKey == "" {
 			return nil, "", fmt.Errorf("api_key is required for anthropic-messages protocol (model: %s)", cfg.Model)
@@ -207,14 +260,16 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		return NewCodexCliProvider(workspace), modelID, nil
 
 	case "github-copilot", "copilot":
-		apiBase := cfg.APIBase
-		if apiBase == "" {
-			apiBase = "localhost:4321"
-		}
 		connectMode := cfg.ConnectMode
 		if connectMode == "" {
 			connectMode = "grpc"
 		}
+		// In stdio mode api_base is the CLI executable path; the SDK
+		// defaults to "copilot" on PATH when it is empty.
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = defaultModelAPIBase(cfg, protocol)
+		}
 		provider, err := NewGitHubCopilotProvider(apiBase, connectMode, modelID)
 		if err != nil {
 			return nil, "", err
@@ -226,62 +281,52 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 	}
 }
 
-// getDefaultAPIBase returns the default API base URL for a given protocol.
-func getDefaultAPIBase(protocol string) string {
+// OAuthCredentialProviders returns the auth store names of the credentials
+// used by model_list entries with auth_method "oauth" (or antigravity, which
+// is OAuth-only), without duplicates.
+func OAuthCredentialProviders(cfg *config.Config) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range cfg.ModelList {
+		protocol, _ := ExtractProtocol(m.Model)
+		name := ""
+		switch {
+		case protocol == "antigravity":
+			name = "google-antigravity"
+		case m.AuthMethod != "oauth":
+			continue
+		case protocol == "openai":
+			name = "openai"
+		case protocol == "anthropic":
+			name = "anthropic"
+		default:
+			continue
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// defaultModelAPIBase returns the api_base CreateProviderFromConfig uses for
+// a model_list entry that sets none. Copilot only has one in grpc mode; in
+// stdio mode api_base is the CLI path and the SDK picks its own default.
+func defaultModelAPIBase(cfg *config.ModelConfig, protocol string) string {
 	switch protocol {
-	case "openai":
-		return "https://api.openai.com/v1"
-	case "openrouter":
-		return "https://openrouter.ai/api/v1"
-	case "litellm":
-		return "http://localhost:4000/v1"
-	case "novita":
-		return "https://api.novita.ai/openai"
-	case "groq":
-		return "https://api.groq.com/openai/v1"
-	case "zhipu":
-		return "https://open.bigmodel.cn/api/paas/v4"
-	case "gemini":
-		return "https://generativelanguage.googleapis.com/v1beta"
-	case "nvidia":
-		return "https://integrate.api.nvidia.com/v1"
-	case "ollama":
-		return "http://localhost:11434/v1"
-	case "moonshot":
-		return "https://api.moonshot.cn/v1"
-	case "shengsuanyun":
-		return "https://router.shengsuanyun.com/api/v1"
-	case "deepseek":
-		return "https://api.deepseek.com/v1"
-	case "cerebras":
-		return "https://api.cerebras.ai/v1"
-	case "vivgrid":
-		return "https://api.vivgrid.com/v1"
-	case "volcengine":
-		return "https://ark.cn-beijing.volces.com/api/v3"
-	case "qwen":
-		return "https://dashscope.aliyuncs.com/compatible-mode/v1"
-	case "qwen-intl", "qwen-international", "dashscope-intl":
-		return "https://dashscope-intl.aliyuncs.com/compatible-mode/v1"
-	case "qwen-us", "dashscope-us":
-		return "https://dashscope-us.aliyuncs.com/compatible-mode/v1"
-	case "coding-plan", "alibaba-coding", "qwen-coding":
-		return "https://coding-intl.dashscope.aliyuncs.com/v1"
-	case "coding-plan-anthropic", "alibaba-coding-anthropic":
-		return "https://coding-intl.dashscope.aliyuncs.com/apps/anthropic"
-	case "vllm":
-		return "http://localhost:8000/v1"
-	case "mistral":
-		return "https://api.mistral.ai/v1"
-	case "avian":
-		return "https://api.avian.io/v1"
-	case "minimax":
-		return "https://api.minimaxi.com/v1"
-	case "longcat":
-		return "https://api.longcat.chat/openai"
-	case "modelscope":
-		return "https://api-inference.modelscope.cn/v1"
-	default:
+	case "anthropic", "anthropic-messages":
+		return defaultAnthropicAPIBase
+	case "github-copilot", "copilot":
+		if cfg.ConnectMode == "" || cfg.ConnectMode == "grpc" {
+			return defaultCopilotAPIBase
+		}
 		return ""
 	}
+	return getDefaultAPIBase(protocol)
+}
+
+// getDefaultAPIBase returns the default API base URL for a given protocol.
+func getDefaultAPIBase(protocol string) string {
+	return config.DefaultAPIBase(protocol)
 }
@@ -6,6 +6,7 @@
 package providers
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -13,6 +14,8 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers/bedrock"
+	"github.com/sipeed/picoclaw/pkg/providers/ollama"
 )
 
 func TestExtractProtocol(t *testing.T) {
@@ -119,7 +122,6 @@ func TestCreateProviderFromConfig_DefaultAPIBase(t *testing.T) {
 		{"qwen", "qwen"},
 		{"vllm", "vllm"},
 		{"deepseek", "deepseek"},
-		{"ollama", "ollama"},
 		{"longcat", "longcat"},
 		{"modelscope", "modelscope"},
 	}
@@ -407,6 +409,48 @@ func TestCreateProviderFromConfig_RequestTimeoutPropagation(t *testing.T) {
 	}
 }
 
+func TestCreateProviderFromConfig_MaxTokensField(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.ModelConfig{
+		ModelName:      "test-max-tokens-field",
+		Model:          "openai/gpt-4.1",
+		APIBase:        server.URL,
+		MaxTokensField: "max_completion_tokens",
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	_, err = provider.Chat(
+		t.Context(),
+		[]Message{{Role: "user", Content: "hi"}},
+		nil,
+		modelID,
+		map[string]any{"max_tokens": 256},
+	)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if got, ok := requestBody["max_completion_tokens"]; !ok || got != float64(256) {
+		t.Fatalf("max_completion_tokens = %v, want 256 (body: %v)", got, requestBody)
+	}
+	if _, ok := requestBody["max_tokens"]; ok {
+		t.Fatalf("did not expect max_tokens when max_tokens_field is set (body: %v)", requestBody)
+	}
+}
+
 func TestCreateProviderFromConfig_Azure(t *testing.T) {
 	cfg := &config.ModelConfig{
 		ModelName: "azure-gpt5",
@@ -427,6 +471,84 @@ func TestCreateProviderFromConfig_Azure(t *testing.T) {
 	}
 }
 
+func TestCreateProviderFromConfig_AzureAPIVersion(t *testing.T) {
+	var gotPath, gotVersion, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotVersion = r.URL.Query().Get("api-version")
+		gotKey = r.Header.Get("Api-Key")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.ModelConfig{
+		ModelName:  "azure-gpt5",
+		Model:      "azure/my-gpt5-deployment",
+		APIKey:     "test-azure-key",
+		APIBase:    server.URL,
+		APIVersion: "2025-04-01-preview",
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, err = provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, modelID, nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if gotPath != "/openai/deployments/my-gpt5-deployment/chat/completions" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotVersion != "2025-04-01-preview" {
+		t.Errorf("api-version = %q, want %q", gotVersion, "2025-04-01-preview")
+	}
+	if gotKey != "test-azure-key" {
+		t.Errorf("api-key header = %q, want %q", gotKey, "test-azure-key")
+	}
+}
+
+func TestCreateProviderFromConfig_Bedrock(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "claude-bedrock",
+		Model:     "bedrock/anthropic.claude-3-5-sonnet-20241022-v2:0",
+		APIBase:   "https://bedrock-runtime.us-west-2.amazonaws.com",
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*bedrock.Provider); !ok {
+		t.Fatalf("expected *bedrock.Provider, got %T", provider)
+	}
+	if modelID != "anthropic.claude-3-5-sonnet-20241022-v2:0" {
+		t.Errorf("modelID = %q, want %q", modelID, "anthropic.claude-3-5-sonnet-20241022-v2:0")
+	}
+}
+
+func TestCreateProviderFromConfig_Ollama(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "local",
+		Model:     "ollama/llama3",
+		APIBase:   "http://localhost:11434/v1",
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*ollama.Provider); !ok {
+		t.Fatalf("expected *ollama.Provider, got %T", provider)
+	}
+	if _, ok := provider.(StreamingProvider); !ok {
+		t.Error("ollama provider should support streaming")
+	}
+	if modelID != "llama3" {
+		t.Errorf("modelID = %q, want %q", modelID, "llama3")
+	}
+}
+
 func TestCreateProviderFromConfig_AzureOpenAIAlias(t *testing.T) {
 	cfg := &config.ModelConfig{
 		ModelName: "azure-gpt4",
@@ -603,3 +725,19 @@ func TestGetDefaultAPIBase_QwenUSAliases(t *testing.T) {
 		}
 	}
 }
+
+func TestOAuthCredentialProviders(t *testing.T) {
+	cfg := &config.Config{ModelList: []config.ModelConfig{
+		{ModelName: "codex", Model: "openai/gpt-5.4", AuthMethod: "oauth"},
+		{ModelName: "codex-mini", Model: "openai/gpt-5.4-mini", AuthMethod: "oauth"},
+		{ModelName: "gpt", Model: "openai/gpt-4o", APIKey: "sk-test"},
+		{ModelName: "ag", Model: "antigravity/gemini-3-flash"},
+		{ModelName: "claude", Model: "anthropic/claude-sonnet-4.6", AuthMethod: "token"},
+	}}
+
+	got := OAuthCredentialProviders(cfg)
+	want := []string{"openai", "google-antigravity"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("OAuthCredentialProviders() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/common"
+)
+
+const (
+	geminiAPIHost        = "generativelanguage.googleapis.com"
+	defaultGeminiAPIBase = "https://" + geminiAPIHost + "/v1beta"
+)
+
+// GeminiProvider implements LLMProvider using Google's native Gemini API
+// (models/{model}:generateContent) with an API key. Tools are sent as
+// functionDeclarations and functionCall parts are returned as ToolCalls.
+type GeminiProvider struct {
+	apiKey     string
+	apiBase    string
+	httpClient *http.Client
+}
+
+// NewGeminiProvider creates a native Gemini provider. An api_base pointing at
+// Google's OpenAI-compatible path (".../v1beta/openai") is accepted and
+// mapped to the native API root.
+func NewGeminiProvider(apiKey, apiBase, proxy string, requestTimeoutSeconds int) *GeminiProvider {
+	apiBase = normalizeGeminiAPIBase(apiBase)
+	client := common.NewHTTPClient(proxy)
+	if requestTimeoutSeconds > 0 {
+		client.Timeout = time.Duration(requestTimeoutSeconds) * time.Second
+	}
+	return &GeminiProvider{
+		apiKey:     apiKey,
+		apiBase:    apiBase,
+		httpClient: client,
+	}
+}
+
+// isGoogleGeminiAPIBase reports whether apiBase is empty or points at
+// Google's Generative Language API, which speaks the native Gemini protocol.
+func isGoogleGeminiAPIBase(apiBase string) bool {
+	if strings.TrimSpace(apiBase) == "" {
+		return true
+	}
+	u, err := url.Parse(apiBase)
+	return err == nil && strings.EqualFold(u.Hostname(), geminiAPIHost)
+}
+
+// normalizeGeminiAPIBase maps an empty api_base to Google's endpoint and an
+// OpenAI-compatible path to the native API root.
+func normalizeGeminiAPIBase(apiBase string) string {
+	apiBase = strings.TrimSuffix(strings.TrimRight(apiBase, "/"), "/openai")
+	if apiBase == "" {
+		return defaultGeminiAPIBase
+	}
+	return apiBase
+}
+
+// Chat sends a generateContent request and converts the first candidate.
+func (p *GeminiProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	model = strings.TrimPrefix(model, "gemini/")
+	if model == "" {
+		return nil, fmt.Errorf("gemini: model is required")
+	}
+
+	bodyBytes, err := json.Marshal(buildGeminiRequest(messages, tools, options))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("%s/models/%s:generateContent", p.apiBase, url.PathEscape(model))
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("X-Goog-Api-Key", p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, common.HandleErrorResponse(resp, p.apiBase)
+	}
+
+	var apiResp geminiGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	return parseGeminiResponse(apiResp)
+}
+
+// GetDefaultModel returns an empty string; the model comes from model_list.
+func (p *GeminiProvider) GetDefaultModel() string {
+	return ""
+}
+
+type geminiGenerateResponse struct {
+	antigravityJSONResponse
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback,omitempty"`
+}
+
+func parseGeminiResponse(resp geminiGenerateResponse) (*LLMResponse, error) {
+	if len(resp.Candidates) == 0 {
+		if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+			return nil, fmt.Errorf("gemini: prompt blocked (%s)", resp.PromptFeedback.BlockReason)
+		}
+		return &LLMResponse{FinishReason: "stop"}, nil
+	}
+
+	candidate := resp.Candidates[0]
+	var content strings.Builder
+	var toolCalls []ToolCall
+	for _, part := range candidate.Content.Parts {
+		content.WriteString(part.Text)
+		if part.FunctionCall == nil {
+			continue
+		}
+		args := part.FunctionCall.Args
+		if args == nil {
+			args = map[string]any{}
+		}
+		argumentsJSON, _ := json.Marshal(args)
+		thoughtSignature := extractPartThoughtSignature(part.ThoughtSignature, part.ThoughtSignatureSnake)
+		toolCalls = append(toolCalls, ToolCall{
+			// Same ID shape as Antigravity so inferToolNameFromCallID works.
+			ID:        fmt.Sprintf("call_%s_%d", part.FunctionCall.Name, time.Now().UnixNano()),
+			Type:      "function",
+			Name:      part.FunctionCall.Name,
+			Arguments: args,
+			Function: &FunctionCall{
+				Name:             part.FunctionCall.Name,
+				Arguments:        string(argumentsJSON),
+				ThoughtSignature: thoughtSignature,
+			},
+			ThoughtSignature: thoughtSignature,
+		})
+	}
+
+	finishReason := "stop"
+	switch {
+	case len(toolCalls) > 0:
+		finishReason = "tool_calls"
+	case candidate.FinishReason == "MAX_TOKENS":
+		finishReason = "length"
+	case candidate.FinishReason == "SAFETY" || candidate.FinishReason == "RECITATION":
+		finishReason = "content_filter"
+	}
+
+	var usage *UsageInfo
+	if resp.UsageMetadata.TotalTokenCount > 0 {
+		usage = &UsageInfo{
+			PromptTokens:     resp.UsageMetadata.PromptTokenCount,
+			CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
+			TotalTokens:      resp.UsageMetadata.TotalTokenCount,
+		}
+	}
+
+	return &LLMResponse{
+		Content:      content.String(),
+		ToolCalls:    toolCalls,
+		FinishReason: finishReason,
+		Usage:        usage,
+	}, nil
+}
@@ -0,0 +1,189 @@
+package providers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestBuildGeminiRequest_FunctionDeclarations(t *testing.T) {
+	tools := []ToolDefinition{{
+		Type: "function",
+		Function: ToolFunctionDefinition{
+			Name:        "get_weather",
+			Description: "Get the weather for a city",
+			Parameters: map[string]any{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": map[string]any{
+					"city": map[string]any{"type": "string", "minLength": 1},
+				},
+				"required": []any{"city"},
+			},
+		},
+	}}
+
+	req := buildGeminiRequest([]Message{{Role: "user", Content: "hi"}}, tools, nil)
+
+	if len(req.Tools) != 1 || len(req.Tools[0].FunctionDeclarations) != 1 {
+		t.Fatalf("tools = %+v, want one functionDeclarations entry", req.Tools)
+	}
+	decl := req.Tools[0].FunctionDeclarations[0]
+	if decl.Name != "get_weather" || decl.Description != "Get the weather for a city" {
+		t.Errorf("declaration = %+v", decl)
+	}
+
+	data, err := json.Marshal(req.Tools[0])
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"functionDeclarations":[{"name":"get_weather","description":"Get the weather for a city",` +
+		`"parameters":{"properties":{"city":{"type":"string"}},"required":["city"],"type":"object"}}]}`
+	if string(data) != want {
+		t.Errorf("tool JSON =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestGeminiProviderChat_ParsesFunctionCall(t *testing.T) {
+	var gotPath, gotKey string
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.Header.Get("X-Goog-Api-Key")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"candidates": [{
+				"content": {
+					"role": "model",
+					"parts": [{
+						"functionCall": {"name": "get_weather", "args": {"city": "Tokyo"}},
+						"thoughtSignature": "sig-1"
+					}]
+				},
+				"finishReason": "STOP"
+			}],
+			"usageMetadata": {"promptTokenCount": 40, "candidatesTokenCount": 7, "totalTokenCount": 47}
+		}`))
+	}))
+	defer server.Close()
+
+	p := NewGeminiProvider("test-key", server.URL+"/v1beta/openai", "", 0)
+	resp, err := p.Chat(t.Context(), []Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "weather in Tokyo?"},
+	}, nil, "gemini-2.5-flash", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if gotPath != "/v1beta/models/gemini-2.5-flash:generateContent" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotKey != "test-key" {
+		t.Errorf("X-Goog-Api-Key = %q, want test-key", gotKey)
+	}
+	if _, ok := gotBody["systemInstruction"]; !ok {
+		t.Errorf("request should carry systemInstruction, got %v", gotBody)
+	}
+
+	if resp.FinishReason != "tool_calls" || len(resp.ToolCalls) != 1 {
+		t.Fatalf("resp = %+v, want one tool call", resp)
+	}
+	tc := resp.ToolCalls[0]
+	if tc.Name != "get_weather" || tc.Arguments["city"] != "Tokyo" {
+		t.Errorf("tool call = %+v, want get_weather(city=Tokyo)", tc)
+	}
+	if tc.Function == nil || tc.Function.Arguments != `{"city":"Tokyo"}` || tc.Function.ThoughtSignature != "sig-1" {
+		t.Errorf("Function = %+v, want encoded args and thought signature", tc.Function)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 47 {
+		t.Errorf("Usage = %+v, want total 47", resp.Usage)
+	}
+}
+
+func TestGeminiProviderChat_PromptBlocked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"promptFeedback":{"blockReason":"SAFETY"}}`))
+	}))
+	defer server.Close()
+
+	p := NewGeminiProvider("test-key", server.URL, "", 0)
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "x"}}, nil, "gemini-2.5-flash", nil); err == nil {
+		t.Fatal("expected blocked prompt error")
+	}
+}
+
+func TestCreateProviderFromConfig_GeminiAPIKeyOnlyForGoogle(t *testing.T) {
+	if _, _, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName: "gemini",
+		Model:     "gemini/gemini-2.5-flash",
+	}); err == nil {
+		t.Error("expected an error without api_key for Google's endpoint")
+	}
+	if _, _, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName: "gemini",
+		Model:     "gemini/gemini-2.5-flash",
+		APIBase:   defaultGeminiAPIBase + "/openai/",
+	}); err == nil {
+		t.Error("expected an error without api_key for Google's OpenAI-compatible path")
+	}
+
+}
+
+func TestCreateProviderFromConfig_GeminiCustomAPIBaseIsOpenAICompatible(t *testing.T) {
+	provider, modelID, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName: "gemini",
+		Model:     "gemini/gemini-2.5-flash",
+		APIBase:   "http://gemini-proxy.local/v1",
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() with a custom api_base error = %v", err)
+	}
+	if _, ok := provider.(*HTTPProvider); !ok {
+		t.Errorf("provider = %T, want *HTTPProvider for a non-Google api_base", provider)
+	}
+	if modelID != "gemini-2.5-flash" {
+		t.Errorf("modelID = %q, want gemini-2.5-flash", modelID)
+	}
+
+	provider, _, err = CreateProviderFromConfig(&config.ModelConfig{
+		ModelName: "gemini",
+		Model:     "gemini/gemini-2.5-flash",
+		APIBase:   defaultGeminiAPIBase + "/openai/",
+		APIKey:    "test-key",
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*GeminiProvider); !ok {
+		t.Errorf("provider = %T, want *GeminiProvider for Google's endpoint", provider)
+	}
+}
+
+func TestCreateProviderFromConfig_GeminiNative(t *testing.T) {
+	provider, modelID, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName: "gemini",
+		Model:     "gemini/gemini-2.5-flash",
+		APIKey:    "test-key",
+	})
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	gp, ok := provider.(*GeminiProvider)
+	if !ok {
+		t.Fatalf("expected *GeminiProvider, got %T", provider)
+	}
+	if gp.apiBase != defaultGeminiAPIBase {
+		t.Errorf("apiBase = %q, want %q", gp.apiBase, defaultGeminiAPIBase)
+	}
+	if modelID != "gemini-2.5-flash" {
+		t.Errorf("modelID = %q, want gemini-2.5-flash", modelID)
+	}
+}
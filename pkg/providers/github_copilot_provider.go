@@ -19,44 +19,59 @@ type GitHubCopilotProvider struct {
 	mu sync.Mutex
 }
 
+// NewGitHubCopilotProvider connects to the Copilot CLI and opens a session.
+// In "grpc" mode (the default) uri is the address of a running CLI server;
+// in "stdio" mode the CLI is spawned as a child process and uri is the path
+// to its executable ("copilot" on PATH when empty).
 func NewGitHubCopilotProvider(uri string, connectMode string, model string) (*GitHubCopilotProvider, error) {
 	if connectMode == "" {
 		connectMode = "grpc"
 	}
 
+	opts, err := copilotClientOptions(uri, connectMode)
+	if err != nil {
+		return nil, err
+	}
+
+	client := copilot.NewClient(opts)
+	if err := client.Start(context.Background()); err != nil {
+		return nil, fmt.Errorf(
+			"can't connect to Github Copilot: %w; `https://github.com/github/copilot-sdk/blob/main/docs/getting-started.md#connecting-to-an-external-cli-server` for details",
+			err,
+		)
+	}
+
+	session, err := client.CreateSession(context.Background(), &copilot.SessionConfig{
+		Model: model,
+		Hooks: &copilot.SessionHooks{},
+	})
+	if err != nil {
+		client.Stop()
+		return nil, fmt.Errorf("create session failed: %w", err)
+	}
+
+	return &GitHubCopilotProvider{
+		uri:         uri,
+		connectMode: connectMode,
+		client:      client,
+		session:     session,
+	}, nil
+}
+
+// copilotClientOptions selects the SDK transport for connectMode.
+func copilotClientOptions(uri, connectMode string) (*copilot.ClientOptions, error) {
 	switch connectMode {
 	case "stdio":
-		// TODO: Implement stdio mode for GitHub Copilot provider
-		// See https://github.com/github/copilot-sdk/blob/main/docs/getting-started.md for details
-		return nil, fmt.Errorf("stdio mode not implemented for GitHub Copilot provider; please use 'grpc' mode instead")
+		return &copilot.ClientOptions{
+			CLIPath:  uri,
+			UseStdio: copilot.Bool(true),
+		}, nil
 	case "grpc":
-		client := copilot.NewClient(&copilot.ClientOptions{
+		return &copilot.ClientOptions{
 			CLIUrl: uri,
-		})
-		if err := client.Start(context.Background()); err != nil {
-			return nil, fmt.Errorf(
-				"can't connect to Github Copilot: %w; `https://github.com/github/copilot-sdk/blob/main/docs/getting-started.md#connecting-to-an-external-cli-server` for details",
-				err,
-			)
-		}
-
-		session, err := client.CreateSession(context.Background(), &copilot.SessionConfig{
-			Model: model,
-			Hooks: &copilot.SessionHooks{},
-		})
-		if err != nil {
-			client.Stop()
-			return nil, fmt.Errorf("create session failed: %w", err)
-		}
-
-		return &GitHubCopilotProvider{
-			uri:         uri,
-			connectMode: connectMode,
-			client:      client,
-			session:     session,
 		}, nil
 	default:
-		return nil, fmt.Errorf("unknown connect mode: %s", connectMode)
+		return nil, fmt.Errorf("unknown connect mode %q for GitHub Copilot provider (want \"grpc\" or \"stdio\")", connectMode)
 	}
 }
 
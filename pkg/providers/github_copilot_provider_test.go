@@ -0,0 +1,31 @@
+package providers
+
+import "testing"
+
+func TestCopilotClientOptions(t *testing.T) {
+	grpc, err := copilotClientOptions("localhost:4321", "grpc")
+	if err != nil {
+		t.Fatalf("grpc: unexpected error %v", err)
+	}
+	if grpc.CLIUrl != "localhost:4321" || grpc.UseStdio != nil || grpc.CLIPath != "" {
+		t.Errorf("grpc options = %+v, want CLIUrl only", grpc)
+	}
+
+	stdio, err := copilotClientOptions("/usr/local/bin/copilot", "stdio")
+	if err != nil {
+		t.Fatalf("stdio: unexpected error %v", err)
+	}
+	if stdio.CLIPath != "/usr/local/bin/copilot" || stdio.UseStdio == nil || !*stdio.UseStdio || stdio.CLIUrl != "" {
+		t.Errorf("stdio options = %+v, want CLIPath with UseStdio", stdio)
+	}
+
+	if _, err := copilotClientOptions("", "websocket"); err == nil {
+		t.Error("expected error for unknown connect mode")
+	}
+}
+
+func TestNewGitHubCopilotProvider_UnknownConnectMode(t *testing.T) {
+	if _, err := NewGitHubCopilotProvider("localhost:4321", "websocket", "gpt-4.1"); err == nil {
+		t.Fatal("expected error for unknown connect mode")
+	}
+}
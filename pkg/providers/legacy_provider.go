@@ -7,8 +7,10 @@ package providers
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
 // CreateProvider creates a provider based on the configuration.
@@ -46,6 +48,13 @@ func CreateProvider(cfg *config.Config) (LLMProvider, string, error) {
 		return nil, "", fmt.Errorf("model %q not found in model_list: %w", model, err)
 	}
 
+	if trace, err := ExplainModelSelection(cfg, model); err == nil {
+		logger.DebugCF("provider", "Provider selection", map[string]any{
+			"model": model,
+			"trace": strings.Join(trace, "; "),
+		})
+	}
+
 	// Inject global workspace if not set in model config
 	if modelCfg.Workspace == "" {
 		modelCfg.Workspace = cfg.WorkspacePath()
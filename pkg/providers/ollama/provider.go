@@ -0,0 +1,442 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package ollama implements a provider for Ollama's native /api/chat endpoint.
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers/common"
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	FunctionCall   = protocoltypes.FunctionCall
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+)
+
+// Provider talks to Ollama's native chat API, which supports keep_alive and
+// Ollama's own tool-call format. The configured api_base may point at either
+// the server root or its OpenAI-compatible /v1 path; both are accepted.
+//
+// If the server answers that /api/chat does not exist or is not supported
+// (e.g. a proxy that only exposes /v1), requests fall back to the
+// OpenAI-compatible endpoint. Transport errors, timeouts and cancellation are
+// returned as they are.
+type Provider struct {
+	baseURL        string
+	apiKey         string
+	httpClient     *http.Client
+	maxTokensField string
+	compat         *openai_compat.Provider
+	// nativeMissing is set once /api/chat returns 404 so later requests go
+	// straight to the OpenAI-compatible endpoint.
+	nativeMissing atomic.Bool
+}
+
+// Option configures the Ollama Provider.
+type Option func(*Provider)
+
+// WithRequestTimeout sets the HTTP request timeout.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		if timeout > 0 {
+			p.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithMaxTokensField sets the max tokens field name sent to the
+// OpenAI-compatible endpoint. The native API always uses num_predict.
+func WithMaxTokensField(maxTokensField string) Option {
+	return func(p *Provider) {
+		p.maxTokensField = maxTokensField
+	}
+}
+
+// NewProvider creates a new Ollama provider.
+func NewProvider(apiKey, apiBase, proxy string, opts ...Option) *Provider {
+	base := strings.TrimSuffix(strings.TrimRight(apiBase, "/"), "/v1")
+	p := &Provider{
+		baseURL:    base,
+		apiKey:     apiKey,
+		httpClient: common.NewHTTPClient(proxy),
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+
+	p.compat = openai_compat.NewProvider(apiKey, base+"/v1", proxy,
+		openai_compat.WithMaxTokensField(p.maxTokensField),
+		openai_compat.WithRequestTimeout(p.httpClient.Timeout))
+	return p
+}
+
+// NewProviderWithTimeout creates a new Ollama provider with a custom request timeout in seconds.
+func NewProviderWithTimeout(apiKey, apiBase, proxy string, requestTimeoutSeconds int) *Provider {
+	return NewProvider(
+		apiKey, apiBase, proxy,
+		WithRequestTimeout(time.Duration(requestTimeoutSeconds)*time.Second),
+	)
+}
+
+// NewProviderWithMaxTokensFieldAndTimeout creates a new Ollama provider with
+// a max tokens field name for the OpenAI-compatible fallback and a custom
+// request timeout in seconds.
+func NewProviderWithMaxTokensFieldAndTimeout(
+	apiKey, apiBase, proxy, maxTokensField string,
+	requestTimeoutSeconds int,
+) *Provider {
+	return NewProvider(
+		apiKey, apiBase, proxy,
+		WithMaxTokensField(maxTokensField),
+		WithRequestTimeout(time.Duration(requestTimeoutSeconds)*time.Second),
+	)
+}
+
+// errNativeUnavailable marks responses saying /api/chat does not exist or is
+// not supported; those requests are retried against the OpenAI-compatible
+// endpoint.
+var errNativeUnavailable = errors.New("ollama native API unavailable")
+
+// Chat sends a non-streaming request to /api/chat.
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	if p.nativeMissing.Load() {
+		return p.compat.Chat(ctx, messages, tools, model, options)
+	}
+
+	resp, err := p.post(ctx, buildRequestBody(messages, tools, model, options, false), p.httpClient)
+	if errors.Is(err, errNativeUnavailable) {
+		logFallback(err)
+		return p.compat.Chat(ctx, messages, tools, model, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var chunk chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chunk); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	return chunk.toLLMResponse(chunk.Message.Content), nil
+}
+
+// ChatStream streams /api/chat, which emits one JSON object per line.
+// onChunk receives the accumulated text so far.
+func (p *Provider) ChatStream(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onChunk func(accumulated string),
+) (*LLMResponse, error) {
+	if p.nativeMissing.Load() {
+		return p.compat.ChatStream(ctx, messages, tools, model, options, onChunk)
+	}
+
+	// Streams can outlive the request timeout; rely on ctx instead.
+	streamClient := &http.Client{Transport: p.httpClient.Transport}
+	resp, err := p.post(ctx, buildRequestBody(messages, tools, model, options, true), streamClient)
+	if errors.Is(err, errNativeUnavailable) {
+		logFallback(err)
+		return p.compat.ChatStream(ctx, messages, tools, model, options, onChunk)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return parseStream(ctx, resp.Body, onChunk)
+}
+
+func logFallback(err error) {
+	logger.WarnCF("provider", "Ollama native API unavailable, falling back to OpenAI-compatible API",
+		map[string]any{"error": err.Error()})
+}
+
+// GetDefaultModel returns an empty string; Ollama models are user-configured.
+func (p *Provider) GetDefaultModel() string {
+	return ""
+}
+
+func (p *Provider) post(ctx context.Context, body map[string]any, client *http.Client) (*http.Response, error) {
+	if p.baseURL == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/chat", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if nativeUnsupported(resp) {
+		resp.Body.Close()
+		p.nativeMissing.Store(true)
+		return nil, fmt.Errorf("%w: %s/api/chat returned %d", errNativeUnavailable, p.baseURL, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, common.HandleErrorResponse(resp, p.baseURL)
+	}
+	return resp, nil
+}
+
+// nativeUnsupported reports whether resp says the server has no usable
+// /api/chat: a 404 for the endpoint itself, 405 or 501.
+func nativeUnsupported(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return !isModelNotFound(resp)
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// isModelNotFound distinguishes Ollama's 404 for an unknown model, which is a
+// real error, from a 404 for the endpoint itself. The body is restored so the
+// caller can still report it.
+func isModelNotFound(resp *http.Response) bool {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return false
+	}
+	return strings.Contains(payload.Error, "model")
+}
+
+func buildRequestBody(
+	messages []Message, tools []ToolDefinition, model string, options map[string]any, stream bool,
+) map[string]any {
+	body := map[string]any{
+		"model":    strings.TrimPrefix(model, "ollama/"),
+		"messages": serializeMessages(messages),
+		"stream":   stream,
+	}
+	if len(tools) > 0 {
+		body["tools"] = tools
+	}
+	if keepAlive, ok := options["keep_alive"].(string); ok && keepAlive != "" {
+		body["keep_alive"] = keepAlive
+	}
+
+	modelOptions := map[string]any{}
+	if maxTokens, ok := common.AsInt(options["max_tokens"]); ok {
+		modelOptions["num_predict"] = maxTokens
+	}
+	if temperature, ok := common.AsFloat(options["temperature"]); ok {
+		modelOptions["temperature"] = temperature
+	}
+	if len(modelOptions) > 0 {
+		body["options"] = modelOptions
+	}
+	return body
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	} `json:"function"`
+}
+
+// serializeMessages converts messages to Ollama's format: tool-call arguments
+// are JSON objects rather than strings, images are bare base64, and tool
+// results are matched to calls by tool name instead of call ID.
+func serializeMessages(messages []Message) []ollamaMessage {
+	toolNames := make(map[string]string)
+	out := make([]ollamaMessage, 0, len(messages))
+	for _, m := range messages {
+		msg := ollamaMessage{Role: m.Role, Content: m.Content}
+		for _, media := range m.Media {
+			if _, data, ok := strings.Cut(media, ";base64,"); ok && strings.HasPrefix(media, "data:image/") {
+				msg.Images = append(msg.Images, data)
+			}
+		}
+		for _, tc := range m.ToolCalls {
+			name, args := common.ToolCallNameAndArguments(tc)
+			if name == "" {
+				continue
+			}
+			toolNames[tc.ID] = name
+			var call ollamaToolCall
+			call.Function.Name = name
+			call.Function.Arguments = args
+			msg.ToolCalls = append(msg.ToolCalls, call)
+		}
+		if m.Role == "tool" {
+			msg.ToolName = toolNames[m.ToolCallID]
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+type chatResponse struct {
+	Message struct {
+		Content   string           `json:"content"`
+		Thinking  string           `json:"thinking"`
+		ToolCalls []ollamaToolCall `json:"tool_calls"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+func (c *chatResponse) toLLMResponse(content string) *LLMResponse {
+	toolCalls := convertToolCalls(c.Message.ToolCalls)
+	return &LLMResponse{
+		Content:          content,
+		ReasoningContent: c.Message.Thinking,
+		ToolCalls:        toolCalls,
+		FinishReason:     finishReason(c.DoneReason, len(toolCalls) > 0),
+		Usage:            c.usage(),
+	}
+}
+
+func (c *chatResponse) usage() *UsageInfo {
+	if c.PromptEvalCount == 0 && c.EvalCount == 0 {
+		return nil
+	}
+	return &UsageInfo{
+		PromptTokens:     c.PromptEvalCount,
+		CompletionTokens: c.EvalCount,
+		TotalTokens:      c.PromptEvalCount + c.EvalCount,
+	}
+}
+
+// convertToolCalls maps Ollama tool calls to ToolCalls. Ollama does not
+// assign call IDs, so random ones are generated; they must not repeat across
+// responses, since the whole session history is sent back on every turn.
+func convertToolCalls(calls []ollamaToolCall) []ToolCall {
+	var out []ToolCall
+	for _, call := range calls {
+		args := call.Function.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		argsJSON, _ := json.Marshal(args)
+		out = append(out, ToolCall{
+			ID:        "call_" + strings.ToLower(rand.Text()),
+			Type:      "function",
+			Name:      call.Function.Name,
+			Arguments: args,
+			Function:  &FunctionCall{Name: call.Function.Name, Arguments: string(argsJSON)},
+		})
+	}
+	return out
+}
+
+func finishReason(doneReason string, hasToolCalls bool) string {
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	if doneReason == "length" {
+		return "length"
+	}
+	return "stop"
+}
+
+func parseStream(ctx context.Context, reader io.Reader, onChunk func(accumulated string)) (*LLMResponse, error) {
+	var content, thinking strings.Builder
+	var toolCalls []ToolCall
+	var final chatResponse
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk chatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			continue // skip malformed chunks
+		}
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			if onChunk != nil {
+				onChunk(content.String())
+			}
+		}
+		thinking.WriteString(chunk.Message.Thinking)
+		toolCalls = append(toolCalls, convertToolCalls(chunk.Message.ToolCalls)...)
+		if chunk.Done {
+			final = chunk
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("streaming read error: %w", err)
+	}
+
+	return &LLMResponse{
+		Content:          content.String(),
+		ReasoningContent: thinking.String(),
+		ToolCalls:        toolCalls,
+		FinishReason:     finishReason(final.DoneReason, len(toolCalls) > 0),
+		Usage:            final.usage(),
+	}, nil
+}
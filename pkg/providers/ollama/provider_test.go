@@ -0,0 +1,286 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordedChatResponse is a non-streaming /api/chat reply with a tool call,
+// in the shape Ollama returns it.
+const recordedChatResponse = `{
+  "model": "llama3.1",
+  "created_at": "2025-05-01T10:00:00.000000Z",
+  "message": {
+    "role": "assistant",
+    "content": "",
+    "tool_calls": [
+      {"function": {"name": "get_weather", "arguments": {"city": "Paris", "units": "metric"}}}
+    ]
+  },
+  "done_reason": "stop",
+  "done": true,
+  "total_duration": 1234567890,
+  "prompt_eval_count": 120,
+  "eval_count": 18
+}`
+
+func TestBuildRequestBody(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "weather?", Media: []string{"data:image/png;base64,aGVsbG8="}},
+		{Role: "assistant", ToolCalls: []ToolCall{{
+			ID:       "call_0",
+			Function: &FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		}}},
+		{Role: "tool", Content: "sunny", ToolCallID: "call_0"},
+	}
+	tools := []ToolDefinition{{Type: "function"}}
+	tools[0].Function.Name = "get_weather"
+
+	body := buildRequestBody(messages, tools, "llama3.1", map[string]any{
+		"max_tokens":  256,
+		"temperature": 0.2,
+		"keep_alive":  "10m",
+	}, false)
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var got struct {
+		Model    string `json:"model"`
+		Stream   bool   `json:"stream"`
+		Messages []struct {
+			Role      string   `json:"role"`
+			Images    []string `json:"images"`
+			ToolName  string   `json:"tool_name"`
+			ToolCalls []struct {
+				Function struct {
+					Name      string         `json:"name"`
+					Arguments map[string]any `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"messages"`
+		Tools     []ToolDefinition `json:"tools"`
+		KeepAlive string           `json:"keep_alive"`
+		Options   map[string]any   `json:"options"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if got.Model != "llama3.1" || got.Stream {
+		t.Errorf("model/stream = %q/%v, want llama3.1/false", got.Model, got.Stream)
+	}
+	if got.KeepAlive != "10m" {
+		t.Errorf("keep_alive = %q, want 10m", got.KeepAlive)
+	}
+	if got.Options["num_predict"] != float64(256) || got.Options["temperature"] != 0.2 {
+		t.Errorf("options = %v, want num_predict=256 temperature=0.2", got.Options)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].Function.Name != "get_weather" {
+		t.Errorf("tools = %+v, want get_weather", got.Tools)
+	}
+	if imgs := got.Messages[1].Images; len(imgs) != 1 || imgs[0] != "aGVsbG8=" {
+		t.Errorf("images = %v, want bare base64", imgs)
+	}
+	calls := got.Messages[2].ToolCalls
+	if len(calls) != 1 || calls[0].Function.Arguments["city"] != "Paris" {
+		t.Errorf("tool_calls = %+v, want object arguments with city=Paris", calls)
+	}
+	if got.Messages[3].ToolName != "get_weather" {
+		t.Errorf("tool message tool_name = %q, want get_weather", got.Messages[3].ToolName)
+	}
+}
+
+func TestProviderChat_ParsesRecordedResponse(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(recordedChatResponse))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL+"/v1", "")
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "weather?"}}, nil, "llama3.1", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if path != "/api/chat" {
+		t.Errorf("path = %q, want /api/chat", path)
+	}
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", resp.FinishReason)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("len(ToolCalls) = %d, want 1", len(resp.ToolCalls))
+	}
+	tc := resp.ToolCalls[0]
+	if tc.Name != "get_weather" || tc.Arguments["city"] != "Paris" || tc.ID == "" {
+		t.Errorf("tool call = %+v, want get_weather(city=Paris) with an ID", tc)
+	}
+	if tc.Function == nil || !strings.Contains(tc.Function.Arguments, `"units":"metric"`) {
+		t.Errorf("Function.Arguments = %+v, want JSON-encoded arguments", tc.Function)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 120 || resp.Usage.CompletionTokens != 18 {
+		t.Errorf("Usage = %+v, want 120/18", resp.Usage)
+	}
+}
+
+func TestProviderChat_ToolCallIDsUniqueAcrossResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(recordedChatResponse))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "")
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "weather?"}}, nil, "llama3.1", nil)
+		if err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+		id := resp.ToolCalls[0].ID
+		if seen[id] {
+			t.Fatalf("tool call ID %q repeated across responses", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestProviderChatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"message":{"role":"assistant","content":"Hel"},"done":false}
+{"message":{"role":"assistant","content":"lo"},"done":false}
+{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":5,"eval_count":2}
+`))
+	}))
+	defer server.Close()
+
+	var chunks []string
+	p := NewProvider("", server.URL, "")
+	resp, err := p.ChatStream(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "llama3.1", nil,
+		func(acc string) { chunks = append(chunks, acc) })
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if resp.Content != "Hello" || resp.FinishReason != "stop" {
+		t.Errorf("resp = %+v, want Hello/stop", resp)
+	}
+	if strings.Join(chunks, ",") != "Hel,Hello" {
+		t.Errorf("chunks = %v, want accumulated text", chunks)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 7 {
+		t.Errorf("Usage = %+v, want total 7", resp.Usage)
+	}
+}
+
+func TestProviderChat_FallsBackToOpenAICompat(t *testing.T) {
+	var nativeHits, compatHits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat":
+			nativeHits++
+			http.NotFound(w, r)
+		case "/v1/chat/completions":
+			compatHits++
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"choices":[{"message":{"content":"from compat"},"finish_reason":"stop"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL+"/v1", "")
+	for range 2 {
+		resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "llama3.1", nil)
+		if err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+		if resp.Content != "from compat" {
+			t.Errorf("Content = %q, want fallback response", resp.Content)
+		}
+	}
+	if nativeHits != 1 || compatHits != 2 {
+		t.Errorf("native/compat hits = %d/%d, want 1/2 (404 should be remembered)", nativeHits, compatHits)
+	}
+}
+
+func TestProviderChat_FallbackHonoursMaxTokensField(t *testing.T) {
+	var compatBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat":
+			w.WriteHeader(http.StatusNotImplemented)
+		case "/v1/chat/completions":
+			json.NewDecoder(r.Body).Decode(&compatBody)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "", WithMaxTokensField("max_completion_tokens"))
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "llama3.1",
+		map[string]any{"max_tokens": 256})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if got := compatBody["max_completion_tokens"]; got != float64(256) {
+		t.Errorf("max_completion_tokens = %v, want 256 (body: %v)", got, compatBody)
+	}
+	if _, ok := compatBody["max_tokens"]; ok {
+		t.Errorf("did not expect max_tokens when max_tokens_field is set (body: %v)", compatBody)
+	}
+}
+
+func TestProviderChat_TransportErrorIsNotFallback(t *testing.T) {
+	// The server is down; the error must surface instead of being retried
+	// against the OpenAI-compatible endpoint.
+	server := httptest.NewServer(http.NotFoundHandler())
+	base := server.URL
+	server.Close()
+
+	p := NewProvider("", base, "")
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "llama3.1", nil); err == nil {
+		t.Fatal("Chat() error = nil, want the connection error")
+	}
+	if p.nativeMissing.Load() {
+		t.Error("a transport error must not mark the native API as missing")
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := p.Chat(ctx, []Message{{Role: "user", Content: "hi"}}, nil, "llama3.1", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Chat() with a cancelled context error = %v, want context.Canceled", err)
+	}
+	if p.nativeMissing.Load() {
+		t.Error("cancellation must not mark the native API as missing")
+	}
+}
+
+func TestProviderChat_ModelNotFoundIsNotFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"nope\" not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "")
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "nope", nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("Chat() error = %v, want model not found error", err)
+	}
+}
@@ -108,17 +108,7 @@ func (p *Provider) buildRequestBody(
 	}
 
 	if maxTokens, ok := common.AsInt(options["max_tokens"]); ok {
-		fieldName := p.maxTokensField
-		if fieldName == "" {
-			lowerModel := strings.ToLower(model)
-			if strings.Contains(lowerModel, "glm") || strings.Contains(lowerModel, "o1") ||
-				strings.Contains(lowerModel, "gpt-5") {
-				fieldName = "max_completion_tokens"
-			} else {
-				fieldName = "max_tokens"
-			}
-		}
-		requestBody[fieldName] = maxTokens
+		requestBody[p.maxTokensFieldFor(model)] = maxTokens
 	}
 
 	if temperature, ok := common.AsFloat(options["temperature"]); ok {
@@ -373,6 +363,24 @@ func parseStreamResponse(
 	}, nil
 }
 
+// maxTokensFieldFor returns the request key for the token limit. An explicit
+// max_tokens_field from config wins; otherwise models known to reject
+// max_tokens (GLM, o-series reasoning models, GPT-5) get
+// max_completion_tokens and everything else gets max_tokens.
+func (p *Provider) maxTokensFieldFor(model string) string {
+	if p.maxTokensField != "" {
+		return p.maxTokensField
+	}
+	lowerModel := strings.ToLower(model)
+	baseModel := lowerModel[strings.LastIndex(lowerModel, "/")+1:]
+	if strings.Contains(lowerModel, "glm") || strings.Contains(lowerModel, "o1") ||
+		strings.Contains(lowerModel, "gpt-5") ||
+		strings.HasPrefix(baseModel, "o3") || strings.HasPrefix(baseModel, "o4") {
+		return "max_completion_tokens"
+	}
+	return "max_tokens"
+}
+
 func normalizeModel(model, apiBase string) string {
 	before, after, ok := strings.Cut(model, "/")
 	if !ok {
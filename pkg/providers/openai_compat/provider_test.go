@@ -1089,3 +1089,35 @@ func TestSerializeMessages_StripsSystemParts(t *testing.T) {
 		t.Fatal("system_parts should not appear in serialized output")
 	}
 }
+
+func TestProvider_MaxTokensFieldFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		model string
+		want  string
+	}{
+		{name: "default", model: "gpt-4o", want: "max_tokens"},
+		{
+			name:  "configured overrides heuristic",
+			field: "max_completion_tokens",
+			model: "gpt-4o",
+			want:  "max_completion_tokens",
+		},
+		{name: "configured wins over model hint", field: "max_tokens", model: "glm-4.7", want: "max_tokens"},
+		{name: "glm", model: "glm-4.7", want: "max_completion_tokens"},
+		{name: "o1", model: "o1-mini", want: "max_completion_tokens"},
+		{name: "o3", model: "o3-mini", want: "max_completion_tokens"},
+		{name: "o4 with vendor prefix", model: "openai/o4-mini", want: "max_completion_tokens"},
+		{name: "gpt-5", model: "gpt-5.1", want: "max_completion_tokens"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider("key", "https://example.com/v1", "", WithMaxTokensField(tt.field))
+			if got := p.maxTokensFieldFor(tt.model); got != tt.want {
+				t.Fatalf("maxTokensFieldFor(%q) = %q, want %q", tt.model, got, tt.want)
+			}
+		})
+	}
+}
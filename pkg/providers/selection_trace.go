@@ -0,0 +1,82 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// ExplainModelSelection describes, step by step, how CreateProvider resolves
+// modelName: which model_list entry is used, which protocol that implies and
+// where the API base comes from. It is meant for diagnosing configs that
+// route to an unexpected provider.
+func ExplainModelSelection(cfg *config.Config, modelName string) ([]string, error) {
+	var indexes []int
+	for i := range cfg.ModelList {
+		if cfg.ModelList[i].ModelName == modelName {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("model %q not found in model_list", modelName)
+	}
+
+	var trace []string
+	if len(indexes) == 1 {
+		trace = append(trace, fmt.Sprintf("model_name %q matched model_list[%d]", modelName, indexes[0]))
+	} else {
+		trace = append(trace, fmt.Sprintf(
+			"model_name %q matched %d model_list entries %v; requests are round-robined, explaining model_list[%d]",
+			modelName, len(indexes), indexes, indexes[0]))
+	}
+	return append(trace, ExplainProviderSelection(&cfg.ModelList[indexes[0]])...), nil
+}
+
+// ExplainProviderSelection describes how CreateProviderFromConfig routes a
+// single model_list entry. The steps mirror its protocol switch.
+func ExplainProviderSelection(cfg *config.ModelConfig) []string {
+	protocol, modelID := ExtractProtocol(cfg.Model)
+
+	var trace []string
+	if strings.Contains(strings.TrimSpace(cfg.Model), "/") {
+		trace = append(trace, fmt.Sprintf("protocol %q from the prefix of model %q", protocol, cfg.Model))
+	} else {
+		trace = append(trace, fmt.Sprintf("protocol %q by default: model %q has no protocol prefix", protocol, cfg.Model))
+	}
+
+	if (protocol == "openai" || protocol == "anthropic") &&
+		(cfg.AuthMethod == "oauth" || cfg.AuthMethod == "token") {
+		trace = append(trace, fmt.Sprintf(
+			"auth_method %q: using stored %s credentials, api_key and api_base are ignored", cfg.AuthMethod, protocol))
+		return append(trace, fmt.Sprintf("sends model ID %q", modelID))
+	}
+
+	switch {
+	case cfg.APIBase != "":
+		trace = append(trace, fmt.Sprintf("api_base %q from model_list", cfg.APIBase))
+	case defaultAPIBaseForExplain(cfg, protocol) != "":
+		trace = append(trace, fmt.Sprintf(
+			"api_base %q is the default for protocol %q (no api_base configured)",
+			defaultAPIBaseForExplain(cfg, protocol), protocol))
+	default:
+		trace = append(trace, fmt.Sprintf("protocol %q has no default api_base", protocol))
+	}
+
+	return append(trace, fmt.Sprintf("sends model ID %q", modelID))
+}
+
+// defaultAPIBaseForExplain returns the api_base CreateProviderFromConfig
+// falls back to for cfg. Bedrock resolves its regional endpoint at
+// construction time, so only its shape is reported.
+func defaultAPIBaseForExplain(cfg *config.ModelConfig, protocol string) string {
+	if protocol == "bedrock" {
+		return "https://bedrock-runtime.{region}.amazonaws.com"
+	}
+	return defaultModelAPIBase(cfg, protocol)
+}
@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestExplainProviderSelection(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ModelConfig
+		want []string
+	}{
+		{
+			name: "prefix with default api base",
+			cfg:  config.ModelConfig{Model: "moonshot/kimi-k2"},
+			want: []string{
+				`protocol "moonshot" from the prefix of model "moonshot/kimi-k2"`,
+				`api_base "https://api.moonshot.cn/v1" is the default for protocol "moonshot" (no api_base configured)`,
+				`sends model ID "kimi-k2"`,
+			},
+		},
+		{
+			name: "no prefix falls back to openai",
+			cfg:  config.ModelConfig{Model: "gpt-4o", APIBase: "https://proxy.example.com/v1"},
+			want: []string{
+				`protocol "openai" by default: model "gpt-4o" has no protocol prefix`,
+				`api_base "https://proxy.example.com/v1" from model_list`,
+				`sends model ID "gpt-4o"`,
+			},
+		},
+		{
+			name: "oauth ignores api base",
+			cfg:  config.ModelConfig{Model: "anthropic/claude-sonnet-4.6", AuthMethod: "oauth"},
+			want: []string{
+				`protocol "anthropic" from the prefix of model "anthropic/claude-sonnet-4.6"`,
+				`auth_method "oauth": using stored anthropic credentials, api_key and api_base are ignored`,
+				`sends model ID "claude-sonnet-4.6"`,
+			},
+		},
+		{
+			name: "protocol without default base",
+			cfg:  config.ModelConfig{Model: "azure/my-deployment"},
+			want: []string{
+				`protocol "azure" from the prefix of model "azure/my-deployment"`,
+				`protocol "azure" has no default api_base`,
+				`sends model ID "my-deployment"`,
+			},
+		},
+		{
+			name: "copilot grpc uses the local server",
+			cfg:  config.ModelConfig{Model: "github-copilot/gpt-5"},
+			want: []string{
+				`protocol "github-copilot" from the prefix of model "github-copilot/gpt-5"`,
+				`api_base "localhost:4321" is the default for protocol "github-copilot" (no api_base configured)`,
+				`sends model ID "gpt-5"`,
+			},
+		},
+		{
+			name: "copilot stdio has no default base",
+			cfg:  config.ModelConfig{Model: "github-copilot/gpt-5", ConnectMode: "stdio"},
+			want: []string{
+				`protocol "github-copilot" from the prefix of model "github-copilot/gpt-5"`,
+				`protocol "github-copilot" has no default api_base`,
+				`sends model ID "gpt-5"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExplainProviderSelection(&tt.cfg)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExplainProviderSelection() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainModelSelection(t *testing.T) {
+	cfg := &config.Config{
+		ModelList: []config.ModelConfig{
+			{ModelName: "fast", Model: "groq/llama-3.3-70b"},
+			{ModelName: "smart", Model: "openai/gpt-5.4", APIKey: "k1"},
+			{ModelName: "smart", Model: "openai/gpt-5.4", APIKey: "k2"},
+		},
+	}
+
+	trace, err := ExplainModelSelection(cfg, "fast")
+	if err != nil {
+		t.Fatalf("ExplainModelSelection() error = %v", err)
+	}
+	if trace[0] != `model_name "fast" matched model_list[0]` {
+		t.Fatalf("trace[0] = %q", trace[0])
+	}
+
+	trace, err = ExplainModelSelection(cfg, "smart")
+	if err != nil {
+		t.Fatalf("ExplainModelSelection() error = %v", err)
+	}
+	want := `model_name "smart" matched 2 model_list entries [1 2]; requests are round-robined, explaining model_list[1]`
+	if trace[0] != want {
+		t.Fatalf("trace[0] = %q, want %q", trace[0], want)
+	}
+
+	if _, err := ExplainModelSelection(cfg, "missing"); err == nil {
+		t.Fatal("ExplainModelSelection() should fail for an unknown model")
+	}
+}
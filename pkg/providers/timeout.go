@@ -0,0 +1,47 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RequestTimeoutError reports a provider call cut off by its configured
+// request timeout. It unwraps to context.DeadlineExceeded so existing
+// timeout handling (retries, fallback) treats it like any other timeout.
+type RequestTimeoutError struct {
+	Model   string
+	Timeout time.Duration
+}
+
+func (e *RequestTimeoutError) Error() string {
+	return fmt.Sprintf("request to model %q timed out after %s", e.Model, e.Timeout)
+}
+
+func (e *RequestTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// CallWithTimeout runs call with ctx bounded by timeout; a timeout <= 0
+// leaves ctx unchanged. When the timeout, not the caller, ends the call, the
+// error is a *RequestTimeoutError.
+func CallWithTimeout(
+	ctx context.Context,
+	timeout time.Duration,
+	model string,
+	call func(ctx context.Context) (*LLMResponse, error),
+) (*LLMResponse, error) {
+	if timeout <= 0 {
+		return call(ctx)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := call(callCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return nil, &RequestTimeoutError{Model: model, Timeout: timeout}
+	}
+	return resp, err
+}
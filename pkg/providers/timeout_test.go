@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// slowProvider answers only after delay, or returns ctx.Err() when the
+// context ends first.
+type slowProvider struct {
+	delay time.Duration
+}
+
+func (p *slowProvider) Chat(
+	ctx context.Context,
+	_ []Message,
+	_ []ToolDefinition,
+	_ string,
+	_ map[string]any,
+) (*LLMResponse, error) {
+	select {
+	case <-time.After(p.delay):
+		return &LLMResponse{Content: "done"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *slowProvider) GetDefaultModel() string { return "slow" }
+
+func chatVia(p LLMProvider) func(ctx context.Context) (*LLMResponse, error) {
+	return func(ctx context.Context) (*LLMResponse, error) {
+		return p.Chat(ctx, nil, nil, "slow", nil)
+	}
+}
+
+func TestCallWithTimeout_CancelsSlowProvider(t *testing.T) {
+	p := &slowProvider{delay: 5 * time.Second}
+
+	start := time.Now()
+	_, err := CallWithTimeout(context.Background(), 50*time.Millisecond, "slow", chatVia(p))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("call took %s, want it cut off near 50ms", elapsed)
+	}
+
+	var timeoutErr *RequestTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("err = %v, want *RequestTimeoutError", err)
+	}
+	if timeoutErr.Timeout != 50*time.Millisecond || timeoutErr.Model != "slow" {
+		t.Errorf("unexpected timeout error: %+v", timeoutErr)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("timeout error should unwrap to context.DeadlineExceeded")
+	}
+	if fe := ClassifyError(err, "openai", "slow"); fe == nil || fe.Reason != FailoverTimeout {
+		t.Errorf("ClassifyError = %+v, want reason timeout", fe)
+	}
+}
+
+func TestCallWithTimeout_FastProviderAndNoTimeout(t *testing.T) {
+	p := &slowProvider{delay: 10 * time.Millisecond}
+
+	for _, timeout := range []time.Duration{0, time.Second} {
+		resp, err := CallWithTimeout(context.Background(), timeout, "slow", chatVia(p))
+		if err != nil || resp.Content != "done" {
+			t.Errorf("timeout %s: resp=%+v err=%v, want done", timeout, resp, err)
+		}
+	}
+}
+
+func TestCallWithTimeout_CallerCancellationIsNotATimeout(t *testing.T) {
+	p := &slowProvider{delay: 5 * time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := CallWithTimeout(ctx, time.Second, "slow", chatVia(p))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	var timeoutErr *RequestTimeoutError
+	if errors.As(err, &timeoutErr) {
+		t.Error("caller cancellation should not be reported as a request timeout")
+	}
+}
@@ -118,19 +118,26 @@ func (r *RouteResolver) ResolveRoute(input RouteInput) ResolvedRoute {
 	return choose(r.resolveDefaultAgentID(), "default")
 }
 
+// filterBindings returns the bindings for channel and accountID. Bindings
+// with match.channel "*" apply to every channel and are ordered after the
+// channel-specific ones, so an exact channel wins at the same priority level.
 func (r *RouteResolver) filterBindings(channel, accountID string) []config.AgentBinding {
-	var filtered []config.AgentBinding
+	var filtered, wildcard []config.AgentBinding
 	for _, b := range r.cfg.Bindings {
 		matchChannel := strings.ToLower(strings.TrimSpace(b.Match.Channel))
-		if matchChannel == "" || matchChannel != channel {
+		if matchChannel == "" || (matchChannel != channel && matchChannel != "*") {
 			continue
 		}
 		if !matchesAccountID(b.Match.AccountID, accountID) {
 			continue
 		}
+		if matchChannel == "*" {
+			wildcard = append(wildcard, b)
+			continue
+		}
 		filtered = append(filtered, b)
 	}
-	return filtered
+	return append(filtered, wildcard...)
 }
 
 func matchesAccountID(matchAccountID, actual string) bool {
@@ -144,7 +151,10 @@ func matchesAccountID(matchAccountID, actual string) bool {
 	return strings.ToLower(trimmed) == strings.ToLower(actual)
 }
 
+// findPeerMatch returns the binding for peer. An exact peer id wins over
+// match.peer.id "*", which matches every peer of that kind.
 func (r *RouteResolver) findPeerMatch(bindings []config.AgentBinding, peer *RoutePeer) *config.AgentBinding {
+	var wildcard *config.AgentBinding
 	for i := range bindings {
 		b := &bindings[i]
 		if b.Match.Peer == nil {
@@ -152,14 +162,17 @@ func (r *RouteResolver) findPeerMatch(bindings []config.AgentBinding, peer *Rout
 		}
 		peerKind := strings.ToLower(strings.TrimSpace(b.Match.Peer.Kind))
 		peerID := strings.TrimSpace(b.Match.Peer.ID)
-		if peerKind == "" || peerID == "" {
+		if peerKind == "" || peerID == "" || peerKind != strings.ToLower(peer.Kind) {
 			continue
 		}
-		if peerKind == strings.ToLower(peer.Kind) && peerID == peer.ID {
+		if peerID == peer.ID {
 			return b
 		}
+		if peerID == "*" && wildcard == nil {
+			wildcard = b
+		}
 	}
-	return nil
+	return wildcard
 }
 
 func (r *RouteResolver) findGuildMatch(bindings []config.AgentBinding, guildID string) *config.AgentBinding {
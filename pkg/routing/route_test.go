@@ -295,3 +295,149 @@ func TestResolveRoute_NoDefaultUsesFirst(t *testing.T) {
 		t.Errorf("AgentID = %q, want 'alpha' (first in list)", route.AgentID)
 	}
 }
+
+func TestResolveRoute_PeerWildcard(t *testing.T) {
+	agents := []config.AgentConfig{
+		{ID: "main", Default: true},
+		{ID: "dm"},
+		{ID: "vip"},
+	}
+	bindings := []config.AgentBinding{
+		{
+			AgentID: "dm",
+			Match: config.BindingMatch{
+				Channel:   "telegram",
+				AccountID: "*",
+				Peer:      &config.PeerMatch{Kind: "direct", ID: "*"},
+			},
+		},
+		{
+			AgentID: "vip",
+			Match: config.BindingMatch{
+				Channel:   "telegram",
+				AccountID: "*",
+				Peer:      &config.PeerMatch{Kind: "direct", ID: "user123"},
+			},
+		},
+	}
+	cfg := testConfig(agents, bindings)
+	r := NewRouteResolver(cfg)
+
+	tests := []struct {
+		peer      *RoutePeer
+		wantAgent string
+		wantMatch string
+	}{
+		{&RoutePeer{Kind: "direct", ID: "user123"}, "vip", "binding.peer"},
+		{&RoutePeer{Kind: "direct", ID: "someone-else"}, "dm", "binding.peer"},
+		{&RoutePeer{Kind: "group", ID: "group1"}, "main", "default"},
+	}
+	for _, tt := range tests {
+		route := r.ResolveRoute(RouteInput{Channel: "telegram", Peer: tt.peer})
+		if route.AgentID != tt.wantAgent || route.MatchedBy != tt.wantMatch {
+			t.Errorf("peer %s:%s routed to %q by %q, want %q by %q",
+				tt.peer.Kind, tt.peer.ID, route.AgentID, route.MatchedBy, tt.wantAgent, tt.wantMatch)
+		}
+	}
+}
+
+func TestResolveRoute_AnyChannelWildcard(t *testing.T) {
+	agents := []config.AgentConfig{
+		{ID: "main", Default: true},
+		{ID: "owner"},
+		{ID: "discord-bot"},
+	}
+	bindings := []config.AgentBinding{
+		{
+			AgentID: "owner",
+			Match: config.BindingMatch{
+				Channel:   "*",
+				AccountID: "*",
+				Peer:      &config.PeerMatch{Kind: "direct", ID: "owner-id"},
+			},
+		},
+		{
+			AgentID: "main",
+			Match: config.BindingMatch{
+				Channel:   "*",
+				AccountID: "*",
+			},
+		},
+		{
+			AgentID: "discord-bot",
+			Match: config.BindingMatch{
+				Channel:   "discord",
+				AccountID: "*",
+			},
+		},
+	}
+	cfg := testConfig(agents, bindings)
+	r := NewRouteResolver(cfg)
+
+	route := r.ResolveRoute(RouteInput{
+		Channel: "slack",
+		Peer:    &RoutePeer{Kind: "direct", ID: "owner-id"},
+	})
+	if route.AgentID != "owner" || route.MatchedBy != "binding.peer" {
+		t.Errorf("owner routed to %q by %q, want 'owner' by 'binding.peer'", route.AgentID, route.MatchedBy)
+	}
+
+	// A channel-specific binding wins over a "*" channel at the same level.
+	route = r.ResolveRoute(RouteInput{
+		Channel: "discord",
+		Peer:    &RoutePeer{Kind: "direct", ID: "user1"},
+	})
+	if route.AgentID != "discord-bot" || route.MatchedBy != "binding.channel" {
+		t.Errorf("discord routed to %q by %q, want 'discord-bot' by 'binding.channel'",
+			route.AgentID, route.MatchedBy)
+	}
+}
+
+func TestResolveRoute_UnmatchedBindingFallsToDefault(t *testing.T) {
+	agents := []config.AgentConfig{
+		{ID: "main", Default: true},
+		{ID: "support"},
+	}
+	bindings := []config.AgentBinding{
+		{
+			AgentID: "support",
+			Match: config.BindingMatch{
+				Channel:   "telegram",
+				AccountID: "*",
+				Peer:      &config.PeerMatch{Kind: "direct", ID: "user123"},
+			},
+		},
+	}
+	cfg := testConfig(agents, bindings)
+	r := NewRouteResolver(cfg)
+
+	route := r.ResolveRoute(RouteInput{
+		Channel: "discord",
+		Peer:    &RoutePeer{Kind: "direct", ID: "user123"},
+	})
+	if route.AgentID != "main" || route.MatchedBy != "default" {
+		t.Errorf("AgentID = %q by %q, want 'main' by 'default'", route.AgentID, route.MatchedBy)
+	}
+}
+
+func TestResolveRoute_IdentityLinksShareSessionAcrossChannels(t *testing.T) {
+	cfg := testConfig(nil, nil)
+	cfg.Session.IdentityLinks = map[string][]string{
+		"john": {"telegram:123", "discord:john#1234"},
+	}
+	r := NewRouteResolver(cfg)
+
+	fromTelegram := r.ResolveRoute(RouteInput{
+		Channel: "telegram",
+		Peer:    &RoutePeer{Kind: "direct", ID: "123"},
+	})
+	fromDiscord := r.ResolveRoute(RouteInput{
+		Channel: "discord",
+		Peer:    &RoutePeer{Kind: "direct", ID: "john#1234"},
+	})
+
+	want := "agent:main:direct:john"
+	if fromTelegram.SessionKey != want || fromDiscord.SessionKey != want {
+		t.Errorf("session keys = %q, %q, want both %q", fromTelegram.SessionKey, fromDiscord.SessionKey, want)
+	}
+}
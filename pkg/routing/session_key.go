@@ -2,6 +2,7 @@ package routing
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -10,6 +11,8 @@ type DMScope string
 
 const (
 	DMScopeMain                  DMScope = "main"
+	DMScopeGlobal                DMScope = "global" // alias for DMScopeMain
+	DMScopePerAccount            DMScope = "per-account"
 	DMScopePerPeer               DMScope = "per-peer"
 	DMScopePerChannelPeer        DMScope = "per-channel-peer"
 	DMScopePerAccountChannelPeer DMScope = "per-account-channel-peer"
@@ -57,7 +60,7 @@ func BuildAgentPeerSessionKey(params SessionKeyParams) string {
 
 	if peerKind == "direct" {
 		dmScope := params.DMScope
-		if dmScope == "" {
+		if dmScope == "" || dmScope == DMScopeGlobal {
 			dmScope = DMScopeMain
 		}
 		peerID := strings.TrimSpace(peer.ID)
@@ -71,6 +74,11 @@ func BuildAgentPeerSessionKey(params SessionKeyParams) string {
 		peerID = strings.ToLower(peerID)
 
 		switch dmScope {
+		case DMScopePerAccount:
+			// All DMs received by one channel account share a session.
+			channel := normalizeChannel(params.Channel)
+			accountID := NormalizeAccountID(params.AccountID)
+			return fmt.Sprintf("agent:%s:%s:%s:direct", agentID, channel, accountID)
 		case DMScopePerAccountChannelPeer:
 			if peerID != "" {
 				channel := normalizeChannel(params.Channel)
@@ -176,12 +184,20 @@ func resolveLinkedPeerID(identityLinks map[string][]string, channel, peerID stri
 		return ""
 	}
 
-	for canonical, ids := range identityLinks {
+	// Visit canonical names in sorted order so an ID listed under several
+	// names always resolves the same way instead of following map order.
+	canonicals := make([]string, 0, len(identityLinks))
+	for canonical := range identityLinks {
+		canonicals = append(canonicals, canonical)
+	}
+	sort.Strings(canonicals)
+
+	for _, canonical := range canonicals {
 		canonicalName := strings.TrimSpace(canonical)
 		if canonicalName == "" {
 			continue
 		}
-		for _, id := range ids {
+		for _, id := range identityLinks[canonical] {
 			normalized := strings.ToLower(strings.TrimSpace(id))
 			if normalized != "" && candidates[normalized] {
 				return canonicalName
@@ -71,6 +71,48 @@ func TestBuildAgentPeerSessionKey_DMScopePerAccountChannelPeer(t *testing.T) {
 	}
 }
 
+func TestBuildAgentPeerSessionKey_DMScopePerAccount(t *testing.T) {
+	got := BuildAgentPeerSessionKey(SessionKeyParams{
+		AgentID:   "main",
+		Channel:   "telegram",
+		AccountID: "bot1",
+		Peer:      &RoutePeer{Kind: "direct", ID: "user123"},
+		DMScope:   DMScopePerAccount,
+	})
+	want := "agent:main:telegram:bot1:direct"
+	if got != want {
+		t.Errorf("DMScopePerAccount = %q, want %q", got, want)
+	}
+}
+
+func TestBuildAgentPeerSessionKey_ScopePartitionsUsers(t *testing.T) {
+	key := func(scope DMScope, accountID, peerID string) string {
+		return BuildAgentPeerSessionKey(SessionKeyParams{
+			AgentID:   "main",
+			Channel:   "telegram",
+			AccountID: accountID,
+			Peer:      &RoutePeer{Kind: "direct", ID: peerID},
+			DMScope:   scope,
+		})
+	}
+
+	if a, b := key(DMScopePerPeer, "bot1", "alice"), key(DMScopePerPeer, "bot1", "bob"); a == b {
+		t.Errorf("per-peer gave two users the same key %q", a)
+	}
+	for _, scope := range []DMScope{DMScopeGlobal, DMScopeMain} {
+		a, b := key(scope, "bot1", "alice"), key(scope, "bot2", "bob")
+		if a != b || a != "agent:main:main" {
+			t.Errorf("%s keys = %q, %q, want both %q", scope, a, b, "agent:main:main")
+		}
+	}
+	if a, b := key(DMScopePerAccount, "bot1", "alice"), key(DMScopePerAccount, "bot1", "bob"); a != b {
+		t.Errorf("per-account split one account's users: %q vs %q", a, b)
+	}
+	if a, b := key(DMScopePerAccount, "bot1", "alice"), key(DMScopePerAccount, "bot2", "alice"); a == b {
+		t.Errorf("per-account gave two accounts the same key %q", a)
+	}
+}
+
 func TestBuildAgentPeerSessionKey_GroupPeer(t *testing.T) {
 	got := BuildAgentPeerSessionKey(SessionKeyParams{
 		AgentID: "main",
@@ -115,6 +157,34 @@ func TestBuildAgentPeerSessionKey_IdentityLink(t *testing.T) {
 	}
 }
 
+func TestResolveLinkedPeerID_SharedAcrossChannels(t *testing.T) {
+	links := map[string][]string{
+		"john": {"telegram:123", "discord:john#1234"},
+	}
+	if got := resolveLinkedPeerID(links, "telegram", "123"); got != "john" {
+		t.Errorf("telegram:123 resolved to %q, want %q", got, "john")
+	}
+	if got := resolveLinkedPeerID(links, "discord", "john#1234"); got != "john" {
+		t.Errorf("discord:john#1234 resolved to %q, want %q", got, "john")
+	}
+	if got := resolveLinkedPeerID(links, "discord", "123"); got != "" {
+		t.Errorf("discord:123 resolved to %q, want no link", got)
+	}
+}
+
+func TestResolveLinkedPeerID_AmbiguousIDIsDeterministic(t *testing.T) {
+	links := map[string][]string{
+		"zed":   {"telegram:123"},
+		"alice": {"telegram:123"},
+		"mike":  {"telegram:123"},
+	}
+	for i := 0; i < 20; i++ {
+		if got := resolveLinkedPeerID(links, "telegram", "123"); got != "alice" {
+			t.Fatalf("resolved to %q, want the first canonical name %q", got, "alice")
+		}
+	}
+}
+
 func TestResolveLinkedPeerID_CanonicalPeerID(t *testing.T) {
 	// When peerID is already in canonical "platform:id" format,
 	// it should match identity_links that use the bare ID.
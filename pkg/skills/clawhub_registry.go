@@ -22,13 +22,17 @@ const (
 // ClawHubRegistry implements SkillRegistry for the ClawHub platform.
 type ClawHubRegistry struct {
 	baseURL         string
-	authToken       string // Optional - for elevated rate limits
+	authToken       string // Optional - for elevated rate limits or private registries
+	authHeader      string // Optional - custom header name for authToken
 	searchPath      string // Search API
 	skillsPath      string // For retrieving skill metadata
 	downloadPath    string // For fetching ZIP files for download
 	maxZipSize      int
 	maxResponseSize int
 	client          *http.Client
+	// allowedDomains optionally restricts archive download hosts; see
+	// SetAllowedDomains.
+	allowedDomains []string
 }
 
 // NewClawHubRegistry creates a new ClawHub registry client from config.
@@ -65,9 +69,10 @@ func NewClawHubRegistry(cfg ClawHubConfig) *ClawHubRegistry {
 		maxResp = cfg.MaxResponseSize
 	}
 
-	return &ClawHubRegistry{
+	c := &ClawHubRegistry{
 		baseURL:         baseURL,
 		authToken:       cfg.AuthToken,
+		authHeader:      cfg.AuthHeader,
 		searchPath:      searchPath,
 		skillsPath:      skillsPath,
 		downloadPath:    downloadPath,
@@ -82,6 +87,37 @@ func NewClawHubRegistry(cfg ClawHubConfig) *ClawHubRegistry {
 			},
 		},
 	}
+	c.client.CheckRedirect = c.checkRedirect
+	return c
+}
+
+// SetAllowedDomains restricts skill archive downloads, including every
+// redirect hop, to the given domains and their subdomains. An empty list
+// allows any host.
+func (c *ClawHubRegistry) SetAllowedDomains(domains []string) {
+	c.allowedDomains = normalizeDomains(domains)
+}
+
+// SetMaxDownloadSize lowers the archive size limit to maxBytes when it is
+// positive and below max_zip_size.
+func (c *ClawHubRegistry) SetMaxDownloadSize(maxBytes int64) {
+	if maxBytes > 0 && maxBytes < int64(c.maxZipSize) {
+		c.maxZipSize = int(maxBytes)
+	}
+}
+
+func (c *ClawHubRegistry) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxSkillDownloadRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxSkillDownloadRedirects)
+	}
+	return c.checkDownloadHost(req.URL)
+}
+
+func (c *ClawHubRegistry) checkDownloadHost(u *url.URL) error {
+	if !hostInDomains(u.Hostname(), c.allowedDomains) {
+		return fmt.Errorf("host %q is not in the allowed download domains", u.Hostname())
+	}
+	return nil
 }
 
 func (c *ClawHubRegistry) Name() string {
@@ -307,7 +343,11 @@ func (c *ClawHubRegistry) newGetRequest(ctx context.Context, urlStr, accept stri
 	}
 	req.Header.Set("Accept", accept)
 	if c.authToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.authToken)
+		if c.authHeader != "" {
+			req.Header.Set(c.authHeader, c.authToken)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+c.authToken)
+		}
 	}
 	return req, nil
 }
@@ -317,6 +357,9 @@ func (c *ClawHubRegistry) downloadToTempFileWithRetry(ctx context.Context, urlSt
 	if err != nil {
 		return "", err
 	}
+	if err := c.checkDownloadHost(req.URL); err != nil {
+		return "", err
+	}
 
 	resp, err := utils.DoRequestWithRetry(c.client, req)
 	if err != nil {
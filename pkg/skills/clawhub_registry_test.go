@@ -230,6 +230,63 @@ func TestClawHubRegistryAuthToken(t *testing.T) {
 	_, _ = reg.Search(context.Background(), "test", 5)
 }
 
+func TestClawHubRegistryAuthHeaderOnAllRequests(t *testing.T) {
+	zipBuf := createTestZip(t, map[string]string{
+		"SKILL.md": "---\nname: private-skill\ndescription: A test\n---\nHello skill",
+	})
+
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "team-secret", r.Header.Get("X-Api-Key"), "path %s", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"), "path %s", r.URL.Path)
+		seen = append(seen, r.URL.Path)
+
+		switch r.URL.Path {
+		case "/api/v1/search":
+			json.NewEncoder(w).Encode(clawhubSearchResponse{Results: nil})
+		case "/api/v1/skills/private-skill":
+			json.NewEncoder(w).Encode(clawhubSkillResponse{
+				Slug:          "private-skill",
+				LatestVersion: &clawhubVersionInfo{Version: "1.0.0"},
+			})
+		case "/api/v1/download":
+			w.Header().Set("Content-Type", "application/zip")
+			w.Write(zipBuf)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	reg := NewClawHubRegistry(ClawHubConfig{
+		Enabled:    true,
+		BaseURL:    srv.URL,
+		AuthToken:  "team-secret",
+		AuthHeader: "X-Api-Key",
+	})
+
+	_, err := reg.Search(context.Background(), "private", 5)
+	require.NoError(t, err)
+	_, err = reg.DownloadAndInstall(context.Background(), "private-skill", "", filepath.Join(t.TempDir(), "private-skill"))
+	require.NoError(t, err)
+
+	assert.Contains(t, seen, "/api/v1/search")
+	assert.Contains(t, seen, "/api/v1/skills/private-skill")
+	assert.Contains(t, seen, "/api/v1/download")
+}
+
+func TestClawHubRegistryPublicSendsNoAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(clawhubSearchResponse{Results: nil})
+	}))
+	defer srv.Close()
+
+	reg := NewClawHubRegistry(ClawHubConfig{Enabled: true, BaseURL: srv.URL, AuthHeader: "X-Api-Key"})
+	_, err := reg.Search(context.Background(), "test", 5)
+	require.NoError(t, err)
+}
+
 func TestExtractZipPathTraversal(t *testing.T) {
 	// Create a ZIP with a path traversal entry.
 	var buf bytes.Buffer
@@ -336,3 +393,36 @@ func createTestZip(t *testing.T, files map[string]string) []byte {
 	require.NoError(t, zw.Close())
 	return buf.Bytes()
 }
+
+func TestRegistryManagerFromConfigAppliesDownloadLimits(t *testing.T) {
+	zipBuf := createTestZip(t, map[string]string{
+		"SKILL.md": "---\nname: test-skill\ndescription: A test\n---\n" + string(bytes.Repeat([]byte("x"), 4096)),
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/download" {
+			w.Write(zipBuf)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	newRegistry := func(cfg RegistryConfig) SkillRegistry {
+		cfg.ClawHub = ClawHubConfig{Enabled: true, BaseURL: srv.URL}
+		return NewRegistryManagerFromConfig(cfg).GetRegistry("clawhub")
+	}
+
+	reg := newRegistry(RegistryConfig{AllowedDomains: []string{"clawhub.ai"}})
+	_, err := reg.DownloadAndInstall(context.Background(), "test-skill", "1.0.0", filepath.Join(t.TempDir(), "a"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "allowed download domains")
+
+	reg = newRegistry(RegistryConfig{MaxDownloadSize: 64})
+	_, err = reg.DownloadAndInstall(context.Background(), "test-skill", "1.0.0", filepath.Join(t.TempDir(), "b"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too large")
+
+	reg = newRegistry(RegistryConfig{AllowedDomains: []string{"127.0.0.1"}, MaxDownloadSize: 1 << 20})
+	_, err = reg.DownloadAndInstall(context.Background(), "test-skill", "1.0.0", filepath.Join(t.TempDir(), "c"))
+	require.NoError(t, err)
+}
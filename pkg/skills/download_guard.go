@@ -0,0 +1,158 @@
+package skills
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// allowPrivateSkillHosts disables the private-address check on skill downloads.
+// It is false at runtime; tests flip it to reach httptest servers on loopback.
+var allowPrivateSkillHosts atomic.Bool
+
+const maxSkillDownloadRedirects = 10
+
+// SetAllowedDomains restricts skill downloads to the given domains and their
+// subdomains. An empty list allows any public host.
+func (si *SkillInstaller) SetAllowedDomains(domains []string) {
+	si.allowedDomains = normalizeDomains(domains)
+}
+
+// normalizeDomains lowercases domains and drops blanks and trailing dots.
+func normalizeDomains(domains []string) []string {
+	allowed := make([]string, 0, len(domains))
+	for _, d := range domains {
+		d = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(d)), ".")
+		if d != "" {
+			allowed = append(allowed, d)
+		}
+	}
+	return allowed
+}
+
+// checkRedirect validates every redirect hop so a public URL cannot bounce
+// the installer to an internal address.
+func (si *SkillInstaller) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxSkillDownloadRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxSkillDownloadRedirects)
+	}
+	return si.checkDownloadURL(req.URL)
+}
+
+// checkDownloadURL rejects URLs that are not http(s), fall outside the
+// configured domain allowlist, or name a local host or private address
+// literally. Download URLs can originate from the LLM, so this guards
+// against SSRF into the host's network (e.g. cloud metadata services).
+// Hostnames are not resolved here: the client's dialer checks the address
+// it actually connects to (see safeSkillDialContext).
+func (si *SkillInstaller) checkDownloadURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return errors.New("download URL has no host")
+	}
+
+	if !si.domainAllowed(host) {
+		return fmt.Errorf("host %q is not in the allowed download domains", host)
+	}
+
+	if allowPrivateSkillHosts.Load() {
+		return nil
+	}
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("blocked download from local host %q", host)
+	}
+	if ip := net.ParseIP(host); ip != nil && utils.IsPrivateOrRestrictedIP(ip) {
+		return fmt.Errorf("blocked download from private address %s", host)
+	}
+	return nil
+}
+
+// safeSkillDialContext dials only public addresses, resolving hostnames at
+// connect time so a DNS answer cannot change between check and use.
+// Addresses for which isProxy reports true are dialed unchecked: the proxy
+// is chosen by the operator, not the download URL, and often runs locally.
+func safeSkillDialContext(
+	dialer *net.Dialer,
+	isProxy func(address string) bool,
+) func(context.Context, string, string) (net.Conn, error) {
+	safeDial := utils.SafeDialContext(dialer, utils.IsPrivateOrRestrictedIP)
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if allowPrivateSkillHosts.Load() || (isProxy != nil && isProxy(address)) {
+			return dialer.DialContext(ctx, network, address)
+		}
+		return safeDial(ctx, network, address)
+	}
+}
+
+// proxyAddresses records the proxies a transport has chosen, so the dialer
+// can tell a connection to the proxy from one to the download host.
+type proxyAddresses struct {
+	seen sync.Map // "host:port" -> struct{}
+}
+
+// track wraps a transport's Proxy function, remembering each proxy address
+// it returns (the configured proxy or one from HTTP_PROXY/HTTPS_PROXY).
+func (p *proxyAddresses) track(proxy func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	if proxy == nil {
+		return nil
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		u, err := proxy(req)
+		if err == nil && u != nil {
+			p.seen.Store(proxyDialAddress(u), struct{}{})
+		}
+		return u, err
+	}
+}
+
+func (p *proxyAddresses) contains(address string) bool {
+	_, ok := p.seen.Load(address)
+	return ok
+}
+
+// proxyDialAddress returns the address the transport dials for proxy u,
+// filling in the scheme's default port like net/http does.
+func proxyDialAddress(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return net.JoinHostPort(u.Hostname(), port)
+	}
+	port := "80"
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+func (si *SkillInstaller) domainAllowed(host string) bool {
+	return hostInDomains(host, si.allowedDomains)
+}
+
+// hostInDomains reports whether host is one of domains or a subdomain of
+// one. An empty list allows every host.
+func hostInDomains(host string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package skills
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withPrivateSkillHostsAllowed(t *testing.T) {
+	t.Helper()
+	previous := allowPrivateSkillHosts.Load()
+	allowPrivateSkillHosts.Store(true)
+	t.Cleanup(func() {
+		allowPrivateSkillHosts.Store(previous)
+	})
+}
+
+func TestSkillInstaller_DownloadFile_BlocksMetadataService(t *testing.T) {
+	installer, err := NewSkillInstaller(t.TempDir(), "", "")
+	if err != nil {
+		t.Fatalf("NewSkillInstaller() error = %v", err)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "SKILL.md")
+	err = installer.downloadFile(context.Background(), "http://169.254.169.254/latest/meta-data/", localPath)
+	if err == nil {
+		t.Fatal("expected metadata service address to be blocked")
+	}
+	if !strings.Contains(err.Error(), "blocked") {
+		t.Errorf("expected blocked error, got: %v", err)
+	}
+}
+
+func TestSkillInstaller_DownloadFile_BlocksRedirectToLocalhost(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("internal server must not be reached")
+	}))
+	defer internal.Close()
+
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL, http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	installer, err := NewSkillInstaller(t.TempDir(), "", "")
+	if err != nil {
+		t.Fatalf("NewSkillInstaller() error = %v", err)
+	}
+
+	// Request the public-looking first hop directly so the redirect check,
+	// not the up-front URL check, is what stops the request.
+	resp, err := installer.client.Get(redirector.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected redirect to localhost to be blocked")
+	}
+	if !strings.Contains(err.Error(), "blocked") {
+		t.Errorf("expected blocked error, got: %v", err)
+	}
+}
+
+func TestSkillInstaller_AllowedDomains(t *testing.T) {
+	installer, err := NewSkillInstaller(t.TempDir(), "", "")
+	if err != nil {
+		t.Fatalf("NewSkillInstaller() error = %v", err)
+	}
+	installer.SetAllowedDomains([]string{"GitHubUserContent.com.", " "})
+
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"raw.githubusercontent.com", true},
+		{"githubusercontent.com", true},
+		{"evilgithubusercontent.com", false},
+		{"example.com", false},
+	}
+	for _, tt := range tests {
+		if got := installer.domainAllowed(tt.host); got != tt.allowed {
+			t.Errorf("domainAllowed(%q) = %v, want %v", tt.host, got, tt.allowed)
+		}
+	}
+
+	err = installer.checkDownloadURL(&url.URL{Scheme: "https", Host: "example.com"})
+	if err == nil || !strings.Contains(err.Error(), "allowed download domains") {
+		t.Errorf("expected allowlist rejection, got: %v", err)
+	}
+}
+
+func TestSafeSkillDialContext_ChecksResolvedAddress(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on loopback: %v", err)
+	}
+	defer listener.Close()
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "localhost" passes no literal check here; only the address it resolves
+	// to at connect time gives it away.
+	dial := safeSkillDialContext(&net.Dialer{Timeout: time.Second}, nil)
+	conn, err := dial(context.Background(), "tcp", net.JoinHostPort("localhost", port))
+	if err == nil {
+		conn.Close()
+		t.Fatal("expected a hostname resolving to loopback to be blocked at connect time")
+	}
+	if !strings.Contains(err.Error(), "private") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSkillInstaller_DownloadFile_AllowsLocalProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte("# skill"))
+	}))
+	defer proxy.Close()
+
+	installer, err := NewSkillInstaller(t.TempDir(), "", proxy.URL)
+	if err != nil {
+		t.Fatalf("NewSkillInstaller() error = %v", err)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "SKILL.md")
+	if err := installer.downloadFile(context.Background(), "http://skills.example.com/SKILL.md", localPath); err != nil {
+		t.Fatalf("download through a loopback proxy failed: %v", err)
+	}
+	if proxied != "http://skills.example.com/SKILL.md" {
+		t.Errorf("proxy saw %q, want the download URL", proxied)
+	}
+
+	// The proxy exemption does not extend to download targets.
+	err = installer.downloadFile(context.Background(), "http://169.254.169.254/latest/meta-data/", localPath)
+	if err == nil || !strings.Contains(err.Error(), "blocked") {
+		t.Errorf("expected metadata service address to stay blocked, got: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -32,11 +33,18 @@ type GitHubRef struct {
 	SubPath  string // Path within the repository
 }
 
+// defaultMaxSkillDownloadSize caps a single skill file download.
+const defaultMaxSkillDownloadSize = 10 * 1024 * 1024 // 10 MB
+
 type SkillInstaller struct {
 	workspace   string
 	client      *http.Client
 	githubToken string
 	proxy       string
+	// allowedDomains optionally restricts download hosts; see SetAllowedDomains.
+	allowedDomains []string
+	// maxDownloadSize caps each downloaded file in bytes.
+	maxDownloadSize int64
 }
 
 // NewSkillInstaller creates a new skill installer.
@@ -47,12 +55,32 @@ func NewSkillInstaller(workspace, githubToken, proxy string) (*SkillInstaller, e
 		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
 
-	return &SkillInstaller{
-		workspace:   workspace,
-		client:      client,
-		githubToken: githubToken,
-		proxy:       proxy,
-	}, nil
+	si := &SkillInstaller{
+		workspace:       workspace,
+		client:          client,
+		githubToken:     githubToken,
+		proxy:           proxy,
+		maxDownloadSize: defaultMaxSkillDownloadSize,
+	}
+	client.CheckRedirect = si.checkRedirect
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		proxies := &proxyAddresses{}
+		transport.Proxy = proxies.track(transport.Proxy)
+		transport.DialContext = safeSkillDialContext(&net.Dialer{
+			Timeout:   15 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}, proxies.contains)
+	}
+	return si, nil
+}
+
+// SetMaxDownloadSize sets the per-file download limit in bytes.
+// Non-positive values restore the default of 10 MB.
+func (si *SkillInstaller) SetMaxDownloadSize(maxBytes int64) {
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxSkillDownloadSize
+	}
+	si.maxDownloadSize = maxBytes
 }
 
 // parseGitHubRef parses a GitHub reference.
@@ -145,6 +173,9 @@ func (si *SkillInstaller) getGithubDirAllFiles(ctx context.Context, apiURL, loca
 	if err != nil {
 		return err
 	}
+	if err := si.checkDownloadURL(req.URL); err != nil {
+		return err
+	}
 	if si.githubToken != "" {
 		req.Header.Set("Authorization", "Bearer "+si.githubToken)
 	}
@@ -201,7 +232,7 @@ func (si *SkillInstaller) downloadRaw(ctx context.Context, owner, repo, ref, sub
 	}
 
 	// Use chunked download to temporary file.
-	tmpPath, err := utils.DownloadToFile(ctx, si.client, req, 0)
+	tmpPath, err := utils.DownloadToFile(ctx, si.client, req, si.maxDownloadSize)
 	if err != nil {
 		return fmt.Errorf("failed to fetch skill: %w", err)
 	}
@@ -226,9 +257,12 @@ func (si *SkillInstaller) downloadFile(ctx context.Context, url, localPath strin
 	if err != nil {
 		return err
 	}
+	if err := si.checkDownloadURL(req.URL); err != nil {
+		return err
+	}
 
 	// Use chunked download to temporary file, then move atomically to target.
-	tmpPath, err := utils.DownloadToFile(ctx, si.client, req, 0)
+	tmpPath, err := utils.DownloadToFile(ctx, si.client, req, si.maxDownloadSize)
 	if err != nil {
 		return err
 	}
@@ -246,6 +246,8 @@ func TestNewSkillInstaller_InvalidProxy(t *testing.T) {
 }
 
 func TestSkillInstaller_DownloadFile(t *testing.T) {
+	withPrivateSkillHostsAllowed(t)
+
 	// Create a test server that serves files
 	content := "test file content for skill download"
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -310,6 +312,8 @@ func TestSkillInstaller_DownloadFile(t *testing.T) {
 }
 
 func TestSkillInstaller_DownloadRaw(t *testing.T) {
+	withPrivateSkillHostsAllowed(t)
+
 	content := "raw skill content"
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -473,6 +477,8 @@ func TestGitHubContent_Struct(t *testing.T) {
 }
 
 func TestSkillInstaller_GetGithubDirAllFiles(t *testing.T) {
+	withPrivateSkillHostsAllowed(t)
+
 	tmpDir := t.TempDir()
 	installer, err := NewSkillInstaller(tmpDir, "", "")
 	if err != nil {
@@ -638,6 +644,8 @@ func TestSkillInstaller_InstallFromGitHub_WithToken(t *testing.T) {
 }
 
 func TestSkillInstaller_ContextCancellation(t *testing.T) {
+	withPrivateSkillHostsAllowed(t)
+
 	tmpDir := t.TempDir()
 	installer, err := NewSkillInstaller(tmpDir, "", "")
 	if err != nil {
@@ -663,3 +671,53 @@ func TestSkillInstaller_ContextCancellation(t *testing.T) {
 		t.Error("downloadFile() expected error for canceled context, got nil")
 	}
 }
+
+func TestSkillInstaller_DownloadFile_ExceedsMaxSize(t *testing.T) {
+	withPrivateSkillHostsAllowed(t)
+
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(strings.Repeat("a", 2048)))
+	}))
+	defer server.Close()
+
+	installer, err := NewSkillInstaller(t.TempDir(), "", "")
+	if err != nil {
+		t.Fatalf("NewSkillInstaller() error = %v", err)
+	}
+	installer.SetMaxDownloadSize(1024)
+
+	localPath := filepath.Join(t.TempDir(), "skill", "SKILL.md")
+	err = installer.downloadFile(context.Background(), server.URL, localPath)
+	if err == nil {
+		t.Fatal("downloadFile() expected error for oversized body, got nil")
+	}
+	if !strings.Contains(err.Error(), "too large") {
+		t.Errorf("expected size limit error, got: %v", err)
+	}
+
+	if _, statErr := os.Stat(localPath); !os.IsNotExist(statErr) {
+		t.Errorf("expected no file at %s, stat err = %v", localPath, statErr)
+	}
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 0 {
+		t.Errorf("expected partial temp file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestSkillInstaller_SetMaxDownloadSizeDefault(t *testing.T) {
+	installer, err := NewSkillInstaller(t.TempDir(), "", "")
+	if err != nil {
+		t.Fatalf("NewSkillInstaller() error = %v", err)
+	}
+	if installer.maxDownloadSize != defaultMaxSkillDownloadSize {
+		t.Errorf("maxDownloadSize = %d, want default %d", installer.maxDownloadSize, defaultMaxSkillDownloadSize)
+	}
+	installer.SetMaxDownloadSize(0)
+	if installer.maxDownloadSize != defaultMaxSkillDownloadSize {
+		t.Errorf("SetMaxDownloadSize(0) = %d, want default", installer.maxDownloadSize)
+	}
+}
@@ -269,6 +269,26 @@ func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 	return metadata
 }
 
+// ValidateSkillFile checks that content is a SKILL.md whose frontmatter
+// gives a valid name and description.
+func ValidateSkillFile(content []byte) error {
+	frontmatter, _ := splitFrontmatter(string(content))
+	if frontmatter == "" {
+		return errors.New("missing frontmatter")
+	}
+
+	var meta struct {
+		Name        string `json:"name"        yaml:"name"`
+		Description string `json:"description" yaml:"description"`
+	}
+	if err := json.Unmarshal([]byte(frontmatter), &meta); err != nil {
+		if err := yaml.Unmarshal([]byte(frontmatter), &meta); err != nil {
+			return fmt.Errorf("invalid frontmatter: %w", err)
+		}
+	}
+	return SkillInfo{Name: meta.Name, Description: meta.Description}.validate()
+}
+
 func extractMarkdownMetadata(content string) (title, description string) {
 	p := parser.NewWithExtensions(parser.CommonExtensions)
 	doc := markdown.Parse([]byte(content), p)
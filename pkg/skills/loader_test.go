@@ -417,3 +417,12 @@ func TestGetSkillMetadata_IgnoresHTMLCommentBlocks(t *testing.T) {
 	assert.Equal(t, "biomed-skill", meta.Name)
 	assert.Equal(t, "Summarize biomedical papers.", meta.Description)
 }
+
+func TestValidateSkillFile(t *testing.T) {
+	assert.NoError(t, ValidateSkillFile([]byte("---\nname: weather\ndescription: Weather lookups\n---\n# Weather\n")))
+	assert.NoError(t, ValidateSkillFile([]byte("---\n{\"name\": \"weather\", \"description\": \"Weather lookups\"}\n---\n")))
+
+	assert.ErrorContains(t, ValidateSkillFile([]byte("# Weather\n")), "missing frontmatter")
+	assert.ErrorContains(t, ValidateSkillFile([]byte("---\nname: weather\n---\n")), "description is required")
+	assert.ErrorContains(t, ValidateSkillFile([]byte("---\nname: bad name\ndescription: x\n---\n")), "alphanumeric")
+}
@@ -62,6 +62,10 @@ type SkillRegistry interface {
 type RegistryConfig struct {
 	ClawHub               ClawHubConfig
 	MaxConcurrentSearches int
+	// AllowedDomains and MaxDownloadSize apply the skill download limits
+	// (tools.skills.github) to registry archive downloads as well.
+	AllowedDomains  []string
+	MaxDownloadSize int64
 }
 
 // ClawHubConfig configures the ClawHub registry.
@@ -69,6 +73,7 @@ type ClawHubConfig struct {
 	Enabled         bool
 	BaseURL         string
 	AuthToken       string
+	AuthHeader      string // header carrying AuthToken; "" = "Authorization: Bearer <token>"
 	SearchPath      string // e.g. "/api/v1/search"
 	SkillsPath      string // e.g. "/api/v1/skills"
 	DownloadPath    string // e.g. "/api/v1/download"
@@ -101,7 +106,10 @@ func NewRegistryManagerFromConfig(cfg RegistryConfig) *RegistryManager {
 		rm.maxConcurrent = cfg.MaxConcurrentSearches
 	}
 	if cfg.ClawHub.Enabled {
-		rm.AddRegistry(NewClawHubRegistry(cfg.ClawHub))
+		clawHub := NewClawHubRegistry(cfg.ClawHub)
+		clawHub.SetAllowedDomains(cfg.AllowedDomains)
+		clawHub.SetMaxDownloadSize(cfg.MaxDownloadSize)
+		rm.AddRegistry(clawHub)
 	}
 	return rm
 }
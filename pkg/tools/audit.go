@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// AuditEntry is one record of a tool invocation. Args are redacted with the
+// logger's secret rules before the entry reaches a recorder.
+type AuditEntry struct {
+	Time       time.Time      `json:"time"`
+	Tool       string         `json:"tool"`
+	Channel    string         `json:"channel,omitempty"`
+	ChatID     string         `json:"chat_id,omitempty"`
+	Args       map[string]any `json:"args,omitempty"`
+	Status     string         `json:"status"` // "ok", "error", or "async"
+	DurationMS int64          `json:"duration_ms"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// AuditRecorder receives an entry for every tool call made through a
+// ToolRegistry. Implementations must be safe for concurrent use.
+type AuditRecorder interface {
+	Record(entry AuditEntry)
+}
+
+// FileAuditRecorder appends audit entries as JSON lines to a file.
+type FileAuditRecorder struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileAuditRecorder returns a recorder that appends to path, creating
+// parent directories as needed.
+func NewFileAuditRecorder(path string) (*FileAuditRecorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create audit log directory: %w", err)
+	}
+	return &FileAuditRecorder{path: path}, nil
+}
+
+// Record appends entry to the audit file. Failures are logged, never returned,
+// so auditing cannot break tool execution.
+func (r *FileAuditRecorder) Record(entry AuditEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logger.WarnCF("tool", "Failed to encode audit entry",
+			map[string]any{"tool": entry.Tool, "error": err.Error()})
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		logger.WarnCF("tool", "Failed to open audit log",
+			map[string]any{"path": r.path, "error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		logger.WarnCF("tool", "Failed to write audit entry",
+			map[string]any{"path": r.path, "error": err.Error()})
+	}
+}
+
+func newAuditEntry(
+	name string,
+	args map[string]any,
+	channel, chatID string,
+	result *ToolResult,
+	duration time.Duration,
+) AuditEntry {
+	entry := AuditEntry{
+		Time:       time.Now().UTC(),
+		Tool:       name,
+		Channel:    channel,
+		ChatID:     chatID,
+		Args:       logger.RedactFields(args),
+		Status:     "ok",
+		DurationMS: duration.Milliseconds(),
+	}
+	switch {
+	case result.IsError:
+		entry.Status = "error"
+		entry.Error = result.ForLLM
+	case result.Async:
+		entry.Status = "async"
+	}
+	return entry
+}
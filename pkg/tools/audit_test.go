@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type memoryAuditRecorder struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+func (r *memoryAuditRecorder) Record(entry AuditEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+func TestToolRegistry_AuditRedactsSecrets(t *testing.T) {
+	reg := NewToolRegistry()
+	reg.Register(&mockRegistryTool{
+		name:   "transfer_token",
+		desc:   "send tokens",
+		params: map[string]any{"type": "object"},
+		result: SilentResult("sent"),
+	})
+	rec := &memoryAuditRecorder{}
+	reg.SetAuditRecorder(rec)
+
+	reg.ExecuteWithContext(context.Background(), "transfer_token", map[string]any{
+		"to":     "0xabc",
+		"amount": "1.5",
+		"pin":    "123456",
+	}, "telegram", "chat-1", nil)
+
+	if len(rec.entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(rec.entries))
+	}
+	entry := rec.entries[0]
+	if entry.Tool != "transfer_token" || entry.Status != "ok" {
+		t.Errorf("entry = %+v, want tool transfer_token status ok", entry)
+	}
+	if entry.Channel != "telegram" || entry.ChatID != "chat-1" {
+		t.Errorf("entry channel/chat = %q/%q", entry.Channel, entry.ChatID)
+	}
+	if entry.Args["pin"] != "[REDACTED]" {
+		t.Errorf("pin = %v, want redacted", entry.Args["pin"])
+	}
+	if entry.Args["to"] != "0xabc" {
+		t.Errorf("to = %v, want 0xabc", entry.Args["to"])
+	}
+}
+
+func TestToolRegistry_AuditRecordsErrorsAndUnknownTools(t *testing.T) {
+	reg := NewToolRegistry()
+	reg.Register(&mockRegistryTool{
+		name:   "fails",
+		params: map[string]any{"type": "object"},
+		result: ErrorResult("boom"),
+	})
+	rec := &memoryAuditRecorder{}
+	reg.SetAuditRecorder(rec)
+
+	reg.Execute(context.Background(), "fails", nil)
+	reg.Execute(context.Background(), "missing", nil)
+
+	if len(rec.entries) != 2 {
+		t.Fatalf("expected 2 audit entries, got %d", len(rec.entries))
+	}
+	if rec.entries[0].Status != "error" || rec.entries[0].Error != "boom" {
+		t.Errorf("failing tool entry = %+v", rec.entries[0])
+	}
+	if rec.entries[1].Tool != "missing" || rec.entries[1].Status != "error" {
+		t.Errorf("unknown tool entry = %+v", rec.entries[1])
+	}
+}
+
+func TestFileAuditRecorder_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "tool_audit.jsonl")
+	rec, err := NewFileAuditRecorder(path)
+	if err != nil {
+		t.Fatalf("NewFileAuditRecorder() error: %v", err)
+	}
+
+	rec.Record(AuditEntry{Tool: "a", Status: "ok"})
+	rec.Record(AuditEntry{Tool: "b", Status: "error", Error: "bad"})
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer f.Close()
+
+	var tools []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid audit line %q: %v", scanner.Text(), err)
+		}
+		tools = append(tools, entry.Tool)
+	}
+	if len(tools) != 2 || tools[0] != "a" || tools[1] != "b" {
+		t.Errorf("audit tools = %v, want [a b]", tools)
+	}
+}
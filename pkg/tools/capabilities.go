@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+// CapabilitiesTool lets the agent (and, through /list capabilities, users)
+// see which tools and installed skills are available.
+type CapabilitiesTool struct {
+	registry   *ToolRegistry
+	listSkills func() []skills.SkillInfo
+}
+
+// NewCapabilitiesTool creates a tool describing the tools in registry and
+// the skills returned by listSkills, which may be nil.
+func NewCapabilitiesTool(registry *ToolRegistry, listSkills func() []skills.SkillInfo) *CapabilitiesTool {
+	return &CapabilitiesTool{
+		registry:   registry,
+		listSkills: listSkills,
+	}
+}
+
+func (t *CapabilitiesTool) Name() string {
+	return "capabilities"
+}
+
+func (t *CapabilitiesTool) Description() string {
+	return "List the tools and installed skills available to you, with short descriptions. " +
+		"Use it when the user asks what you can do."
+}
+
+func (t *CapabilitiesTool) Parameters() map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"properties": map[string]any{},
+	}
+}
+
+func (t *CapabilitiesTool) Execute(_ context.Context, _ map[string]any) *ToolResult {
+	var skillList []skills.SkillInfo
+	if t.listSkills != nil {
+		skillList = t.listSkills()
+	}
+	return NewToolResult(FormatCapabilities(t.registry, skillList))
+}
+
+// FormatCapabilities renders the callable tools in registry and skillList as
+// a chat-friendly listing.
+func FormatCapabilities(registry *ToolRegistry, skillList []skills.SkillInfo) string {
+	var sb strings.Builder
+
+	var summaries []string
+	if registry != nil {
+		summaries = registry.GetSummaries()
+	}
+	if len(summaries) == 0 {
+		sb.WriteString("Tools: none available\n")
+	} else {
+		fmt.Fprintf(&sb, "Tools (%d):\n", len(summaries))
+		for _, s := range summaries {
+			sb.WriteString(s)
+			sb.WriteByte('\n')
+		}
+	}
+
+	sb.WriteByte('\n')
+	if len(skillList) == 0 {
+		sb.WriteString("Skills: none installed")
+	} else {
+		fmt.Fprintf(&sb, "Skills (%d):\n", len(skillList))
+		for _, s := range skillList {
+			fmt.Fprintf(&sb, "- `%s` - %s\n", s.Name, s.Description)
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func TestCapabilitiesTool_ListsToolsAndSkills(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("read_file", "Read the contents of a file"))
+	r.RegisterHidden(newMockTool("mcp_hidden", "not yet discovered"))
+	tool := NewCapabilitiesTool(r, func() []skills.SkillInfo {
+		return []skills.SkillInfo{{Name: "weather", Description: "Get the forecast"}}
+	})
+	r.Register(tool)
+
+	result := tool.Execute(context.Background(), nil)
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+
+	for _, want := range []string{
+		"- `read_file` - Read the contents of a file",
+		"- `capabilities` - " + tool.Description(),
+		"Skills (1):\n- `weather` - Get the forecast",
+	} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("listing missing %q:\n%s", want, result.ForLLM)
+		}
+	}
+	if strings.Contains(result.ForLLM, "mcp_hidden") {
+		t.Errorf("hidden tools should not be listed:\n%s", result.ForLLM)
+	}
+}
+
+func TestFormatCapabilities_Empty(t *testing.T) {
+	got := FormatCapabilities(NewToolRegistry(), nil)
+	if want := "Tools: none available\n\nSkills: none installed"; got != want {
+		t.Errorf("FormatCapabilities() = %q, want %q", got, want)
+	}
+}
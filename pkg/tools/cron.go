@@ -26,6 +26,7 @@ type CronTool struct {
 	execTool     *ExecTool
 	allowCommand bool
 	execEnabled  bool
+	execTimeout  time.Duration
 }
 
 // NewCronTool creates a new CronTool
@@ -60,6 +61,7 @@ func NewCronTool(
 		execTool:     execTool,
 		allowCommand: allowCommand,
 		execEnabled:  execEnabled,
+		execTimeout:  execTimeout,
 	}, nil
 }
 
@@ -362,6 +364,14 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 	// For deliver=false, process through agent (for complex tasks)
 	sessionKey := fmt.Sprintf("cron-%s", job.ID)
 
+	// Bound agent turns by the same timeout as scheduled commands so a stuck
+	// provider or tool loop cannot hold the cron runner indefinitely.
+	if t.execTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.execTimeout)
+		defer cancel()
+	}
+
 	// Call agent with job's message
 	response, err := t.executor.ProcessDirectWithChannel(
 		ctx,
@@ -237,3 +237,39 @@ func TestCronTool_ExecuteJobPublishesErrorWhenExecDisabled(t *testing.T) {
 		t.Fatalf("expected exec disabled message, got: %s", msg.Content)
 	}
 }
+
+type blockingJobExecutor struct{}
+
+func (blockingJobExecutor) ProcessDirectWithChannel(
+	ctx context.Context, content, sessionKey, channel, chatID string,
+) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestCronTool_ExecuteJobAgentTurnHonorsExecTimeout(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron.json")
+	cronService := cron.NewCronService(storePath, nil)
+	tool, err := NewCronTool(
+		cronService, blockingJobExecutor{}, bus.NewMessageBus(), t.TempDir(), true,
+		50*time.Millisecond, config.DefaultConfig(),
+	)
+	if err != nil {
+		t.Fatalf("NewCronTool() error: %v", err)
+	}
+
+	job := &cron.CronJob{ID: "job-1"}
+	job.Payload.Message = "summarize the news"
+
+	done := make(chan string, 1)
+	go func() { done <- tool.ExecuteJob(context.Background(), job) }()
+
+	select {
+	case got := <-done:
+		if !strings.Contains(got, context.DeadlineExceeded.Error()) {
+			t.Fatalf("ExecuteJob() = %q, want deadline exceeded error", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ExecuteJob did not return after exec timeout")
+	}
+}
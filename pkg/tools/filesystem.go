@@ -20,6 +20,8 @@ import (
 
 const MaxReadFileSize = 64 * 1024 // 64KB limit to avoid context overflow
 
+const MaxWriteFileSize = 1024 * 1024 // 1MB limit on a single write_file call
+
 func validatePathWithAllowPaths(path, workspace string, restrict bool, patterns []*regexp.Regexp) (string, error) {
 	if workspace == "" {
 		return path, fmt.Errorf("workspace is not defined")
@@ -532,6 +534,11 @@ func (t *WriteFileTool) Execute(ctx context.Context, args map[string]any) *ToolR
 		return ErrorResult("content is required")
 	}
 
+	if len(content) > MaxWriteFileSize {
+		return ErrorResult(fmt.Sprintf(
+			"content is %d bytes, exceeding the %d byte write_file limit", len(content), MaxWriteFileSize))
+	}
+
 	overwrite, _ := args["overwrite"].(bool)
 
 	if !overwrite {
@@ -403,6 +403,69 @@ func TestFilesystemTool_ReadFile_RejectsSymlinkEscape(t *testing.T) {
 	}
 }
 
+func TestFilesystemTool_RejectsTraversalOutsideWorkspace(t *testing.T) {
+	root := t.TempDir()
+	workspace := filepath.Join(root, "workspace")
+	if err := os.MkdirAll(workspace, 0o755); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	secret := filepath.Join(root, "secret.txt")
+	if err := os.WriteFile(secret, []byte("top secret"), 0o644); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+
+	readResult := NewReadFileTool(workspace, true, MaxReadFileSize).Execute(context.Background(), map[string]any{
+		"path": "../secret.txt",
+	})
+	assert.True(t, readResult.IsError, "expected ../ read to be blocked")
+	assert.NotContains(t, readResult.ForLLM, "top secret")
+
+	writeResult := NewWriteFileTool(workspace, true).Execute(context.Background(), map[string]any{
+		"path":      "../escaped.txt",
+		"content":   "pwned",
+		"overwrite": true,
+	})
+	assert.True(t, writeResult.IsError, "expected ../ write to be blocked")
+	_, err := os.Stat(filepath.Join(root, "escaped.txt"))
+	assert.True(t, os.IsNotExist(err), "file must not be created outside the workspace")
+}
+
+func TestFilesystemTool_WriteFile_RejectsSymlinkEscape(t *testing.T) {
+	root := t.TempDir()
+	workspace := filepath.Join(root, "workspace")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{workspace, outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(workspace, "link")); err != nil {
+		t.Skipf("symlink not supported in this environment: %v", err)
+	}
+
+	result := NewWriteFileTool(workspace, true).Execute(context.Background(), map[string]any{
+		"path":    "link/escaped.txt",
+		"content": "pwned",
+	})
+	assert.True(t, result.IsError, "expected write through symlink to be blocked")
+	_, err := os.Stat(filepath.Join(outside, "escaped.txt"))
+	assert.True(t, os.IsNotExist(err), "file must not be created through the symlink")
+}
+
+func TestFilesystemTool_WriteFile_RejectsOversizedContent(t *testing.T) {
+	workspace := t.TempDir()
+	tool := NewWriteFileTool(workspace, true)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"path":    "big.txt",
+		"content": strings.Repeat("x", MaxWriteFileSize+1),
+	})
+	assert.True(t, result.IsError, "expected oversized write to be rejected")
+	assert.Contains(t, result.ForLLM, "write_file limit")
+	_, err := os.Stat(filepath.Join(workspace, "big.txt"))
+	assert.True(t, os.IsNotExist(err), "oversized content must not be written")
+}
+
 func TestFilesystemTool_EmptyWorkspace_AccessDenied(t *testing.T) {
 	tool := NewReadFileTool("", true, MaxReadFileSize) // restrict=true but workspace=""
 
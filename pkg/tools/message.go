@@ -6,7 +6,10 @@ import (
 	"sync/atomic"
 )
 
-type SendCallback func(channel, chatID, content string) error
+// SendCallback delivers a message. ctx is the tool call's context, so the
+// message carries the turn's trace ID. With editLast set, the message
+// replaces the last one sent to the chat where the channel can edit.
+type SendCallback func(ctx context.Context, channel, chatID, content string, editLast bool) error
 
 type MessageTool struct {
 	sendCallback SendCallback
@@ -41,6 +44,10 @@ func (t *MessageTool) Parameters() map[string]any {
 				"type":        "string",
 				"description": "Optional: target chat/user ID",
 			},
+			"edit_last": map[string]any{
+				"type":        "boolean",
+				"description": "Optional: replace the last message sent to the chat instead of sending a new one, for status updates (e.g. pending -> confirmed). Sent as a new message where editing is not supported.",
+			},
 		},
 		"required": []string{"content"},
 	}
@@ -69,6 +76,7 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 
 	channel, _ := args["channel"].(string)
 	chatID, _ := args["chat_id"].(string)
+	editLast, _ := args["edit_last"].(bool)
 
 	if channel == "" {
 		channel = ToolChannel(ctx)
@@ -85,7 +93,7 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 		return &ToolResult{ForLLM: "Message sending not configured", IsError: true}
 	}
 
-	if err := t.sendCallback(channel, chatID, content); err != nil {
+	if err := t.sendCallback(ctx, channel, chatID, content, editLast); err != nil {
 		return &ToolResult{
 			ForLLM:  fmt.Sprintf("sending message: %v", err),
 			IsError: true,
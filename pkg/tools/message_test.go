@@ -10,7 +10,7 @@ func TestMessageTool_Execute_Success(t *testing.T) {
 	tool := NewMessageTool()
 
 	var sentChannel, sentChatID, sentContent string
-	tool.SetSendCallback(func(channel, chatID, content string) error {
+	tool.SetSendCallback(func(ctx context.Context, channel, chatID, content string, editLast bool) error {
 		sentChannel = channel
 		sentChatID = chatID
 		sentContent = content
@@ -61,7 +61,7 @@ func TestMessageTool_Execute_WithCustomChannel(t *testing.T) {
 	tool := NewMessageTool()
 
 	var sentChannel, sentChatID string
-	tool.SetSendCallback(func(channel, chatID, content string) error {
+	tool.SetSendCallback(func(ctx context.Context, channel, chatID, content string, editLast bool) error {
 		sentChannel = channel
 		sentChatID = chatID
 		return nil
@@ -96,7 +96,7 @@ func TestMessageTool_Execute_SendFailure(t *testing.T) {
 	tool := NewMessageTool()
 
 	sendErr := errors.New("network error")
-	tool.SetSendCallback(func(channel, chatID, content string) error {
+	tool.SetSendCallback(func(ctx context.Context, channel, chatID, content string, editLast bool) error {
 		return sendErr
 	})
 
@@ -149,7 +149,7 @@ func TestMessageTool_Execute_NoTargetChannel(t *testing.T) {
 	tool := NewMessageTool()
 	// No WithToolContext — channel/chatID are empty
 
-	tool.SetSendCallback(func(channel, chatID, content string) error {
+	tool.SetSendCallback(func(ctx context.Context, channel, chatID, content string, editLast bool) error {
 		return nil
 	})
 
@@ -252,3 +252,21 @@ func TestMessageTool_Parameters(t *testing.T) {
 		t.Error("Expected chat_id type to be 'string'")
 	}
 }
+
+func TestMessageTool_Execute_EditLast(t *testing.T) {
+	tool := NewMessageTool()
+
+	var gotEditLast []bool
+	tool.SetSendCallback(func(ctx context.Context, channel, chatID, content string, editLast bool) error {
+		gotEditLast = append(gotEditLast, editLast)
+		return nil
+	})
+
+	ctx := WithToolContext(context.Background(), "test-channel", "test-chat-id")
+	tool.Execute(ctx, map[string]any{"content": "transfer pending"})
+	tool.Execute(ctx, map[string]any{"content": "transfer confirmed", "edit_last": true})
+
+	if len(gotEditLast) != 2 || gotEditLast[0] || !gotEditLast[1] {
+		t.Errorf("editLast = %v, want [false true]", gotEditLast)
+	}
+}
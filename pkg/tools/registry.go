@@ -3,12 +3,14 @@ package tools
 import (
 	"context"
 	"fmt"
+	"path"
 	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/metrics"
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
@@ -22,6 +24,8 @@ type ToolRegistry struct {
 	tools   map[string]*ToolEntry
 	mu      sync.RWMutex
 	version atomic.Uint64 // incremented on Register/RegisterHidden for cache invalidation
+	audit   AuditRecorder
+	filter  func(name string) bool // when set, tools it rejects are not registered
 }
 
 func NewToolRegistry() *ToolRegistry {
@@ -34,6 +38,10 @@ func (r *ToolRegistry) Register(tool Tool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	name := tool.Name()
+	if r.filter != nil && !r.filter(name) {
+		logger.DebugCF("tools", "Tool excluded by filter", map[string]any{"name": name})
+		return
+	}
 	if _, exists := r.tools[name]; exists {
 		logger.WarnCF("tools", "Tool registration overwrites existing tool",
 			map[string]any{"name": name})
@@ -52,6 +60,10 @@ func (r *ToolRegistry) RegisterHidden(tool Tool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	name := tool.Name()
+	if r.filter != nil && !r.filter(name) {
+		logger.DebugCF("tools", "Tool excluded by filter", map[string]any{"name": name})
+		return
+	}
 	if _, exists := r.tools[name]; exists {
 		logger.WarnCF("tools", "Hidden tool registration overwrites existing tool",
 			map[string]any{"name": name})
@@ -150,6 +162,52 @@ func (r *ToolRegistry) Get(name string) (Tool, bool) {
 	return entry.Tool, true
 }
 
+// SetAuditRecorder installs a recorder that receives an entry for every
+// tool call. Pass nil to disable auditing.
+func (r *ToolRegistry) SetAuditRecorder(rec AuditRecorder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.audit = rec
+}
+
+// SetFilter restricts which tools can be registered from now on: tools
+// whose name filter rejects are skipped by Register and RegisterHidden.
+func (r *ToolRegistry) SetFilter(filter func(name string) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.filter = filter
+}
+
+// NameFilter returns a SetFilter function that accepts names matching an
+// allow entry (all names when allow is empty) and no deny entry. Entries
+// are names or path.Match glob patterns. It returns nil when both lists
+// are empty.
+func NameFilter(allow, deny []string) func(name string) bool {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil
+	}
+	matches := func(patterns []string, name string) bool {
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, name); ok || p == name {
+				return true
+			}
+		}
+		return false
+	}
+	return func(name string) bool {
+		if len(allow) > 0 && !matches(allow, name) {
+			return false
+		}
+		return !matches(deny, name)
+	}
+}
+
+func (r *ToolRegistry) auditRecorder() AuditRecorder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.audit
+}
+
 func (r *ToolRegistry) Execute(ctx context.Context, name string, args map[string]any) *ToolResult {
 	return r.ExecuteWithContext(ctx, name, args, "", "", nil)
 }
@@ -177,7 +235,14 @@ func (r *ToolRegistry) ExecuteWithContext(
 			map[string]any{
 				"tool": name,
 			})
-		return ErrorResult(fmt.Sprintf("tool %q not found", name)).WithError(fmt.Errorf("tool not found"))
+		result := ErrorResult(fmt.Sprintf("tool %q not found", name)).WithError(fmt.Errorf("tool not found"))
+		// The name comes from the model; a fixed label keeps made-up tool
+		// names from growing the metric's label set.
+		metrics.ToolInvocations.Inc("unknown", "error")
+		if rec := r.auditRecorder(); rec != nil {
+			rec.Record(newAuditEntry(name, args, channel, chatID, result, 0))
+		}
+		return result
 	}
 
 	// Inject channel/chatID into ctx so tools read them via ToolChannel(ctx)/ToolChatID(ctx).
@@ -232,8 +297,13 @@ func (r *ToolRegistry) ExecuteWithContext(
 
 	duration := time.Since(start)
 
+	if rec := r.auditRecorder(); rec != nil {
+		rec.Record(newAuditEntry(name, args, channel, chatID, result, duration))
+	}
+
 	// Log based on result type
 	if result.IsError {
+		metrics.ToolInvocations.Inc(name, "error")
 		logger.ErrorCF("tool", "Tool execution failed",
 			map[string]any{
 				"tool":     name,
@@ -241,12 +311,14 @@ func (r *ToolRegistry) ExecuteWithContext(
 				"error":    result.ForLLM,
 			})
 	} else if result.Async {
+		metrics.ToolInvocations.Inc(name, "async")
 		logger.InfoCF("tool", "Tool started (async)",
 			map[string]any{
 				"tool":     name,
 				"duration": duration.Milliseconds(),
 			})
 	} else {
+		metrics.ToolInvocations.Inc(name, "ok")
 		logger.InfoCF("tool", "Tool execution completed",
 			map[string]any{
 				"tool":          name,
@@ -346,7 +418,9 @@ func (r *ToolRegistry) Clone() *ToolRegistry {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	clone := &ToolRegistry{
-		tools: make(map[string]*ToolEntry, len(r.tools)),
+		tools:  make(map[string]*ToolEntry, len(r.tools)),
+		audit:  r.audit,
+		filter: r.filter,
 	}
 	for name, entry := range r.tools {
 		clone.tools[name] = &ToolEntry{
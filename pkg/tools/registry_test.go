@@ -1,12 +1,15 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/sipeed/picoclaw/pkg/metrics"
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
@@ -105,6 +108,47 @@ func TestToolRegistry_RegisterOverwrite(t *testing.T) {
 	}
 }
 
+func TestToolRegistry_SetFilter(t *testing.T) {
+	r := NewToolRegistry()
+	r.SetFilter(NameFilter([]string{"read_*", "exec", "mcp_*"}, []string{"mcp_github_*"}))
+
+	r.Register(newMockTool("read_file", "reads files"))
+	r.Register(newMockTool("exec", "runs commands"))
+	r.Register(newMockTool("write_file", "writes files"))
+	r.RegisterHidden(newMockTool("mcp_fs_list", "lists files"))
+	r.RegisterHidden(newMockTool("mcp_github_push", "pushes commits"))
+
+	want := []string{"exec", "mcp_fs_list", "read_file"}
+	if got := r.List(); !reflect.DeepEqual(got, want) {
+		t.Errorf("registered tools = %v, want %v", got, want)
+	}
+
+	clone := r.Clone()
+	clone.Register(newMockTool("write_file", "writes files"))
+	if _, ok := clone.Get("write_file"); ok {
+		t.Error("expected clone to keep the filter")
+	}
+}
+
+func TestNameFilter(t *testing.T) {
+	if NameFilter(nil, nil) != nil {
+		t.Fatal("expected nil filter when no lists are set")
+	}
+
+	denyOnly := NameFilter(nil, []string{"exec"})
+	if !denyOnly("read_file") || denyOnly("exec") {
+		t.Error("deny-only filter should allow everything except denied tools")
+	}
+
+	both := NameFilter([]string{"exec", "web_*"}, []string{"exec"})
+	if both("exec") {
+		t.Error("deny should win over allow")
+	}
+	if !both("web_fetch") || both("read_file") {
+		t.Error("allow list should admit only matching tools")
+	}
+}
+
 func TestToolRegistry_Execute_Success(t *testing.T) {
 	r := NewToolRegistry()
 	r.Register(&mockRegistryTool{
@@ -135,6 +179,15 @@ func TestToolRegistry_Execute_NotFound(t *testing.T) {
 	if result.Err == nil {
 		t.Error("expected Err to be set via WithError")
 	}
+
+	var buf bytes.Buffer
+	metrics.WriteText(&buf)
+	if !strings.Contains(buf.String(), `picoclaw_tool_invocations_total{tool="unknown",result="error"}`) {
+		t.Error("expected the missing tool to be counted under tool=\"unknown\"")
+	}
+	if strings.Contains(buf.String(), `tool="missing"`) {
+		t.Error("the model-supplied tool name must not become a metric label")
+	}
 }
 
 func TestToolRegistry_ExecuteWithContext_InjectsToolContext(t *testing.T) {
@@ -34,6 +34,10 @@ type ToolResult struct {
 	// Media contains media store refs produced by this tool.
 	// When non-empty, the agent will publish these as OutboundMediaMessage.
 	Media []string `json:"media,omitempty"`
+
+	// Data is an optional machine-readable payload for programmatic consumers
+	// (gateway, other tools). ForLLM remains the text the model sees.
+	Data map[string]any `json:"data,omitempty"`
 }
 
 // NewToolResult creates a basic ToolResult with content for the LLM.
@@ -158,3 +162,13 @@ func (tr *ToolResult) WithError(err error) *ToolResult {
 	tr.Err = err
 	return tr
 }
+
+// WithData attaches a structured payload and returns the result for chaining.
+//
+// Example:
+//
+//	result := SilentResult("Balance: 1.5 ETH").WithData(map[string]any{"balance": "1.5", "symbol": "ETH"})
+func (tr *ToolResult) WithData(data map[string]any) *ToolResult {
+	tr.Data = data
+	return tr
+}
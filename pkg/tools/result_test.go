@@ -3,6 +3,7 @@ package tools
 import (
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -227,3 +228,37 @@ func TestToolResultJSONStructure(t *testing.T) {
 		t.Errorf("Expected silent false, got %v", parsed["silent"])
 	}
 }
+
+func TestToolResultWithData(t *testing.T) {
+	result := SilentResult("Balance: 1.5 ETH").WithData(map[string]any{
+		"address": "0xabc",
+		"balance": "1.5",
+		"symbol":  "ETH",
+	})
+
+	if result.ForLLM != "Balance: 1.5 ETH" {
+		t.Errorf("ForLLM = %q, want text preserved", result.ForLLM)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	var decoded ToolResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if decoded.ForLLM != result.ForLLM {
+		t.Errorf("ForLLM mismatch: got %q", decoded.ForLLM)
+	}
+	if decoded.Data["symbol"] != "ETH" || decoded.Data["address"] != "0xabc" {
+		t.Errorf("Data mismatch: got %v", decoded.Data)
+	}
+
+	// Results without data omit the field entirely.
+	plain, _ := json.Marshal(SilentResult("x"))
+	if strings.Contains(string(plain), `"data"`) {
+		t.Errorf("expected data to be omitted, got %s", plain)
+	}
+}
@@ -24,6 +24,7 @@ type ExecTool struct {
 	allowPatterns       []*regexp.Regexp
 	customAllowPatterns []*regexp.Regexp
 	allowedPathPatterns []*regexp.Regexp
+	allowedBinaries     map[string]bool
 	restrictToWorkspace bool
 	allowRemote         bool
 }
@@ -79,6 +80,13 @@ var (
 		regexp.MustCompile(`\bsource\s+.*\.sh\b`),
 	}
 
+	// commandSeparatorPattern splits a shell command line into the simple
+	// commands whose binaries are checked against allowed_binaries.
+	commandSeparatorPattern = regexp.MustCompile(`\|\||&&|[|;&\n]`)
+
+	// envAssignmentPattern matches a NAME=value assignment prefix.
+	envAssignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
 	// absolutePathPattern matches absolute file paths in commands (Unix and Windows).
 	absolutePathPattern = regexp.MustCompile(`[A-Za-z]:\\[^\\\"']+|/[^\s\"']+`)
 
@@ -150,6 +158,14 @@ func NewExecToolWithConfig(
 		timeout = time.Duration(config.Tools.Exec.TimeoutSeconds) * time.Second
 	}
 
+	var allowedBinaries map[string]bool
+	if config != nil && len(config.Tools.Exec.AllowedBinaries) > 0 {
+		allowedBinaries = make(map[string]bool, len(config.Tools.Exec.AllowedBinaries))
+		for _, name := range config.Tools.Exec.AllowedBinaries {
+			allowedBinaries[strings.ToLower(strings.TrimSpace(name))] = true
+		}
+	}
+
 	return &ExecTool{
 		workingDir:          workingDir,
 		timeout:             timeout,
@@ -157,6 +173,7 @@ func NewExecToolWithConfig(
 		allowPatterns:       nil,
 		customAllowPatterns: customAllowPatterns,
 		allowedPathPatterns: allowedPathPatterns,
+		allowedBinaries:     allowedBinaries,
 		restrictToWorkspace: restrict,
 		allowRemote:         allowRemote,
 	}, nil
@@ -395,6 +412,23 @@ func (t *ExecTool) guardCommand(command, cwd string) string {
 		}
 	}
 
+	if len(t.allowedBinaries) > 0 {
+		// Substitutions run programs the allowlist cannot see.
+		if strings.Contains(cmd, "$(") || strings.Contains(cmd, "`") {
+			return "Command blocked by safety guard (command substitution not allowed with allowed_binaries)"
+		}
+		if strings.Contains(cmd, "<(") || strings.Contains(cmd, ">(") {
+			return "Command blocked by safety guard (process substitution not allowed with allowed_binaries)"
+		}
+		// A path-qualified program (./ls, /tmp/ls) must be listed with that
+		// exact path, so a listed name cannot be shadowed by another file.
+		for _, name := range commandBinaries(cmd) {
+			if !t.allowedBinaries[strings.ToLower(name)] {
+				return fmt.Sprintf("Command blocked by safety guard (binary %q not in allowed_binaries)", name)
+			}
+		}
+	}
+
 	if t.restrictToWorkspace {
 		if strings.Contains(cmd, "..\\") || strings.Contains(cmd, "../") {
 			return "Command blocked by safety guard (path traversal detected)"
@@ -462,6 +496,23 @@ func (t *ExecTool) guardCommand(command, cwd string) string {
 	return ""
 }
 
+// commandBinaries returns the program run by each simple command in a shell
+// command line, as written, skipping leading NAME=value assignments.
+func commandBinaries(command string) []string {
+	var names []string
+	for _, segment := range commandSeparatorPattern.Split(command, -1) {
+		for _, field := range strings.Fields(segment) {
+			field = strings.TrimLeft(field, "({")
+			if field == "" || envAssignmentPattern.MatchString(field) {
+				continue
+			}
+			names = append(names, field)
+			break
+		}
+	}
+	return names
+}
+
 func (t *ExecTool) SetTimeout(timeout time.Duration) {
 	t.timeout = timeout
 }
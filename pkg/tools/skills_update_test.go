@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+type fakeSkillRegistry struct {
+	latest     string
+	installErr error
+	installs   int
+	onFailure  func(targetDir string) // runs after a failed install
+}
+
+func (r *fakeSkillRegistry) Name() string { return "fake" }
+
+func (r *fakeSkillRegistry) Search(context.Context, string, int) ([]skills.SearchResult, error) {
+	return nil, nil
+}
+
+func (r *fakeSkillRegistry) GetSkillMeta(_ context.Context, slug string) (*skills.SkillMeta, error) {
+	return &skills.SkillMeta{Slug: slug, LatestVersion: r.latest, RegistryName: r.Name()}, nil
+}
+
+func (r *fakeSkillRegistry) DownloadAndInstall(
+	_ context.Context, _, version, targetDir string,
+) (*skills.InstallResult, error) {
+	r.installs++
+	if r.installErr != nil {
+		// Simulate a partial install before failing.
+		_ = os.MkdirAll(targetDir, 0o755)
+		if r.onFailure != nil {
+			r.onFailure(targetDir)
+		}
+		return nil, r.installErr
+	}
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "SKILL.md"), []byte("v"+version), 0o600); err != nil {
+		return nil, err
+	}
+	return &skills.InstallResult{Version: version}, nil
+}
+
+func newInstalledSkill(t *testing.T, version string) (string, string) {
+	t.Helper()
+	workspace := t.TempDir()
+	skillDir := filepath.Join(workspace, "skills", "weather")
+	require.NoError(t, os.MkdirAll(skillDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("v"+version), 0o600))
+	require.NoError(t, writeOriginMeta(skillDir, "fake", "weather", version))
+	return workspace, skillDir
+}
+
+func newUpdateSkillTool(workspace string, reg *fakeSkillRegistry) *UpdateSkillTool {
+	mgr := skills.NewRegistryManager()
+	mgr.AddRegistry(reg)
+	return NewUpdateSkillTool(mgr, workspace)
+}
+
+func TestUpdateSkillToolUpdatesToLatest(t *testing.T) {
+	workspace, skillDir := newInstalledSkill(t, "1.0.0")
+	reg := &fakeSkillRegistry{latest: "1.1.0"}
+
+	result := newUpdateSkillTool(workspace, reg).Execute(context.Background(), map[string]any{"slug": "weather"})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "from v1.0.0 to v1.1.0")
+	assert.Equal(t, 1, reg.installs)
+
+	data, err := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", string(data))
+
+	origin, err := readOriginMeta(skillDir)
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", origin.InstalledVersion)
+
+	for _, dir := range []string{workspace, filepath.Dir(workspace)} {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		for _, e := range entries {
+			assert.NotContains(t, e.Name(), ".skill-update-", "backup directory should be removed")
+		}
+	}
+}
+
+func TestUpdateSkillToolAlreadyCurrent(t *testing.T) {
+	workspace, _ := newInstalledSkill(t, "1.0.0")
+	reg := &fakeSkillRegistry{latest: "1.0.0"}
+
+	result := newUpdateSkillTool(workspace, reg).Execute(context.Background(), map[string]any{"slug": "weather"})
+	require.False(t, result.IsError, result.ForLLM)
+	assert.Contains(t, result.ForLLM, "already up to date")
+	assert.Equal(t, 0, reg.installs)
+}
+
+func TestUpdateSkillToolRestoresBackupOnFailure(t *testing.T) {
+	workspace, skillDir := newInstalledSkill(t, "1.0.0")
+	reg := &fakeSkillRegistry{latest: "2.0.0", installErr: errors.New("download failed")}
+
+	result := newUpdateSkillTool(workspace, reg).Execute(context.Background(), map[string]any{"slug": "weather"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "kept v1.0.0")
+
+	data, err := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", string(data))
+}
+
+func TestUpdateSkillToolKeepsBackupWhenRestoreFails(t *testing.T) {
+	workspace, _ := newInstalledSkill(t, "1.0.0")
+	reg := &fakeSkillRegistry{latest: "2.0.0", installErr: errors.New("download failed")}
+	reg.onFailure = func(targetDir string) {
+		// Replace the skills directory with a file so the restore cannot
+		// move the backup back.
+		skillsDir := filepath.Dir(targetDir)
+		require.NoError(t, os.RemoveAll(skillsDir))
+		require.NoError(t, os.WriteFile(skillsDir, nil, 0o600))
+	}
+
+	result := newUpdateSkillTool(workspace, reg).Execute(context.Background(), map[string]any{"slug": "weather"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "previous version is kept at")
+
+	backups, err := filepath.Glob(filepath.Join(filepath.Dir(workspace), ".skill-update-*", "weather"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	t.Cleanup(func() { os.RemoveAll(filepath.Dir(backups[0])) })
+	assert.Contains(t, result.ForLLM, backups[0])
+	assert.NotContains(t, backups[0], workspace, "backup must not be staged inside the workspace")
+
+	data, err := os.ReadFile(filepath.Join(backups[0], "SKILL.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", string(data))
+}
+
+func TestSkillInstallLockIsSharedPerWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	assert.Same(t, skillInstallLock(workspace), skillInstallLock(workspace+string(filepath.Separator)))
+	assert.NotSame(t, skillInstallLock(workspace), skillInstallLock(t.TempDir()))
+}
+
+func TestUpdateSkillToolRequiresOriginMeta(t *testing.T) {
+	workspace := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "skills", "manual"), 0o755))
+
+	result := newUpdateSkillTool(workspace, &fakeSkillRegistry{}).Execute(
+		context.Background(), map[string]any{"slug": "manual"},
+	)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "no registry origin metadata")
+}
@@ -77,3 +77,30 @@ func TestSpawnTool_Execute_NilManager(t *testing.T) {
 		t.Errorf("Error message should mention manager not configured, got: %s", result.ForLLM)
 	}
 }
+
+// TestSpawnTool_SharesToolContract verifies spawn goes through the same
+// Tool / *ToolResult contract as every other tool in the registry.
+func TestSpawnTool_SharesToolContract(t *testing.T) {
+	var (
+		_ Tool          = (*SpawnTool)(nil)
+		_ AsyncExecutor = (*SpawnTool)(nil)
+		_ Tool          = (*mockRegistryTool)(nil)
+	)
+
+	reg := NewToolRegistry()
+	reg.Register(NewSpawnTool(nil))
+	reg.Register(&mockRegistryTool{
+		name:   "plain",
+		params: map[string]any{"type": "object"},
+		result: SilentResult("done"),
+	})
+
+	spawnResult := reg.Execute(context.Background(), "spawn", map[string]any{"task": "x"})
+	if spawnResult == nil || !spawnResult.IsError {
+		t.Fatalf("expected spawn error result through registry, got %+v", spawnResult)
+	}
+	plainResult := reg.Execute(context.Background(), "plain", nil)
+	if plainResult == nil || plainResult.ForLLM != "done" {
+		t.Fatalf("expected plain tool result through registry, got %+v", plainResult)
+	}
+}
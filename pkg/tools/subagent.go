@@ -33,6 +33,7 @@ type SubagentManager struct {
 	temperature    float64
 	hasMaxTokens   bool
 	hasTemperature bool
+	requestTimeout time.Duration
 	nextID         int
 }
 
@@ -51,6 +52,13 @@ func NewSubagentManager(
 	}
 }
 
+// SetRequestTimeout bounds each subagent LLM call; 0 means no bound.
+func (sm *SubagentManager) SetRequestTimeout(timeout time.Duration) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.requestTimeout = timeout
+}
+
 // SetLLMOptions sets max tokens and temperature for subagent LLM calls.
 func (sm *SubagentManager) SetLLMOptions(maxTokens int, temperature float64) {
 	sm.mu.Lock()
@@ -144,6 +152,7 @@ After completing the task, provide a clear summary of what was done.`
 	temperature := sm.temperature
 	hasMaxTokens := sm.hasMaxTokens
 	hasTemperature := sm.hasTemperature
+	requestTimeout := sm.requestTimeout
 	sm.mu.RUnlock()
 
 	var llmOptions map[string]any
@@ -158,11 +167,12 @@ After completing the task, provide a clear summary of what was done.`
 	}
 
 	loopResult, err := RunToolLoop(ctx, ToolLoopConfig{
-		Provider:      sm.provider,
-		Model:         sm.defaultModel,
-		Tools:         tools,
-		MaxIterations: maxIter,
-		LLMOptions:    llmOptions,
+		Provider:       sm.provider,
+		Model:          sm.defaultModel,
+		Tools:          tools,
+		MaxIterations:  maxIter,
+		LLMOptions:     llmOptions,
+		RequestTimeout: requestTimeout,
 	}, messages, task.OriginChannel, task.OriginChatID)
 
 	sm.mu.Lock()
@@ -323,6 +333,7 @@ func (t *SubagentTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	temperature := sm.temperature
 	hasMaxTokens := sm.hasMaxTokens
 	hasTemperature := sm.hasTemperature
+	requestTimeout := sm.requestTimeout
 	sm.mu.RUnlock()
 
 	var llmOptions map[string]any
@@ -348,11 +359,12 @@ func (t *SubagentTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	}
 
 	loopResult, err := RunToolLoop(ctx, ToolLoopConfig{
-		Provider:      sm.provider,
-		Model:         sm.defaultModel,
-		Tools:         tools,
-		MaxIterations: maxIter,
-		LLMOptions:    llmOptions,
+		Provider:       sm.provider,
+		Model:          sm.defaultModel,
+		Tools:          tools,
+		MaxIterations:  maxIter,
+		LLMOptions:     llmOptions,
+		RequestTimeout: requestTimeout,
 	}, messages, channel, chatID)
 	if err != nil {
 		return ErrorResult(fmt.Sprintf("Subagent execution failed: %v", err)).WithError(err)
@@ -2,8 +2,10 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
@@ -70,6 +72,38 @@ func TestSubagentManager_SetLLMOptions_AppliesToRunToolLoop(t *testing.T) {
 }
 
 // TestSubagentTool_Name verifies tool name
+// hangingLLMProvider blocks every Chat call until its context ends.
+type hangingLLMProvider struct {
+	MockLLMProvider
+}
+
+func (m *hangingLLMProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	options map[string]any,
+) (*providers.LLMResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSubagentManager_SetRequestTimeout_BoundsLLMCalls(t *testing.T) {
+	manager := NewSubagentManager(&hangingLLMProvider{}, "test-model", "/tmp/test")
+	manager.SetRequestTimeout(20 * time.Millisecond)
+	tool := NewSubagentTool(manager)
+
+	ctx := WithToolContext(context.Background(), "cli", "direct")
+	result := tool.Execute(ctx, map[string]any{"task": "Do something"})
+	if result == nil || !result.IsError {
+		t.Fatalf("Expected timeout error, got: %+v", result)
+	}
+	var timeoutErr *providers.RequestTimeoutError
+	if !errors.As(result.Err, &timeoutErr) {
+		t.Errorf("Err = %v, want *providers.RequestTimeoutError", result.Err)
+	}
+}
+
 func TestSubagentTool_Name(t *testing.T) {
 	provider := &MockLLMProvider{}
 	manager := NewSubagentManager(provider, "test-model", "/tmp/test")
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	defaultTailLines    = 50
+	maxTailLines        = 500
+	defaultTailMaxBytes = 16 * 1024
+)
+
+// TailLogTool returns the last lines of a log file in the workspace logs/
+// directory so operators can troubleshoot from a chat. Output is redacted.
+// Besides internal channels, only the configured admin chats may call it.
+type TailLogTool struct {
+	workspace  string
+	allowChats []string // "channel:chat_id"
+	maxBytes   int64
+}
+
+func NewTailLogTool(workspace string, allowChats []string, maxBytes int) *TailLogTool {
+	if maxBytes <= 0 {
+		maxBytes = defaultTailMaxBytes
+	}
+	return &TailLogTool{
+		workspace:  workspace,
+		allowChats: allowChats,
+		maxBytes:   int64(maxBytes),
+	}
+}
+
+func (t *TailLogTool) Name() string {
+	return "tail_log"
+}
+
+func (t *TailLogTool) Description() string {
+	return "Show the last lines of a log file in the workspace logs/ directory (e.g. logs/app.log). Operator use only."
+}
+
+func (t *TailLogTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path to the log file under logs/, relative to the workspace.",
+			},
+			"lines": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Number of lines to return (max %d).", maxTailLines),
+				"default":     defaultTailLines,
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+func (t *TailLogTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if !t.allowed(ctx) {
+		return ErrorResult("tail_log is restricted to internal channels and admin chats")
+	}
+
+	path, ok := args["path"].(string)
+	if !ok || strings.TrimSpace(path) == "" {
+		return ErrorResult("path is required")
+	}
+	lines, err := getInt64Arg(args, "lines", defaultTailLines)
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	if lines <= 0 {
+		return ErrorResult("lines must be > 0")
+	}
+	lines = min(lines, maxTailLines)
+
+	// Always confined to the workspace logs directory, whatever
+	// restrict_to_workspace says; paths are still given relative to the
+	// workspace.
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.workspace, path)
+	}
+	resolved, err := validatePathWithAllowPaths(path, filepath.Join(t.workspace, "logs"), true, nil)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("%v (tail_log only reads files under logs/)", err))
+	}
+
+	text, err := t.tail(resolved, int(lines))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to read log: %v", err))
+	}
+	if text == "" {
+		return NewToolResult("(log is empty)")
+	}
+	return NewToolResult(logger.RedactString(text))
+}
+
+// allowed reports whether the calling chat may read logs.
+func (t *TailLogTool) allowed(ctx context.Context) bool {
+	channel := strings.TrimSpace(ToolChannel(ctx))
+	if channel == "" {
+		return false
+	}
+	if constants.IsInternalChannel(channel) {
+		return true
+	}
+	return slices.Contains(t.allowChats, channel+":"+ToolChatID(ctx))
+}
+
+// tail reads at most maxBytes from the end of the file and returns its last
+// n lines. A line cut by the byte cap is dropped.
+func (t *TailLogTool) tail(path string, n int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+
+	start := max(info.Size()-t.maxBytes, 0)
+	buf := make([]byte, info.Size()-start)
+	if _, err := f.ReadAt(buf, start); err != nil && err != io.EOF {
+		return "", err
+	}
+
+	text := strings.TrimRight(string(buf), "\n")
+	if start > 0 {
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			text = text[i+1:]
+		} else {
+			text = ""
+		}
+	}
+	if text == "" {
+		return "", nil
+	}
+
+	all := strings.Split(text, "\n")
+	if len(all) > n {
+		all = all[len(all)-n:]
+	}
+	return strings.Join(all, "\n"), nil
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
@@ -24,6 +25,8 @@ type ToolLoopConfig struct {
 	Tools         *ToolRegistry
 	MaxIterations int
 	LLMOptions    map[string]any
+	// RequestTimeout bounds each LLM call; 0 means no bound.
+	RequestTimeout time.Duration
 }
 
 // ToolLoopResult contains the result of running the tool loop.
@@ -64,7 +67,10 @@ func RunToolLoop(
 			llmOpts = map[string]any{}
 		}
 		// 3. Call LLM
-		response, err := config.Provider.Chat(ctx, messages, providerToolDefs, config.Model, llmOpts)
+		response, err := providers.CallWithTimeout(ctx, config.RequestTimeout, config.Model,
+			func(ctx context.Context) (*providers.LLMResponse, error) {
+				return config.Provider.Chat(ctx, messages, providerToolDefs, config.Model, llmOpts)
+			})
 		if err != nil {
 			logger.ErrorCF("toolloop", "LLM call failed",
 				map[string]any{
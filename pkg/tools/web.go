@@ -616,6 +616,16 @@ func (p *GLMSearchProvider) Search(ctx context.Context, query string, count int)
 type WebSearchTool struct {
 	provider   SearchProvider
 	maxResults int
+	// fallbacks are the remaining enabled providers, in priority order,
+	// tried when the primary provider fails.
+	fallbacks []searchCandidate
+}
+
+// searchCandidate pairs an enabled search provider with its configured
+// default result count.
+type searchCandidate struct {
+	provider   SearchProvider
+	maxResults int
 }
 
 type WebSearchToolOptions struct {
@@ -642,61 +652,92 @@ type WebSearchToolOptions struct {
 	Proxy                string
 }
 
+// NewWebSearchTool returns a search tool backed by every enabled provider.
+// The highest-priority provider serves requests; the rest are tried in order
+// when it fails. It returns nil when no provider is enabled.
 func NewWebSearchTool(opts WebSearchToolOptions) (*WebSearchTool, error) {
-	var provider SearchProvider
-	maxResults := 5
-	// Priority: Perplexity > Brave > SearXNG > Tavily > DuckDuckGo > GLM Search
-	if opts.PerplexityEnabled && len(opts.PerplexityAPIKeys) > 0 {
-		client, err := utils.CreateHTTPClient(opts.Proxy, perplexityTimeout)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create HTTP client for Perplexity: %w", err)
-		}
-		provider = &PerplexitySearchProvider{
-			keyPool: NewAPIKeyPool(opts.PerplexityAPIKeys),
-			proxy:   opts.Proxy,
-			client:  client,
-		}
-		if opts.PerplexityMaxResults > 0 {
-			maxResults = opts.PerplexityMaxResults
-		}
-	} else if opts.BraveEnabled && len(opts.BraveAPIKeys) > 0 {
+	candidates, err := searchCandidates(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	return &WebSearchTool{
+		provider:   candidates[0].provider,
+		maxResults: candidates[0].maxResults,
+		fallbacks:  candidates[1:],
+	}, nil
+}
+
+func resultLimit(configured int) int {
+	if configured > 0 {
+		return configured
+	}
+	return 5
+}
+
+// searchCandidates builds the enabled providers in priority order:
+// Brave > Perplexity > SearXNG > Tavily > DuckDuckGo > GLM Search.
+func searchCandidates(opts WebSearchToolOptions) ([]searchCandidate, error) {
+	var candidates []searchCandidate
+	if opts.BraveEnabled && len(opts.BraveAPIKeys) > 0 {
 		client, err := utils.CreateHTTPClient(opts.Proxy, searchTimeout)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create HTTP client for Brave: %w", err)
 		}
-		provider = &BraveSearchProvider{keyPool: NewAPIKeyPool(opts.BraveAPIKeys), proxy: opts.Proxy, client: client}
-		if opts.BraveMaxResults > 0 {
-			maxResults = opts.BraveMaxResults
-		}
-	} else if opts.SearXNGEnabled && opts.SearXNGBaseURL != "" {
-		provider = &SearXNGSearchProvider{baseURL: opts.SearXNGBaseURL}
-		if opts.SearXNGMaxResults > 0 {
-			maxResults = opts.SearXNGMaxResults
+		candidates = append(candidates, searchCandidate{
+			provider:   &BraveSearchProvider{keyPool: NewAPIKeyPool(opts.BraveAPIKeys), proxy: opts.Proxy, client: client},
+			maxResults: resultLimit(opts.BraveMaxResults),
+		})
+	}
+	if opts.PerplexityEnabled && len(opts.PerplexityAPIKeys) > 0 {
+		client, err := utils.CreateHTTPClient(opts.Proxy, perplexityTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client for Perplexity: %w", err)
 		}
-	} else if opts.TavilyEnabled && len(opts.TavilyAPIKeys) > 0 {
+		candidates = append(candidates, searchCandidate{
+			provider: &PerplexitySearchProvider{
+				keyPool: NewAPIKeyPool(opts.PerplexityAPIKeys),
+				proxy:   opts.Proxy,
+				client:  client,
+			},
+			maxResults: resultLimit(opts.PerplexityMaxResults),
+		})
+	}
+	if opts.SearXNGEnabled && opts.SearXNGBaseURL != "" {
+		candidates = append(candidates, searchCandidate{
+			provider:   &SearXNGSearchProvider{baseURL: opts.SearXNGBaseURL},
+			maxResults: resultLimit(opts.SearXNGMaxResults),
+		})
+	}
+	if opts.TavilyEnabled && len(opts.TavilyAPIKeys) > 0 {
 		client, err := utils.CreateHTTPClient(opts.Proxy, searchTimeout)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create HTTP client for Tavily: %w", err)
 		}
-		provider = &TavilySearchProvider{
-			keyPool: NewAPIKeyPool(opts.TavilyAPIKeys),
-			baseURL: opts.TavilyBaseURL,
-			proxy:   opts.Proxy,
-			client:  client,
-		}
-		if opts.TavilyMaxResults > 0 {
-			maxResults = opts.TavilyMaxResults
-		}
-	} else if opts.DuckDuckGoEnabled {
+		candidates = append(candidates, searchCandidate{
+			provider: &TavilySearchProvider{
+				keyPool: NewAPIKeyPool(opts.TavilyAPIKeys),
+				baseURL: opts.TavilyBaseURL,
+				proxy:   opts.Proxy,
+				client:  client,
+			},
+			maxResults: resultLimit(opts.TavilyMaxResults),
+		})
+	}
+	if opts.DuckDuckGoEnabled {
 		client, err := utils.CreateHTTPClient(opts.Proxy, searchTimeout)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create HTTP client for DuckDuckGo: %w", err)
 		}
-		provider = &DuckDuckGoSearchProvider{proxy: opts.Proxy, client: client}
-		if opts.DuckDuckGoMaxResults > 0 {
-			maxResults = opts.DuckDuckGoMaxResults
-		}
-	} else if opts.GLMSearchEnabled && opts.GLMSearchAPIKey != "" {
+		candidates = append(candidates, searchCandidate{
+			provider:   &DuckDuckGoSearchProvider{proxy: opts.Proxy, client: client},
+			maxResults: resultLimit(opts.DuckDuckGoMaxResults),
+		})
+	}
+	if opts.GLMSearchEnabled && opts.GLMSearchAPIKey != "" {
 		client, err := utils.CreateHTTPClient(opts.Proxy, searchTimeout)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create HTTP client for GLM Search: %w", err)
@@ -705,24 +746,18 @@ func NewWebSearchTool(opts WebSearchToolOptions) (*WebSearchTool, error) {
 		if searchEngine == "" {
 			searchEngine = "search_std"
 		}
-		provider = &GLMSearchProvider{
-			apiKey:       opts.GLMSearchAPIKey,
-			baseURL:      opts.GLMSearchBaseURL,
-			searchEngine: searchEngine,
-			proxy:        opts.Proxy,
-			client:       client,
-		}
-		if opts.GLMSearchMaxResults > 0 {
-			maxResults = opts.GLMSearchMaxResults
-		}
-	} else {
-		return nil, nil
+		candidates = append(candidates, searchCandidate{
+			provider: &GLMSearchProvider{
+				apiKey:       opts.GLMSearchAPIKey,
+				baseURL:      opts.GLMSearchBaseURL,
+				searchEngine: searchEngine,
+				proxy:        opts.Proxy,
+				client:       client,
+			},
+			maxResults: resultLimit(opts.GLMSearchMaxResults),
+		})
 	}
-
-	return &WebSearchTool{
-		provider:   provider,
-		maxResults: maxResults,
-	}, nil
+	return candidates, nil
 }
 
 func (t *WebSearchTool) Name() string {
@@ -758,14 +793,33 @@ func (t *WebSearchTool) Execute(ctx context.Context, args map[string]any) *ToolR
 		return ErrorResult("query is required")
 	}
 
-	count := t.maxResults
+	requested := 0
 	if c, ok := args["count"].(float64); ok {
 		if int(c) > 0 && int(c) <= 10 {
-			count = int(c)
+			requested = int(c)
 		}
 	}
 
-	result, err := t.provider.Search(ctx, query, count)
+	candidates := append([]searchCandidate{{provider: t.provider, maxResults: t.maxResults}}, t.fallbacks...)
+	var result string
+	var err error
+	for i, c := range candidates {
+		count := c.maxResults
+		if requested > 0 {
+			count = requested
+		}
+		result, err = c.provider.Search(ctx, query, count)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(candidates)-1 {
+			logger.WarnCF("tool", "Search provider failed, trying next",
+				map[string]any{"provider": fmt.Sprintf("%T", c.provider), "error": err.Error()})
+		}
+	}
 	if err != nil {
 		return ErrorResult(fmt.Sprintf("search failed: %v", err))
 	}
@@ -993,6 +1047,10 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		}
 	}
 
+	if !isTextualMediaType(mediaType) && !isTextualMediaType(sniffMediaType(body)) {
+		return ErrorResult(fmt.Sprintf("unsupported content type %q: web_fetch only returns text content", mediaType))
+	}
+
 	var text, extractor string
 
 	switch {
@@ -1059,9 +1117,44 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 			extractor,
 			truncated,
 		),
+		Data: map[string]any{
+			"url":          urlStr,
+			"status":       resp.StatusCode,
+			"content_type": mediaType,
+			"extractor":    extractor,
+			"truncated":    truncated,
+			"length":       len(text),
+		},
 	}
 }
 
+// isTextualMediaType reports whether mediaType is something web_fetch can
+// meaningfully hand to the model as text.
+func isTextualMediaType(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-javascript", "application/ecmascript", "application/x-ndjson":
+		return true
+	}
+	return false
+}
+
+// sniffMediaType guesses the media type from the body, for servers that omit
+// or mislabel Content-Type.
+func sniffMediaType(body []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(body))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
 func looksLikeHTML(body string) bool {
 	if body == "" {
 		return false
@@ -1101,52 +1194,14 @@ func newSafeDialContext(
 	dialer *net.Dialer,
 	whitelist *privateHostWhitelist,
 ) func(context.Context, string, string) (net.Conn, error) {
+	safeDial := utils.SafeDialContext(dialer, func(ip net.IP) bool {
+		return shouldBlockPrivateIP(ip, whitelist)
+	})
 	return func(ctx context.Context, network, address string) (net.Conn, error) {
 		if allowPrivateWebFetchHosts.Load() {
 			return dialer.DialContext(ctx, network, address)
 		}
-
-		host, port, err := net.SplitHostPort(address)
-		if err != nil {
-			return nil, fmt.Errorf("invalid target address %q: %w", address, err)
-		}
-		if host == "" {
-			return nil, fmt.Errorf("empty target host")
-		}
-
-		if ip := net.ParseIP(host); ip != nil {
-			if shouldBlockPrivateIP(ip, whitelist) {
-				return nil, fmt.Errorf("blocked private or local target: %s", host)
-			}
-			return dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
-		}
-
-		ipAddrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
-		if err != nil {
-			return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
-		}
-
-		attempted := 0
-		var lastErr error
-		for _, ipAddr := range ipAddrs {
-			if shouldBlockPrivateIP(ipAddr.IP, whitelist) {
-				continue
-			}
-			attempted++
-			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ipAddr.IP.String(), port))
-			if err == nil {
-				return conn, nil
-			}
-			lastErr = err
-		}
-
-		if attempted == 0 {
-			return nil, fmt.Errorf("all resolved addresses for %s are private, restricted, or not whitelisted", host)
-		}
-		if lastErr != nil {
-			return nil, fmt.Errorf("failed connecting to public addresses for %s: %w", host, lastErr)
-		}
-		return nil, fmt.Errorf("failed connecting to public addresses for %s", host)
+		return safeDial(ctx, network, address)
 	}
 }
 
@@ -1209,7 +1264,7 @@ func normalizeWhitelistIP(ip net.IP) net.IP {
 }
 
 func shouldBlockPrivateIP(ip net.IP, whitelist *privateHostWhitelist) bool {
-	return isPrivateOrRestrictedIP(ip) && !whitelist.Contains(ip)
+	return utils.IsPrivateOrRestrictedIP(ip) && !whitelist.Contains(ip)
 }
 
 // isObviousPrivateHost performs a lightweight, no-DNS check for obviously private hosts.
@@ -1236,50 +1291,3 @@ func isObviousPrivateHost(host string, whitelist *privateHostWhitelist) bool {
 
 	return false
 }
-
-// isPrivateOrRestrictedIP returns true for IPs that should never be reached via web_fetch:
-// RFC 1918, loopback, link-local (incl. cloud metadata 169.254.x.x), carrier-grade NAT,
-// IPv6 unique-local (fc00::/7), 6to4 (2002::/16), and Teredo (2001:0000::/32).
-func isPrivateOrRestrictedIP(ip net.IP) bool {
-	if ip == nil {
-		return true
-	}
-
-	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
-		ip.IsMulticast() || ip.IsUnspecified() {
-		return true
-	}
-
-	if ip4 := ip.To4(); ip4 != nil {
-		// IPv4 private, loopback, link-local, and carrier-grade NAT ranges.
-		if ip4[0] == 10 ||
-			ip4[0] == 127 ||
-			ip4[0] == 0 ||
-			(ip4[0] == 172 && ip4[1] >= 16 && ip4[1] <= 31) ||
-			(ip4[0] == 192 && ip4[1] == 168) ||
-			(ip4[0] == 169 && ip4[1] == 254) ||
-			(ip4[0] == 100 && ip4[1] >= 64 && ip4[1] <= 127) {
-			return true
-		}
-		return false
-	}
-
-	if len(ip) == net.IPv6len {
-		// IPv6 unique local addresses (fc00::/7)
-		if (ip[0] & 0xfe) == 0xfc {
-			return true
-		}
-		// 6to4 addresses (2002::/16): check the embedded IPv4 at bytes [2:6].
-		if ip[0] == 0x20 && ip[1] == 0x02 {
-			embedded := net.IPv4(ip[2], ip[3], ip[4], ip[5])
-			return isPrivateOrRestrictedIP(embedded)
-		}
-		// Teredo (2001:0000::/32): client IPv4 is at bytes [12:16], XOR-inverted.
-		if ip[0] == 0x20 && ip[1] == 0x01 && ip[2] == 0x00 && ip[3] == 0x00 {
-			client := net.IPv4(ip[12]^0xff, ip[13]^0xff, ip[14]^0xff, ip[15]^0xff)
-			return isPrivateOrRestrictedIP(client)
-		}
-	}
-
-	return false
-}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -57,6 +58,14 @@ func TestWebTool_WebFetch_Success(t *testing.T) {
 	if !strings.Contains(result.ForUser, "bytes") && !strings.Contains(result.ForUser, "extractor") {
 		t.Errorf("Expected ForUser to contain summary, got: %s", result.ForUser)
 	}
+
+	// Data carries the same metadata in machine-readable form
+	if result.Data["url"] != server.URL || result.Data["status"] != http.StatusOK {
+		t.Errorf("Expected Data to describe the fetch, got: %v", result.Data)
+	}
+	if result.Data["content_type"] != "text/html" {
+		t.Errorf("Expected content_type text/html in Data, got: %v", result.Data["content_type"])
+	}
 }
 
 // TestWebTool_WebFetch_JSON verifies JSON content handling
@@ -836,49 +845,6 @@ func TestNewSafeDialContext_AllowsWhitelistedPrivateDNSResolution(t *testing.T)
 	}
 }
 
-// TestIsPrivateOrRestrictedIP_Table tests IP classification logic
-func TestIsPrivateOrRestrictedIP_Table(t *testing.T) {
-	tests := []struct {
-		ip      string
-		blocked bool
-		desc    string
-	}{
-		{"127.0.0.1", true, "IPv4 loopback"},
-		{"10.0.0.1", true, "IPv4 private class A"},
-		{"172.16.0.1", true, "IPv4 private class B"},
-		{"192.168.1.1", true, "IPv4 private class C"},
-		{"169.254.169.254", true, "link-local / cloud metadata"},
-		{"100.64.0.1", true, "carrier-grade NAT"},
-		{"0.0.0.0", true, "unspecified"},
-		{"8.8.8.8", false, "public DNS"},
-		{"1.1.1.1", false, "public DNS"},
-		{"::1", true, "IPv6 loopback"},
-		{"::ffff:127.0.0.1", true, "IPv4-mapped IPv6 loopback"},
-		{"::ffff:10.0.0.1", true, "IPv4-mapped IPv6 private"},
-		{"fc00::1", true, "IPv6 unique local"},
-		{"fd00::1", true, "IPv6 unique local"},
-		{"2002:7f00:0001::1", true, "6to4 with embedded 127.x (private)"},
-		{"2002:0a00:0001::1", true, "6to4 with embedded 10.0.0.1 (private)"},
-		{"2002:0801:0101::1", false, "6to4 with embedded 8.1.1.1 (public)"},
-		{"2001:0000:4136:e378:8000:63bf:f5ff:fffe", true, "Teredo with client 10.0.0.1 (private)"},
-		{"2001:0000:4136:e378:8000:63bf:f7f6:fefe", false, "Teredo with client 8.9.1.1 (public)"},
-		{"2607:f8b0:4004:800::200e", false, "public IPv6 (Google)"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.desc, func(t *testing.T) {
-			ip := net.ParseIP(tt.ip)
-			if ip == nil {
-				t.Fatalf("failed to parse IP: %s", tt.ip)
-			}
-			got := isPrivateOrRestrictedIP(ip)
-			if got != tt.blocked {
-				t.Errorf("isPrivateOrRestrictedIP(%s) = %v, want %v", tt.ip, got, tt.blocked)
-			}
-		})
-	}
-}
-
 // TestWebTool_WebFetch_MissingDomain verifies error handling for URL without domain
 func TestWebTool_WebFetch_MissingDomain(t *testing.T) {
 	tool, err := NewWebFetchTool(50000, format, testFetchLimit)
@@ -1428,3 +1394,135 @@ func TestWebTool_GLMSearch_Priority(t *testing.T) {
 		t.Errorf("Expected GLMSearchProvider when only GLM enabled, got %T", tool2.provider)
 	}
 }
+
+type stubSearchProvider struct {
+	name   string
+	err    error
+	calls  int
+	counts []int
+}
+
+func (p *stubSearchProvider) Search(ctx context.Context, query string, count int) (string, error) {
+	p.calls++
+	p.counts = append(p.counts, count)
+	if p.err != nil {
+		return "", p.err
+	}
+	return "results from " + p.name, nil
+}
+
+func TestWebSearchTool_FallsThroughToNextProvider(t *testing.T) {
+	primary := &stubSearchProvider{name: "primary", err: errors.New("rate limited")}
+	secondary := &stubSearchProvider{name: "secondary"}
+	tool := &WebSearchTool{
+		provider:   primary,
+		maxResults: 3,
+		fallbacks:  []searchCandidate{{provider: secondary, maxResults: 7}},
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"query": "picoclaw"})
+	if result.IsError {
+		t.Fatalf("expected success via fallback, got error: %s", result.ForLLM)
+	}
+	if result.ForLLM != "results from secondary" {
+		t.Errorf("ForLLM = %q, want results from secondary", result.ForLLM)
+	}
+	if primary.calls != 1 || secondary.calls != 1 {
+		t.Errorf("calls = (%d, %d), want (1, 1)", primary.calls, secondary.calls)
+	}
+	if secondary.counts[0] != 7 {
+		t.Errorf("fallback count = %d, want its own max results 7", secondary.counts[0])
+	}
+}
+
+func TestWebSearchTool_AllProvidersFail(t *testing.T) {
+	tool := &WebSearchTool{
+		provider:   &stubSearchProvider{err: errors.New("first down")},
+		maxResults: 5,
+		fallbacks:  []searchCandidate{{provider: &stubSearchProvider{err: errors.New("second down")}, maxResults: 5}},
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"query": "picoclaw", "count": float64(2)})
+	if !result.IsError {
+		t.Fatal("expected error when every provider fails")
+	}
+	if !strings.Contains(result.ForLLM, "second down") {
+		t.Errorf("expected last provider error, got: %s", result.ForLLM)
+	}
+}
+
+func TestNewWebSearchTool_FallbackOrder(t *testing.T) {
+	tool, err := NewWebSearchTool(WebSearchToolOptions{
+		PerplexityEnabled: true,
+		PerplexityAPIKeys: []string{"pplx"},
+		BraveEnabled:      true,
+		BraveAPIKeys:      []string{"brave"},
+		DuckDuckGoEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("NewWebSearchTool() error: %v", err)
+	}
+	if _, ok := tool.provider.(*BraveSearchProvider); !ok {
+		t.Fatalf("primary = %T, want *BraveSearchProvider", tool.provider)
+	}
+	if len(tool.fallbacks) != 2 {
+		t.Fatalf("fallbacks = %d, want 2", len(tool.fallbacks))
+	}
+	if _, ok := tool.fallbacks[0].provider.(*PerplexitySearchProvider); !ok {
+		t.Errorf("fallbacks[0] = %T, want *PerplexitySearchProvider", tool.fallbacks[0].provider)
+	}
+	if _, ok := tool.fallbacks[1].provider.(*DuckDuckGoSearchProvider); !ok {
+		t.Errorf("fallbacks[1] = %T, want *DuckDuckGoSearchProvider", tool.fallbacks[1].provider)
+	}
+}
+
+// TestWebTool_WebFetch_RejectsBinaryContent verifies non-text responses are refused
+func TestWebTool_WebFetch_RejectsBinaryContent(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	}))
+	defer server.Close()
+
+	tool, err := NewWebFetchTool(1000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("NewWebFetchTool() error: %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL})
+	if !result.IsError {
+		t.Fatalf("expected binary content to be rejected, got: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "unsupported content type") {
+		t.Errorf("expected unsupported content type error, got: %s", result.ForLLM)
+	}
+}
+
+// TestWebTool_WebFetch_SniffsUnlabeledText verifies text served without a
+// Content-Type header is still returned
+func TestWebTool_WebFetch_SniffsUnlabeledText(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("plain notes without a header"))
+	}))
+	defer server.Close()
+
+	tool, err := NewWebFetchTool(1000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("NewWebFetchTool() error: %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL})
+	if result.IsError {
+		t.Fatalf("expected unlabeled text to be accepted, got: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "plain notes without a header") {
+		t.Errorf("expected body in result, got: %s", result.ForLLM)
+	}
+}
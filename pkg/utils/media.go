@@ -55,6 +55,7 @@ type DownloadOptions struct {
 	ExtraHeaders map[string]string
 	LoggerPrefix string
 	ProxyURL     string
+	MaxBytes     int64 // 0 means no limit; larger downloads are discarded
 }
 
 // DownloadFile downloads a file from URL to a local temp directory.
@@ -135,7 +136,12 @@ func DownloadFile(urlStr, filename string, opts DownloadOptions) string {
 	}
 	defer out.Close()
 
-	if _, err := io.Copy(out, resp.Body); err != nil {
+	var body io.Reader = resp.Body
+	if opts.MaxBytes > 0 {
+		body = io.LimitReader(resp.Body, opts.MaxBytes+1)
+	}
+	written, err := io.Copy(out, body)
+	if err != nil {
 		out.Close()
 		os.Remove(localPath)
 		logger.ErrorCF(opts.LoggerPrefix, "Failed to write file", map[string]any{
@@ -143,6 +149,15 @@ func DownloadFile(urlStr, filename string, opts DownloadOptions) string {
 		})
 		return ""
 	}
+	if opts.MaxBytes > 0 && written > opts.MaxBytes {
+		out.Close()
+		os.Remove(localPath)
+		logger.WarnCF(opts.LoggerPrefix, "Download exceeds size limit", map[string]any{
+			"url": urlStr,
+			"max": opts.MaxBytes,
+		})
+		return ""
+	}
 
 	logger.DebugCF(opts.LoggerPrefix, "File downloaded successfully", map[string]any{
 		"path": localPath,
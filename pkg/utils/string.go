@@ -36,7 +36,10 @@ func SanitizeMessageContent(input string) string {
 }
 
 // Truncate returns a truncated version of s with at most maxLen runes.
-// Handles multi-byte Unicode characters properly.
+// Handles multi-byte Unicode characters properly and never splits a
+// grapheme cluster such as a ZWJ emoji sequence, a skin-tone modifier, a
+// flag, or a base letter and its combining marks; the cut moves back to the
+// start of the cluster instead.
 // If the string is truncated, "..." is appended to indicate truncation.
 func Truncate(s string, maxLen int) string {
 	// If the no-truncate flag is active, it returns the full string
@@ -52,9 +55,52 @@ func Truncate(s string, maxLen int) string {
 	}
 	// Reserve 3 chars for "..."
 	if maxLen <= 3 {
-		return string(runes[:maxLen])
+		return string(runes[:graphemeCut(runes, maxLen)])
 	}
-	return string(runes[:maxLen-3]) + "..."
+	return string(runes[:graphemeCut(runes, maxLen-3)]) + "..."
+}
+
+// graphemeCut moves cut backwards until runes[:cut] ends on a grapheme
+// cluster boundary. It covers the cases that show up in chat content without
+// pulling in a full UAX #29 implementation.
+func graphemeCut(runes []rune, cut int) int {
+	for cut > 0 && cut < len(runes) {
+		next, prev := runes[cut], runes[cut-1]
+		switch {
+		case isGraphemeExtender(next), prev == zeroWidthJoiner:
+			cut--
+		case isRegionalIndicator(next) && isRegionalIndicator(prev) && oddRegionalRun(runes, cut):
+			cut--
+		default:
+			return cut
+		}
+	}
+	return cut
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// isGraphemeExtender reports runes that attach to the preceding rune.
+func isGraphemeExtender(r rune) bool {
+	return r == zeroWidthJoiner ||
+		(r >= 0xfe00 && r <= 0xfe0f) || // variation selectors
+		(r >= 0x1f3fb && r <= 0x1f3ff) || // emoji skin-tone modifiers
+		(r >= 0xe0020 && r <= 0xe007f) || // emoji tag sequences
+		unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// oddRegionalRun reports whether an odd number of regional indicators end at
+// cut, i.e. cutting there would split a flag pair.
+func oddRegionalRun(runes []rune, cut int) bool {
+	n := 0
+	for i := cut - 1; i >= 0 && isRegionalIndicator(runes[i]); i-- {
+		n++
+	}
+	return n%2 == 1
 }
 
 // DerefStr dereferences a pointer to a string and
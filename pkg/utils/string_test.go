@@ -93,6 +93,48 @@ func TestTruncate(t *testing.T) {
 			maxLen: 5,
 			want:   "Go...",
 		},
+		{
+			name:   "CJK truncated on rune boundary",
+			input:  "你好世界，欢迎使用",
+			maxLen: 5,
+			want:   "你好...",
+		},
+		{
+			name:   "ZWJ family emoji not split",
+			input:  "ab\U0001f468\u200d\U0001f469\u200d\U0001f467xyz",
+			maxLen: 7,
+			want:   "ab...",
+		},
+		{
+			name:   "skin tone modifier kept with base",
+			input:  "hi\U0001f44d\U0001f3fdthere",
+			maxLen: 6,
+			want:   "hi...",
+		},
+		{
+			name:   "flag pair not split",
+			input:  "x\U0001f1ef\U0001f1f5\U0001f1fa\U0001f1f8!!",
+			maxLen: 5,
+			want:   "x...",
+		},
+		{
+			name:   "cut between two flags kept",
+			input:  "x\U0001f1ef\U0001f1f5\U0001f1fa\U0001f1f8!!",
+			maxLen: 6,
+			want:   "x\U0001f1ef\U0001f1f5...",
+		},
+		{
+			name:   "combining accent kept with letter",
+			input:  "cafe\u0301 noir",
+			maxLen: 7,
+			want:   "caf...",
+		},
+		{
+			name:   "variation selector kept with heart",
+			input:  "\u2764\ufe0f\u2764\ufe0f",
+			maxLen: 3,
+			want:   "\u2764\ufe0f",
+		},
 	}
 
 	for _, tt := range tests {